@@ -3,22 +3,33 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"math/rand"
+	"net"
+	"net/http"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/becomeliminal/nim-go-sdk/core"
 	"github.com/becomeliminal/nim-go-sdk/executor"
 	"github.com/becomeliminal/nim-go-sdk/server"
 	"github.com/becomeliminal/nim-go-sdk/tools"
+	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 )
 
@@ -32,9 +43,13 @@ func main() {
 	// Load configuration from environment variables
 	// Create a .env file or export these in your shell
 
+	// OFFLINE_MODE=true runs only the HTTP endpoints on mock data, with no
+	// Claude server, so the analytics can be demoed or tested without a key
+	offlineMode := os.Getenv("OFFLINE_MODE") == "true"
+
 	anthropicKey := os.Getenv("ANTHROPIC_API_KEY")
-	if anthropicKey == "" {
-		log.Fatal("❌ ANTHROPIC_API_KEY environment variable is required")
+	if anthropicKey == "" && !offlineMode {
+		log.Fatal("❌ ANTHROPIC_API_KEY environment variable is required (or set OFFLINE_MODE=true)")
 	}
 
 	liminalBaseURL := os.Getenv("LIMINAL_BASE_URL")
@@ -47,6 +62,82 @@ func main() {
 		port = "8080"
 	}
 
+	// Hard caps on money movement, enforced server-side regardless of what the
+	// LLM decides. Unset or 0 means no cap.
+	spendingLimits := map[string]float64{
+		"send_money":       envFloat("MAX_SEND_AMOUNT"),
+		"withdraw_savings": envFloat("MAX_WITHDRAW_AMOUNT"),
+	}
+
+	// Browser origins allowed to reach the WebSocket and HTTP endpoints.
+	// Comma-separated; "*" allows any origin (development only).
+	allowedOrigins := parseOrigins(os.Getenv("ALLOWED_ORIGINS"))
+	log.Printf("✅ Allowed origins: %s", strings.Join(allowedOrigins, ", "))
+
+	// WebSocket caps so a busy demo can't exhaust the server. Unset or 0 means no cap.
+	maxConnections := envInt("MAX_CONNECTIONS", 0)
+	maxMessageBytes := envInt("MAX_MESSAGE_BYTES", 0)
+
+	// Fallback analysis windows when a tool call doesn't specify one
+	if days := envInt("DEFAULT_SPENDING_DAYS", defaultSpendingDays); days > 0 {
+		defaultSpendingDays = days
+	}
+	if months := envInt("DEFAULT_SUBSCRIPTION_MONTHS", defaultSubscriptionMonths); months > 0 {
+		defaultSubscriptionMonths = months
+	}
+	log.Printf("✅ Default analysis windows: %d days (spending), %d months (subscriptions)", defaultSpendingDays, defaultSubscriptionMonths)
+
+	// Retries for transient Liminal failures on read-only calls
+	liminalMaxRetries = envInt("LIMINAL_MAX_RETRIES", liminalMaxRetries)
+
+	// Categories that count as unavoidable spending for runway/budget math
+	if raw := os.Getenv("ESSENTIAL_CATEGORIES"); raw != "" {
+		essentialCategories = parseCategoryList(raw)
+	}
+	log.Printf("✅ Essential categories: %s", strings.Join(sortedKeys(essentialCategories), ", "))
+	if label := strings.TrimSpace(os.Getenv("FALLBACK_CATEGORY")); label != "" {
+		fallbackCategory = label
+	}
+	if label := strings.TrimSpace(os.Getenv("UNKNOWN_MERCHANT")); label != "" {
+		unknownMerchant = label
+	}
+
+	// Users who have opted in to sharing transactions with each other
+	households = parseHouseholds(os.Getenv("HOUSEHOLDS"))
+
+	// Type labels from non-Liminal sources, e.g. SEND_TYPE_ALIASES=debit,payment,withdrawal
+	for alias := range parseCategoryList(os.Getenv("SEND_TYPE_ALIASES")) {
+		sendAliases[alias] = true
+	}
+	for alias := range parseCategoryList(os.Getenv("RECEIVE_TYPE_ALIASES")) {
+		receiveAliases[alias] = true
+	}
+
+	// How much a recurring charge may vary and still be grouped as one
+	if tolerance := envFloat("AMOUNT_TOLERANCE"); tolerance > 0 {
+		defaultAmountTolerance = tolerance
+	}
+	if raw := os.Getenv("AMOUNT_TOLERANCES"); raw != "" {
+		amountTolerances = parseTolerances(raw)
+	}
+
+	// Unit of the apy field in get_vault_rates: "percent" (4.5, the default) or "fraction" (0.045)
+	switch unit := strings.ToLower(strings.TrimSpace(os.Getenv("VAULT_APY_UNIT"))); unit {
+	case "", "percent":
+	case "fraction":
+		vaultAPYIsFraction = true
+	default:
+		log.Printf("⚠️  Ignoring invalid VAULT_APY_UNIT=%q, using percent", unit)
+	}
+
+	// When proactive alerts are queued instead of returned, e.g. 22:00 to 07:00
+	alertQuietHours = parseQuietHours(os.Getenv("QUIET_HOURS_START"), os.Getenv("QUIET_HOURS_END"), os.Getenv("QUIET_HOURS_TIMEZONE"))
+
+	// How busy mock spending histories are
+	if density := envFloat("MOCK_TRANSACTIONS_PER_DAY"); density > 0 {
+		mockTransactionsPerDay = density
+	}
+
 	// ============================================================================
 	// LIMINAL EXECUTOR SETUP
 	// ============================================================================
@@ -67,15 +158,26 @@ func main() {
 	// Authentication is automatic: JWT tokens from the login flow are extracted
 	// from WebSocket connections and forwarded to Liminal API calls
 
-	srv, err := server.New(server.Config{
-		AnthropicKey:    anthropicKey,
-		SystemPrompt:    hackathonSystemPrompt,
-		Model:           "claude-sonnet-4-20250514",
-		MaxTokens:       4096,
-		LiminalExecutor: liminalExecutor, // SDK automatically handles JWT extraction and forwarding
-	})
-	if err != nil {
-		log.Fatal(err)
+	// In offline mode srv stays nil: tools are still cataloged and runnable over
+	// HTTP, but there is no WebSocket chat.
+
+	var srv *server.Server
+	if offlineMode {
+		log.Println("🔌 OFFLINE_MODE enabled: skipping Claude server, HTTP endpoints use mock data")
+	} else {
+		var err error
+		srv, err = server.New(server.Config{
+			AnthropicKey:    anthropicKey,
+			SystemPrompt:    hackathonSystemPrompt,
+			Model:           "claude-sonnet-4-20250514",
+			MaxTokens:       4096,
+			LiminalExecutor: liminalExecutor, // SDK automatically handles JWT extraction and forwarding
+			// Same JWT forwarding as the SDK default, but a per-user ID instead of "user"
+			AuthFunc: liminalAuthFunc(liminalExecutor),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	// ============================================================================
@@ -96,7 +198,7 @@ func main() {
 	//   8. deposit_savings - Deposit funds into savings
 	//   9. withdraw_savings - Withdraw funds from savings
 
-	srv.AddTools(tools.LiminalTools(liminalExecutor)...)
+	registerTools(srv, applySpendingLimits(tools.LiminalTools(liminalExecutor), spendingLimits)...)
 	log.Println("✅ Added 9 Liminal banking tools")
 
 	// ============================================================================
@@ -105,12 +207,165 @@ func main() {
 	// This is where you'll add your hackathon project's custom tools!
 	// Below are example analyzer tools to get you started.
 
-	srv.AddTool(createSpendingAnalyzerTool(liminalExecutor))
+	registerAnalyzers(srv, createSpendingAnalyzerTool(liminalExecutor))
 	log.Println("✅ Added custom spending analyzer tool")
 
-	srv.AddTool(createSubscriptionAnalyzerTool(liminalExecutor))
+	registerAnalyzers(srv, createSubscriptionAnalyzerTool(liminalExecutor))
 	log.Println("✅ Added custom subscription analyzer tool")
 
+	registerAnalyzers(srv, createHabitCostTool(liminalExecutor))
+	log.Println("✅ Added custom habit cost tool")
+
+	registerAnalyzers(srv, createMerchantComparisonTool(liminalExecutor))
+	log.Println("✅ Added custom merchant comparison tool")
+
+	registerAnalyzers(srv, createIncomeChangeTool(liminalExecutor))
+	log.Println("✅ Added custom income change simulator tool")
+
+	registerAnalyzers(srv, createUncategorizedSpendTool(liminalExecutor))
+	log.Println("✅ Added custom uncategorized spending tool")
+
+	registerAnalyzers(srv, createMerchantHistoryTool(liminalExecutor))
+	log.Println("✅ Added custom merchant history tool")
+
+	registerAnalyzers(srv, createSetSpendingTargetTool(), createCheckSpendingTargetTool(liminalExecutor))
+	log.Println("✅ Added custom spending target tools")
+
+	registerAnalyzers(srv, createExplainCategoryTool())
+	log.Println("✅ Added custom category explainer tool")
+
+	registerAnalyzers(srv, createFixedCostFloorTool(liminalExecutor))
+	log.Println("✅ Added custom fixed cost floor tool")
+
+	registerAnalyzers(srv, createPurchaseTimingTool(liminalExecutor))
+	log.Println("✅ Added custom purchase timing tool")
+
+	registerAnalyzers(srv, createMonthlyReportTool(liminalExecutor))
+	log.Println("✅ Added custom monthly report tool")
+
+	registerAnalyzers(srv, createLifestyleInflationTool(liminalExecutor))
+	log.Println("✅ Added custom lifestyle inflation tool")
+
+	registerAnalyzers(srv, createGoalAllocationTool())
+	log.Println("✅ Added custom goal allocation tool")
+
+	registerAnalyzers(srv, createBenchmarkTool(liminalExecutor))
+	log.Println("✅ Added custom spending benchmark tool")
+
+	registerAnalyzers(srv, createIncomeDelayTool(liminalExecutor))
+	log.Println("✅ Added custom income status tool")
+
+	registerAnalyzers(srv, createRecurringOverviewTool(liminalExecutor))
+	log.Println("✅ Added custom recurring overview tool")
+
+	registerAnalyzers(srv, createSafeToSpendTool(liminalExecutor))
+	log.Println("✅ Added custom safe to spend tool")
+
+	registerAnalyzers(srv, createPlanComparisonTool(liminalExecutor))
+	log.Println("✅ Added custom billing plan comparison tool")
+
+	registerAnalyzers(srv, createRoundupTool(liminalExecutor))
+	log.Println("✅ Added custom round-up savings tool")
+
+	registerAnalyzers(srv, createSpendingImpactTool(liminalExecutor))
+	log.Println("✅ Added custom spending impact tool")
+
+	registerAnalyzers(srv, createHouseholdTool(liminalExecutor))
+	log.Println("✅ Added custom household analysis tool")
+
+	registerAnalyzers(srv, createDepositScheduleTool(liminalExecutor))
+	log.Println("✅ Added custom deposit schedule tool")
+
+	registerAnalyzers(srv, createLowBalanceWarningTool(liminalExecutor))
+	log.Println("✅ Added custom low balance warning tool")
+
+	registerAnalyzers(srv, createRateTrendTool(liminalExecutor))
+	log.Println("✅ Added custom vault rate trend tool")
+
+	registerAnalyzers(srv, createCategoryForecastTool(liminalExecutor))
+	log.Println("✅ Added custom category forecast tool")
+
+	registerAnalyzers(srv, createMicroSubscriptionTool(liminalExecutor))
+	log.Println("✅ Added custom micro-subscription tool")
+
+	registerAnalyzers(srv, createSubscriptionOffsetTool(liminalExecutor))
+	log.Println("✅ Added custom subscription offset tool")
+
+	registerAnalyzers(srv, createCategoryVolatilityTool(liminalExecutor))
+	log.Println("✅ Added custom category volatility tool")
+
+	registerAnalyzers(srv, createPriceIncreaseImpactTool(liminalExecutor))
+	log.Println("✅ Added custom price increase impact tool")
+
+	registerAnalyzers(srv, createPaydaySpendingTool(liminalExecutor))
+	log.Println("✅ Added custom payday spending tool")
+
+	registerAnalyzers(srv, createNetWorthProjectionTool(liminalExecutor))
+	log.Println("✅ Added custom net worth projection tool")
+
+	registerAnalyzers(srv, createCategoryTrendTool(liminalExecutor))
+	log.Println("✅ Added custom category trend tool")
+
+	registerAnalyzers(srv, createLatteFactorTool(liminalExecutor))
+	log.Println("✅ Added custom latte factor tool")
+
+	registerAnalyzers(srv, createStreamingConsolidationTool(liminalExecutor))
+	log.Println("✅ Added custom streaming consolidation tool")
+
+	registerAnalyzers(srv, createWindfallTool(liminalExecutor))
+	log.Println("✅ Added custom windfall allocation tool")
+
+	registerAnalyzers(srv, createDeficitDetectorTool(liminalExecutor))
+	log.Println("✅ Added custom deficit detector tool")
+
+	registerAnalyzers(srv, createSpendConcentrationTool(liminalExecutor))
+	log.Println("✅ Added custom spend concentration tool")
+
+	registerAnalyzers(srv, createUnusedSubscriptionTool(liminalExecutor))
+	log.Println("✅ Added custom unused subscription tool")
+
+	registerAnalyzers(srv, createOpportunityCostTool(liminalExecutor))
+	log.Println("✅ Added custom savings opportunity cost tool")
+
+	registerAnalyzers(srv, createRuleSuggestionTool(liminalExecutor))
+	log.Println("✅ Added custom category rule suggestion tool")
+
+	registerAnalyzers(srv, createTransactionSizeTrendTool(liminalExecutor))
+	log.Println("✅ Added custom transaction size trend tool")
+
+	registerAnalyzers(srv, createP2PTool(liminalExecutor))
+	log.Println("✅ Added custom P2P transfer tool")
+
+	registerAnalyzers(srv, createBudgetVarianceTool(liminalExecutor))
+	log.Println("✅ Added custom budget variance tool")
+
+	registerAnalyzers(srv, createSmartWithdrawalTool(liminalExecutor))
+	log.Println("✅ Added custom withdrawal recommendation tool")
+
+	registerAnalyzers(srv, createDuplicateChargeTool(liminalExecutor))
+	log.Println("✅ Added custom duplicate charge tool")
+
+	registerAnalyzers(srv, createSavingsChallengeTool(liminalExecutor))
+	log.Println("✅ Added custom savings challenge tool")
+
+	registerAnalyzers(srv, createFXFeeTool(liminalExecutor))
+	log.Println("✅ Added custom FX fee tool")
+
+	registerAnalyzers(srv, createSaveGoalTool(), createGoalsDashboardTool(liminalExecutor))
+	log.Println("✅ Added custom goals dashboard tools")
+
+	registerAnalyzers(srv, createSubscriptionInflationTool(liminalExecutor))
+	log.Println("✅ Added custom subscription inflation tool")
+
+	registerAnalyzers(srv, createCardTestingDetectorTool(liminalExecutor))
+	log.Println("✅ Added custom suspicious activity tool")
+
+	registerAnalyzers(srv, createCancellationCostTool())
+	log.Println("✅ Added custom cancellation cost tool")
+
+	registerAnalyzers(srv, createSuggestBudgetTool(liminalExecutor))
+	log.Println("✅ Added custom budget suggestion tool")
+
 	// TODO: Add more custom tools here!
 	// Examples:
 	//   - Savings goal tracker
@@ -119,6 +374,33 @@ func main() {
 	//   - Bill payment predictor
 	//   - Cash flow forecaster
 
+	// ============================================================================
+	// HTTP ENDPOINTS
+	// ============================================================================
+	// We serve our own mux (instead of srv.Run) so every route, including the
+	// WebSocket upgrade, goes through the same origin check.
+
+	mux := http.NewServeMux()
+	if srv != nil {
+		mux.Handle("/ws", withWebSocketLimits(srv.Handler(), maxConnections, int64(maxMessageBytes)))
+	}
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/api/tools", handleListTools)
+	// Unauthenticated, so only exposed when no user's JWT is in play
+	if offlineMode {
+		mux.HandleFunc("POST /api/tools/{name}", runToolHandler)
+	}
+	mux.HandleFunc("GET /api/demo", handleDemo)
+	mux.HandleFunc("POST /api/analyze/full", handleAnalyzeFull)
+	// Only exposed when a token is configured
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken != "" {
+		mux.HandleFunc("POST /admin/reset", adminResetHandler(adminToken))
+	}
+
 	// ============================================================================
 	// START SERVER
 	// ============================================================================
@@ -126,341 +408,10990 @@ func main() {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("🚀 Hackathon Starter Server Running")
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	log.Printf("📡 WebSocket endpoint: ws://localhost:%s/ws", port)
+	if srv != nil {
+		log.Printf("📡 WebSocket endpoint: ws://localhost:%s/ws", port)
+	}
 	log.Printf("💚 Health check: http://localhost:%s/health", port)
+	log.Printf("🧰 Tool catalog: http://localhost:%s/api/tools", port)
+	if offlineMode {
+		log.Printf("▶️  Run a tool: POST http://localhost:%s/api/tools/{name}", port)
+	}
+	log.Printf("🎬 Demo script: http://localhost:%s/api/demo", port)
+	log.Printf("📋 Full analysis: POST http://localhost:%s/api/analyze/full", port)
+	if adminToken != "" {
+		log.Printf("🧹 Admin reset: POST http://localhost:%s/admin/reset", port)
+	}
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("Ready for connections! Start your frontend with: cd frontend && npm run dev")
 	log.Println()
 
-	if err := srv.Run(":" + port); err != nil {
+	if err := http.ListenAndServe(":"+port, withCORS(mux, allowedOrigins)); err != nil {
 		log.Fatal(err)
 	}
 }
 
 // ============================================================================
-// SYSTEM PROMPT
+// TOOL CATALOG
 // ============================================================================
-// This prompt defines your AI agent's personality and behavior
-// Customize this to match your hackathon project's focus!
+// The SDK registry only exposes tool names, so we keep our own record of every
+// tool handed to the server. This backs the /api/tools discovery endpoint.
+//
+// Tools may be registered or replaced while the server is running (e.g. after
+// loading a plugin config). Both the SDK registry and this catalog are guarded
+// by locks. The agent sends the tool list to the model at the start of each
+// turn, so a change applies to new conversations and to the next message of
+// open ones. A turn already in progress looks tools up by name on every call,
+// so it may run the replacement if the swap lands mid-turn; keep the schema
+// compatible when replacing. There is no removal: a tool that disappeared
+// mid-conversation would leave the model calling something that no longer exists.
 
-const hackathonSystemPrompt = `You are Nim, a friendly AI financial assistant built for the Liminal Vibe Banking Hackathon.
+var (
+	toolsMu         sync.RWMutex
+	registeredTools []core.Tool
+)
 
-WHAT YOU DO:
-You help users manage their money using Liminal's stablecoin banking platform. You can check balances, review transactions, send money, and manage savings - all through natural conversation.
+// registerTools adds tools to the server and records them in the catalog
+// A tool whose name is already registered replaces the old one, as in the SDK registry
+// srv may be nil in offline mode, in which case tools are only cataloged
+func registerTools(srv *server.Server, ts ...core.Tool) {
+	toolsMu.Lock()
+	defer toolsMu.Unlock()
+	if srv != nil {
+		srv.AddTools(ts...)
+	}
+	for _, tool := range ts {
+		if i := toolIndex(tool.Name()); i >= 0 {
+			registeredTools[i] = tool
+			continue
+		}
+		registeredTools = append(registeredTools, tool)
+	}
+}
 
-CONVERSATIONAL STYLE:
-- Be warm, friendly, and conversational - not robotic
-- Use casual language when appropriate, but stay professional about money
-- Ask clarifying questions when something is unclear
-- Remember context from earlier in the conversation
-- Explain things simply without being condescending
+// replaceTool swaps a running tool for a new implementation with the same name
+// It fails rather than adding when no such tool exists, so a typo can't silently
+// register a second tool. Wrap analyzers in metaTool first to keep their _meta.
+func replaceTool(srv *server.Server, tool core.Tool) error {
+	toolsMu.Lock()
+	defer toolsMu.Unlock()
+	i := toolIndex(tool.Name())
+	if i < 0 {
+		return fmt.Errorf("no tool named %q is registered", tool.Name())
+	}
+	if srv != nil {
+		srv.AddTool(tool)
+	}
+	registeredTools[i] = tool
+	log.Printf("🔁 Replaced tool %s", tool.Name())
+	return nil
+}
 
-WHEN TO USE TOOLS:
-- Use tools immediately for simple queries ("what's my balance?")
-- For actions, gather all required info first ("send $50 to @alice")
-- Always confirm before executing money movements
-- Don't use tools for general questions about how things work
+// toolIndex returns the catalog position of a tool, or -1; callers hold toolsMu
+func toolIndex(name string) int {
+	for i, tool := range registeredTools {
+		if tool.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
 
-MONEY MOVEMENT RULES (IMPORTANT):
-- ALL money movements require explicit user confirmation
-- Show a clear summary before confirming:
-  * send_money: "Send $50 USD to @alice"
-  * deposit_savings: "Deposit $100 USD into savings"
-  * withdraw_savings: "Withdraw $50 USD from savings"
-- Never assume amounts or recipients
-- Always use the exact currency the user specified
+// findTool looks up a registered tool by name
+func findTool(name string) (core.Tool, bool) {
+	toolsMu.RLock()
+	defer toolsMu.RUnlock()
+	if i := toolIndex(name); i >= 0 {
+		return registeredTools[i], true
+	}
+	return nil, false
+}
 
-AVAILABLE BANKING TOOLS:
-- Check wallet balance (get_balance)
-- Check savings balance and APY (get_savings_balance)
-- View savings rates (get_vault_rates)
-- View transaction history (get_transactions)
-- Get profile info (get_profile)
-- Search for users (search_users)
-- Send money (send_money) - requires confirmation
-- Deposit to savings (deposit_savings) - requires confirmation
-- Withdraw from savings (withdraw_savings) - requires confirmation
+// catalogTools returns a snapshot of the registered tools
+func catalogTools() []core.Tool {
+	toolsMu.RLock()
+	defer toolsMu.RUnlock()
+	return append([]core.Tool(nil), registeredTools...)
+}
 
-CUSTOM ANALYTICAL TOOLS:
-- Analyze spending patterns (analyze_spending)
-- Detect subscriptions (analyze_subscriptions)
+// runToolHandler serves POST /api/tools/{name}, executing a read-only tool
+// with the request body as its input. Tools that move money are never run
+// over HTTP. The route has no auth, so it is only registered in offline mode
+// and use_mock is always forced on: it must never reach a real user's data.
+func runToolHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	tool, ok := findTool(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown tool %q", name), http.StatusNotFound)
+		return
+	}
+	if tool.RequiresConfirmation() {
+		http.Error(w, fmt.Sprintf("%s requires confirmation and can't be run over HTTP", name), http.StatusForbidden)
+		return
+	}
 
-TIPS FOR GREAT INTERACTIONS:
-- Proactively suggest relevant actions ("Want me to move some to savings?")
-- Explain the "why" behind suggestions
-- Celebrate financial wins ("Nice! Your savings earned $5 this month!")
-- Be encouraging about savings goals
-- Make finance feel less intimidating
+	input := map[string]interface{}{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, fmt.Sprintf("invalid JSON body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	input["use_mock"] = true
+	inputJSON, _ := json.Marshal(input)
 
-Remember: You're here to make banking delightful and help users build better financial habits!`
+	result, err := tool.Execute(r.Context(), &core.ToolParams{
+		UserID:    "http",
+		Input:     inputJSON,
+		RequestID: fmt.Sprintf("http_%d", time.Now().UnixNano()),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Success {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleListTools serves GET /api/tools with the name, description and schema
+// of every registered tool
+func handleListTools(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	registered := catalogTools()
+	catalog := make([]map[string]interface{}, 0, len(registered))
+	for _, tool := range registered {
+		catalog = append(catalog, map[string]interface{}{
+			"name":                  tool.Name(),
+			"description":           tool.Description(),
+			"schema":                tool.Schema(),
+			"requires_confirmation": tool.RequiresConfirmation(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count": len(catalog),
+		"tools": catalog,
+	})
+}
 
 // ============================================================================
-// MOCK DATA GENERATORS
+// CORS
 // ============================================================================
 
-// generateMockTransactionsForAnalysis creates realistic transaction data for testing
-// Useful for demo purposes without needing real user data
-func generateMockTransactionsForAnalysis(days int) []map[string]interface{} {
-	rand.Seed(time.Now().UnixNano())
-	now := time.Now()
-	transactions := []map[string]interface{}{}
+// defaultAllowedOrigins covers the Vite dev server when ALLOWED_ORIGINS is unset
+var defaultAllowedOrigins = []string{"http://localhost:5173", "http://127.0.0.1:5173"}
 
-	// Transaction templates - realistic merchant names and amounts
-	templates := []struct {
-		description string
-		amount      float64
-		txType      string
-	}{
-		// Food & Dining
-		{"Starbucks Coffee", 8.50, "send"},
-		{"Chipotle Mexican Grill", 15.75, "send"},
-		{"Whole Foods Market", 67.30, "send"},
-		{"DoorDash - Pizza Delivery", 32.50, "send"},
-		{"Local Coffee Shop", 6.25, "send"},
-		// Transportation
-		{"Uber Ride", 18.50, "send"},
-		{"Gas Station", 45.00, "send"},
-		{"Lyft Ride", 22.75, "send"},
-		{"Metro Card Reload", 30.00, "send"},
-		// Shopping
-		{"Amazon.com", 89.99, "send"},
-		{"Target Store", 54.25, "send"},
-		{"Nike Store", 125.00, "send"},
-		// Entertainment
-		{"Netflix Subscription", 15.99, "send"},
-		{"Spotify Premium", 10.99, "send"},
-		{"Movie Theater", 28.50, "send"},
-		{"Steam Games", 59.99, "send"},
-		// Bills
-		{"Electric Bill Payment", 125.50, "send"},
-		{"Internet Service", 79.99, "send"},
-		{"Phone Bill", 65.00, "send"},
-		// Income
-		{"Payroll Deposit", 2500.00, "receive"},
-		{"Freelance Payment", 450.00, "receive"},
-		{"Refund from Amazon", 29.99, "receive"},
-		{"Payment from @alice", 75.00, "receive"},
+// parseOrigins splits a comma-separated origin list, falling back to localhost
+func parseOrigins(raw string) []string {
+	var origins []string
+	for _, origin := range strings.Split(raw, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return defaultAllowedOrigins
 	}
+	return origins
+}
 
-	// Generate 30-40 transactions spread over the time period
-	numTxs := 30 + rand.Intn(11)
-	for i := 0; i < numTxs; i++ {
-		template := templates[rand.Intn(len(templates))]
-		daysAgo := rand.Intn(days)
-		txDate := now.AddDate(0, 0, -daysAgo)
+// withCORS rejects requests from disallowed browser origins with a 403 and
+// sets CORS headers for allowed ones. Requests without an Origin header
+// (curl, server-to-server) pass through untouched.
+func withCORS(next http.Handler, allowedOrigins []string) http.Handler {
+	allowAll := false
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[origin] = true
+	}
 
-		// Add variance to amounts (80% - 120%) to make it more realistic
-		variance := 0.8 + rand.Float64()*0.4
-		amount := math.Round(template.amount*variance*100) / 100
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		transactions = append(transactions, map[string]interface{}{
-			"id":          fmt.Sprintf("tx_mock_%d", i),
-			"type":        template.txType,
-			"amount":      amount,
-			"description": template.description,
-			"date":        txDate.Format(time.RFC3339),
-			"status":      "completed",
-			"currency":    "USD",
-		})
+		if !allowAll && !allowed[origin] {
+			log.Printf("🚫 Rejected request from origin %s", origin)
+			http.Error(w, fmt.Sprintf("origin %s is not allowed", origin), http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ============================================================================
+// SESSION IDENTITY
+// ============================================================================
+// The SDK's default auth forwards the JWT to Liminal but gives every tool call
+// the placeholder user ID "user", so anything keyed on ToolParams.UserID (the
+// per-user store, households) would be shared by every connection.
+// liminalAuthFunc keeps the JWT forwarding and derives the user ID from the token.
+
+// anonymousUserID is the user ID for connections without a JWT, e.g. mock-data demos
+// They all share one entry in the per-user store
+const anonymousUserID = "anonymous"
+
+// liminalAuthFunc is the server's AuthFunc: the SDK default plus a real user ID
+func liminalAuthFunc(liminalExecutor *executor.HTTPExecutor) func(r *http.Request) (string, error) {
+	return func(r *http.Request) (string, error) {
+		// Same lookup as the SDK: query param (WebSocket), then Authorization header
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); token == "" && strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if token == "" {
+			return anonymousUserID, nil
+		}
+		liminalExecutor.UpdateJWT(token)
+		return sessionUserID(token), nil
 	}
+}
 
-	return transactions
+// sessionUserID derives a user ID from a hash of the whole JWT. The signature isn't
+// checked here, only by Liminal on each API call, so a claim like "sub" can't be
+// trusted: a forged token could name someone else and read their goals or household.
+// Hashing the full token keys state on the credential itself; a refreshed token starts
+// a fresh session until tokens are verified against the issuer's key.
+func sessionUserID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "session-" + hex.EncodeToString(sum[:8])
 }
 
-// generateMockSubscriptionTransactions creates recurring payment patterns for subscription detection
-func generateMockSubscriptionTransactions(months int) []map[string]interface{} {
-	rand.Seed(time.Now().UnixNano())
-	now := time.Now()
-	transactions := []map[string]interface{}{}
+// ============================================================================
+// WEBSOCKET LIMITS
+// ============================================================================
+// The SDK owns the WebSocket connection, so limits are enforced around it: a
+// counter in front of the upgrade, and a hijacked connection that reads frame
+// headers as they arrive and stops a message as soon as it is too big.
 
-	// Subscription templates with recurring patterns
-	subscriptions := []struct {
-		merchant  string
-		amount    float64
-		frequency int // days between payments
+// withWebSocketLimits caps concurrent connections at maxConnections and inbound
+// messages at maxMessageBytes; 0 disables either cap. Connections over the cap
+// are upgraded only to be closed with 1013 (try again later), which browsers
+// surface to the frontend, unlike a plain HTTP error on the upgrade.
+func withWebSocketLimits(next http.Handler, maxConnections int, maxMessageBytes int64) http.Handler {
+	if maxConnections <= 0 && maxMessageBytes <= 0 {
+		return next
+	}
+	// Origins were already checked by withCORS
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	var active atomic.Int64
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxConnections > 0 {
+			if active.Add(1) > int64(maxConnections) {
+				active.Add(-1)
+				log.Printf("🚫 Rejected WebSocket connection: %d connections already open", maxConnections)
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server is at capacity, try again shortly"),
+					time.Now().Add(time.Second))
+				conn.Close()
+				return
+			}
+			defer active.Add(-1)
+		}
+		if maxMessageBytes > 0 {
+			w = &limitedResponseWriter{ResponseWriter: w, limit: maxMessageBytes}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitedResponseWriter hands the WebSocket upgrade a size-checking connection
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	limit int64
+}
+
+// Hijack wraps the hijacked connection in a limitedConn. Reads go through the
+// original buffered reader so bytes net/http already buffered aren't lost.
+func (w *limitedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	limited := &limitedConn{Conn: conn, reader: rw.Reader, limit: w.limit}
+	return limited, bufio.NewReadWriter(bufio.NewReader(limited), rw.Writer), nil
+}
+
+// errMessageTooBig stops reading once a client message exceeds MAX_MESSAGE_BYTES
+var errMessageTooBig = errors.New("websocket message exceeds MAX_MESSAGE_BYTES")
+
+// limitedConn follows the client-to-server frame stream and fails the read as
+// soon as a frame header pushes the current message past limit, before its
+// payload is buffered. It then closes the connection: a close frame written here
+// could interleave with a frame the SDK is part way through writing, so the client
+// sees an abnormal closure (1006) rather than 1009 (message too big).
+type limitedConn struct {
+	net.Conn
+	reader    io.Reader
+	limit     int64
+	header    []byte // frame header bytes seen so far
+	remaining int64  // payload bytes left in the current frame
+	message   int64  // payload bytes in the current data message, across fragments
+	err       error
+}
+
+func (c *limitedConn) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.reader.Read(p)
+	if tooBig := c.inspect(p[:n]); tooBig != nil {
+		c.err = tooBig
+		log.Printf("🚫 Closing WebSocket: message larger than %d bytes", c.limit)
+		c.Conn.Close()
+		return 0, tooBig
+	}
+	return n, err
+}
+
+// inspect advances the frame parser over data
+func (c *limitedConn) inspect(data []byte) error {
+	for len(data) > 0 {
+		if c.remaining > 0 {
+			skip := int64(len(data))
+			if skip > c.remaining {
+				skip = c.remaining
+			}
+			c.remaining -= skip
+			data = data[skip:]
+			continue
+		}
+
+		c.header = append(c.header, data[0])
+		data = data[1:]
+		length, complete := frameLength(c.header)
+		if !complete {
+			continue
+		}
+		fin, opcode := c.header[0]&0x80 != 0, c.header[0]&0x0f
+		c.header = c.header[:0]
+		c.remaining = length
+
+		// Control frames (close, ping, pong) sit between fragments and aren't part of a message
+		if opcode >= 0x8 {
+			continue
+		}
+		if opcode != 0x0 {
+			c.message = 0
+		}
+		c.message += length
+		if c.message > c.limit {
+			return errMessageTooBig
+		}
+		if fin {
+			c.message = 0
+		}
+	}
+	return nil
+}
+
+// frameLength reads the payload length from a WebSocket frame header (RFC 6455
+// section 5.2), reporting false until the whole header, mask included, is there
+func frameLength(header []byte) (int64, bool) {
+	if len(header) < 2 {
+		return 0, false
+	}
+	size := 2
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		size += 2
+	case 127:
+		size += 8
+	}
+	if header[1]&0x80 != 0 {
+		size += 4
+	}
+	if len(header) < size {
+		return 0, false
+	}
+	switch length {
+	case 126:
+		length = int64(header[2])<<8 | int64(header[3])
+	case 127:
+		length = 0
+		for _, b := range header[2:10] {
+			length = length<<8 | int64(b)
+		}
+	}
+	return length, true
+}
+
+// ============================================================================
+// ESSENTIAL CATEGORIES
+// ============================================================================
+// The single definition of "essential" spending shared by every tool that does
+// runway or budget math. Override with ESSENTIAL_CATEGORIES="Bills & Utilities,Food & Dining",
+// or per call with the essential_categories param.
+
+// essentialSet is a set of lowercased category names considered essential
+type essentialSet map[string]bool
+
+// has reports whether a spending category is in the set
+func (s essentialSet) has(category string) bool {
+	return s[strings.ToLower(strings.TrimSpace(category))]
+}
+
+// essentialCategories is the deployment-wide default set
+var essentialCategories = essentialSet{
+	"bills & utilities": true,
+	"food & dining":     true,
+	"transportation":    true,
+}
+
+// isEssential reports whether a spending category is essential by default
+func isEssential(category string) bool {
+	return essentialCategories.has(category)
+}
+
+// essentialCategoriesProperty is the shared schema for the essential_categories param
+func essentialCategoriesProperty() map[string]interface{} {
+	return tools.ArrayProperty("Categories to treat as essential for this call, e.g. [\"Bills & Utilities\", \"Food & Dining\"] (default: the server's ESSENTIAL_CATEGORIES)",
+		tools.StringProperty("Category name"))
+}
+
+// resolveEssentials returns the essential_categories param as a set, or the default when it's empty
+func resolveEssentials(override []string) essentialSet {
+	set := essentialSet{}
+	for _, name := range override {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			set[name] = true
+		}
+	}
+	if len(set) == 0 {
+		return essentialCategories
+	}
+	return set
+}
+
+// parseCategoryList turns a comma-separated list into a lowercased category set
+func parseCategoryList(raw string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// sortedKeys returns a set's keys in alphabetical order for stable output
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ============================================================================
+// SPENDING LIMITS
+// ============================================================================
+// A hard guardrail on money movement that doesn't depend on prompt adherence.
+// Capped write tools reject over-limit amounts before the Liminal API is called.
+
+// limitedTool wraps a Liminal write tool and enforces a maximum amount per call
+type limitedTool struct {
+	core.Tool
+	maxAmount float64
+}
+
+// Execute rejects the call if the requested amount exceeds the cap
+func (t *limitedTool) Execute(ctx context.Context, params *core.ToolParams) (*core.ToolResult, error) {
+	var input struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(params.Input, &input); err != nil {
+		return &core.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %s input: %v", t.Name(), err),
+		}, nil
+	}
+
+	// parseAmount rejects NaN and ±Inf, which would otherwise slip past the cap comparison
+	amount, err := parseAmount(input.Amount)
+	if err != nil {
+		return &core.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("invalid amount %q", input.Amount),
+		}, nil
+	}
+	if amount <= 0 {
+		return &core.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("%s amount must be greater than zero", t.Name()),
+		}, nil
+	}
+
+	if amount > t.maxAmount {
+		log.Printf("🛑 Blocked %s of %.2f %s (limit %.2f)", t.Name(), amount, input.Currency, t.maxAmount)
+		return &core.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("%s amount %.2f exceeds the maximum of %.2f per transaction", t.Name(), amount, t.maxAmount),
+		}, nil
+	}
+
+	return t.Tool.Execute(ctx, params)
+}
+
+// applySpendingLimits wraps every tool that has a positive limit configured
+// Tools without a limit are returned unchanged
+func applySpendingLimits(ts []core.Tool, limits map[string]float64) []core.Tool {
+	wrapped := make([]core.Tool, len(ts))
+	for i, tool := range ts {
+		if limit := limits[tool.Name()]; limit > 0 {
+			wrapped[i] = &limitedTool{Tool: tool, maxAmount: limit}
+			log.Printf("🔒 %s capped at %.2f per transaction", tool.Name(), limit)
+			continue
+		}
+		wrapped[i] = tool
+	}
+	return wrapped
+}
+
+// ============================================================================
+// RESULT METADATA
+// ============================================================================
+// Every analyzer result carries a _meta object so clients can rely on the
+// same debugging fields regardless of which tool ran.
+
+// toolVersion is reported in _meta.tool_version; bump it when result shapes change
+const toolVersion = "1.0.0"
+
+// metaTool wraps an analyzer and stamps _meta onto its result data
+type metaTool struct {
+	core.Tool
+}
+
+// Execute runs the wrapped tool and adds version, timing and cache info to map results
+func (t *metaTool) Execute(ctx context.Context, params *core.ToolParams) (*core.ToolResult, error) {
+	start := time.Now()
+	result, err := t.Tool.Execute(ctx, params)
+	if err != nil || result == nil {
+		return result, err
+	}
+	if data, ok := result.Data.(map[string]interface{}); ok {
+		data["_meta"] = map[string]interface{}{
+			"tool_version": toolVersion,
+			"duration_ms":  time.Since(start).Milliseconds(),
+			// Nothing is cached yet; analyzers always compute fresh
+			"cached": false,
+		}
+	}
+	return result, nil
+}
+
+// registerAnalyzers registers custom analyzer tools with result metadata attached
+func registerAnalyzers(srv *server.Server, ts ...core.Tool) {
+	wrapped := make([]core.Tool, len(ts))
+	for i, tool := range ts {
+		wrapped[i] = &metaTool{Tool: tool}
+	}
+	registerTools(srv, wrapped...)
+}
+
+// envFloat reads a numeric environment variable, returning 0 if unset or invalid
+func envFloat(name string) float64 {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("⚠️  Ignoring invalid %s=%q: %v", name, raw, err)
+		return 0
+	}
+	return value
+}
+
+// envInt reads an integer environment variable, returning fallback if unset or invalid
+func envInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("⚠️  Ignoring invalid %s=%q, using %d", name, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// ============================================================================
+// SYSTEM PROMPT
+// ============================================================================
+// This prompt defines your AI agent's personality and behavior
+// Customize this to match your hackathon project's focus!
+
+const hackathonSystemPrompt = `You are Nim, a friendly AI financial assistant built for the Liminal Vibe Banking Hackathon.
+
+WHAT YOU DO:
+You help users manage their money using Liminal's stablecoin banking platform. You can check balances, review transactions, send money, and manage savings - all through natural conversation.
+
+CONVERSATIONAL STYLE:
+- Be warm, friendly, and conversational - not robotic
+- Use casual language when appropriate, but stay professional about money
+- Ask clarifying questions when something is unclear
+- Remember context from earlier in the conversation
+- Explain things simply without being condescending
+
+WHEN TO USE TOOLS:
+- Use tools immediately for simple queries ("what's my balance?")
+- For actions, gather all required info first ("send $50 to @alice")
+- Always confirm before executing money movements
+- Don't use tools for general questions about how things work
+
+MONEY MOVEMENT RULES (IMPORTANT):
+- ALL money movements require explicit user confirmation
+- Show a clear summary before confirming:
+  * send_money: "Send $50 USD to @alice"
+  * deposit_savings: "Deposit $100 USD into savings"
+  * withdraw_savings: "Withdraw $50 USD from savings"
+- Never assume amounts or recipients
+- Always use the exact currency the user specified
+- If a tool fails with error_code "auth_expired", tell the user their session expired and ask them to log in again
+
+AVAILABLE BANKING TOOLS:
+- Check wallet balance (get_balance)
+- Check savings balance and APY (get_savings_balance)
+- View savings rates (get_vault_rates)
+- View transaction history (get_transactions)
+- Get profile info (get_profile)
+- Search for users (search_users)
+- Send money (send_money) - requires confirmation
+- Deposit to savings (deposit_savings) - requires confirmation
+- Withdraw from savings (withdraw_savings) - requires confirmation
+
+CUSTOM ANALYTICAL TOOLS:
+- Analyze spending patterns (analyze_spending)
+- Detect subscriptions (analyze_subscriptions)
+- Project the yearly cost of a habit like daily coffee (habit_cost)
+- Compare merchants and weekdays within a category (compare_merchants)
+- Simulate a raise or pay cut (simulate_income_change)
+- Find spending that couldn't be categorized (find_uncategorized)
+- Show the full history with one merchant (merchant_history)
+- Set and check an overall weekly/monthly spending target (set_spending_target, check_spending_target)
+- Explain why a transaction got its category (explain_category)
+- Estimate the baseline monthly cost before discretionary spending (fixed_cost_floor)
+- Recommend when to make a large purchase (when_to_buy)
+- Wrap up the month in a full report (monthly_report)
+- Detect spending growing faster than income (detect_lifestyle_inflation)
+- Split spare money across several savings goals (allocate_to_goals)
+- Benchmark spending against the 50/30/20 rule (benchmark_spending)
+- Check whether the paycheck has arrived on schedule (check_income_status)
+- Show all recurring inflows and outflows in one view (recurring_overview)
+- Work out how much is safe to spend before the next payday (safe_to_spend)
+- Compare a monthly plan with the annual plan for the same service (compare_billing_plans)
+- Calculate round-up savings per purchase or per week (calculate_roundups)
+- Illustrative sustainability score by category, only when the user asks (spending_impact)
+- Analyze combined finances of household members (analyze_household)
+- Plan savings deposits right after each payday (optimize_deposit_schedule)
+- Warn when the balance won't cover bills due soon (check_low_balance)
+- Check whether savings rates are rising or falling (vault_rate_trend)
+- Project next month's spending per category (forecast_by_category)
+- Total up the small subscriptions that add up unnoticed (find_micro_subscriptions)
+- Show how much savings would let interest pay for subscriptions (offset_subscriptions)
+- Rank categories from most erratic to steadiest month to month (category_volatility)
+- Show what a subscription price increase costs over the years (price_increase_impact)
+- Measure how much spending bunches up right after payday (payday_spending)
+- Project wallet + savings month by month for long-term planning (project_net_worth)
+- Drill into one category's month-by-month trend (category_trend)
+- Find the user's own small repeat purchase and its 10-year cost (latte_factor)
+- Quantify cutting overlapping streaming services; confirm before the user cancels (consolidate_streaming)
+- Split a bonus or other windfall across fun, emergency fund, goals and savings (allocate_windfall)
+- Flag months where spending beat income (detect_deficit); if the deficit is chronic, say so plainly and coach toward closing the gap before suggesting new savings or purchases
+- Show how few merchants make up most of the spending (spend_concentration)
+- Flag subscriptions paid for but not used (flag_unused_subscriptions); if the user says when they last used a service, pass it in last_used
+- Show the interest lost by leaving cash idle in the wallet (savings_opportunity_cost); offer deposit_savings if they want to act
+- Suggest keyword rules for spending stuck in "Other" (suggest_category_rules); ask the user about each one, then pass accepted category_weight entries to analyze_spending's category_weights
+- Track whether the average purchase size is creeping up (transaction_size_trend)
+- Summarize money sent to and received from friends by @handle (analyze_p2p)
+- Compare a month's spending with the user's planned budget per category (budget_variance)
+- Size the smallest savings withdrawal that covers an upcoming shortfall (recommend_withdrawal); mention any emergency-fund warning and only call withdraw_savings after the user confirms
+- Flag likely double charges at the same merchant, with a confidence per pair (detect_duplicate_charges)
+- Turn a small spending habit into a week-by-week savings challenge (create_savings_challenge)
+- Total the currency conversion fees paid on non-primary-currency transactions (analyze_fx_fees)
+- Save savings goals and show progress on all of them at once (save_savings_goal, goals_dashboard)
+- Project total subscription cost 1/3/5 years out from observed price increases (project_subscription_inflation)
+- Flag bursts of tiny charges to unfamiliar merchants that look like card testing (detect_suspicious_activity); urge the user to review them
+- Decide whether canceling a contract subscription now beats paying out the term, given its early termination fee (cancellation_cost)
+- Suggest per-category monthly budget limits from past spending (suggest_budget); its budget map can be passed to budget_variance
+
+TIPS FOR GREAT INTERACTIONS:
+- If the user writes in Spanish, pass language "es" to analyze_spending and analyze_subscriptions
+- Proactively suggest relevant actions ("Want me to move some to savings?")
+- Explain the "why" behind suggestions
+- Celebrate financial wins ("Nice! Your savings earned $5 this month!")
+- Call analyze_spending with separate_savings to report money moved to savings as amount_saved, not spending
+- Be encouraging about savings goals
+- Make finance feel less intimidating
+
+Remember: You're here to make banking delightful and help users build better financial habits!`
+
+// ============================================================================
+// ANALYSIS DEFAULTS
+// ============================================================================
+
+// defaultSpendingDays is the spending analysis window when none is given (DEFAULT_SPENDING_DAYS)
+var defaultSpendingDays = 30
+
+// defaultSubscriptionMonths is the subscription scan window when none is given (DEFAULT_SUBSCRIPTION_MONTHS)
+var defaultSubscriptionMonths = 6
+
+// maxTimeframeMonths caps timeframe_months so tool input can't request unbounded history
+const maxTimeframeMonths = 24
+
+// ============================================================================
+// TIMEZONES
+// ============================================================================
+// Day, week and month boundaries depend on where the user is. Tools that
+// bucket by calendar take a timezone param and resolve it here.
+
+// timezoneProperty is the shared schema for the timezone param
+func timezoneProperty() map[string]interface{} {
+	return tools.StringProperty("IANA timezone used for day/week/month boundaries, e.g. 'America/New_York' (default: UTC)")
+}
+
+// resolveTimezone loads an IANA timezone, falling back to UTC with a warning when it's invalid
+func resolveTimezone(name string) (*time.Location, string) {
+	if name == "" {
+		return time.UTC, ""
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC, fmt.Sprintf("unknown timezone %q, using UTC", name)
+	}
+	return loc, ""
+}
+
+// ============================================================================
+// MESSAGES
+// ============================================================================
+// Insight and warning text is looked up by key so it can be returned in the
+// user's language. English is the default and the fallback for missing keys.
+
+// defaultLanguage is used when no language is given or a key isn't translated
+const defaultLanguage = "en"
+
+// messageCatalog maps language code -> message key -> fmt template
+var messageCatalog = map[string]map[string]string{
+	"en": {
+		"spending.count":            "You made %d spending transactions over %d days",
+		"spending.avg_daily":        "Average daily spend: $%.2f over the %d-day window",
+		"spending.sparse_history":   "Your history only covers %d days; on those days you averaged $%.2f/day",
+		"spending.uncategorizable":  "%d spending transactions had no description and couldn't be categorized",
+		"spending.positive_flow":    "Great! You're cash flow positive with $%.2f net income",
+		"spending.negative_flow":    "You spent $%.2f more than you received this period",
+		"spending.top_category":     "Your biggest spending category is %s (%.0f%% of spending)",
+		"spending.refunds":          "You got $%.2f back in %d refund(s), which isn't counted as income",
+		"spending.amount_saved":     "Nice! You moved $%.2f into savings across %d deposit(s)",
+		"spending.none":             "No spending this period — nicely done!",
+		"subscriptions.none":        "No subscriptions were detected in your transaction history.",
+		"subscriptions.monthly":     "You are spending approximately $%.2f per month on subscriptions.",
+		"subscriptions.duplicates":  "You have multiple %s subscriptions: %s. Consider consolidating.",
+		"subscriptions.inactive":    "Subscription to '%s' seems inactive (last paid %s). Consider cancelling if you no longer use it.",
+		"subscriptions.savings_tip": "Tip: Cancelling just 10%% of your subscriptions could save you $%.2f monthly!",
+	},
+	"es": {
+		"spending.count":            "Hiciste %d gastos en %d días",
+		"spending.avg_daily":        "Gasto diario promedio: $%.2f en el periodo de %d días",
+		"spending.sparse_history":   "Tu historial solo cubre %d días; en esos días gastaste en promedio $%.2f/día",
+		"spending.uncategorizable":  "%d gastos no tenían descripción y no se pudieron categorizar",
+		"spending.positive_flow":    "¡Genial! Tu flujo de caja es positivo con $%.2f de ingreso neto",
+		"spending.negative_flow":    "Gastaste $%.2f más de lo que recibiste en este periodo",
+		"spending.top_category":     "Tu mayor categoría de gasto es %s (%.0f%% del gasto)",
+		"spending.refunds":          "Recibiste $%.2f en %d reembolso(s), que no se cuentan como ingreso",
+		"spending.amount_saved":     "¡Bien! Pasaste $%.2f a tus ahorros en %d depósito(s)",
+		"spending.none":             "Sin gastos en este periodo, ¡bien hecho!",
+		"subscriptions.none":        "No se detectaron suscripciones en tu historial de transacciones.",
+		"subscriptions.monthly":     "Estás gastando aproximadamente $%.2f al mes en suscripciones.",
+		"subscriptions.duplicates":  "Tienes varias suscripciones de %s: %s. Considera consolidarlas.",
+		"subscriptions.inactive":    "La suscripción a '%s' parece inactiva (último pago %s). Considera cancelarla si ya no la usas.",
+		"subscriptions.savings_tip": "Consejo: ¡Cancelar solo el 10%% de tus suscripciones podría ahorrarte $%.2f al mes!",
+	},
+}
+
+// languageProperty is the shared schema for the language param
+func languageProperty() map[string]interface{} {
+	return tools.StringProperty("Language for insights and warnings: 'en' or 'es' (default: en)")
+}
+
+// normalizeLanguage reduces a tag like "es-MX" to a supported catalog code, defaulting to English
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := messageCatalog[lang]; !ok {
+		return defaultLanguage
+	}
+	return lang
+}
+
+// message formats the template for key in lang, falling back to English
+func message(lang, key string, args ...interface{}) string {
+	template, ok := messageCatalog[normalizeLanguage(lang)][key]
+	if !ok {
+		template = messageCatalog[defaultLanguage][key]
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// ============================================================================
+// MOCK DATA GENERATORS
+// ============================================================================
+
+// defaultMockCurrency is the currency mock transactions use unless a tool asks otherwise
+const defaultMockCurrency = "USD"
+
+// mockCurrencyRates scales the USD-based templates to typical amounts in each currency
+var mockCurrencyRates = map[string]float64{
+	"USD": 1,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"JPY": 150,
+}
+
+// mockCurrencies lists supported mock currencies in a stable order
+var mockCurrencies = []string{"USD", "EUR", "GBP", "JPY"}
+
+// mockTransactionsPerDay is the average number of mock analysis transactions per day (MOCK_TRANSACTIONS_PER_DAY)
+var mockTransactionsPerDay = 1.2
+
+// Upper bounds on one mock analysis history, since days and count come from tool input
+const (
+	maxMockDays         = 730
+	maxMockTransactions = 5000
+)
+
+// mockOptions tunes the mock data generators
+type mockOptions struct {
+	Currency string // USD (default), EUR, GBP, JPY or "mixed"
+	Seed     int64  // fixed seed for repeatable data; 0 uses the current time
+	Count    int    // exact number of analysis transactions; 0 scales with the window
+}
+
+// newRand returns a random source for one generator run
+// Each run gets its own source so a fixed seed always yields the same data
+func (o mockOptions) newRand() *rand.Rand {
+	seed := o.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// pickMockCurrency resolves the requested mock currency
+// "mixed" picks a random supported currency; unknown values fall back to USD
+func pickMockCurrency(currency string, rng *rand.Rand) string {
+	currency = strings.ToUpper(currency)
+	if currency == "MIXED" {
+		return mockCurrencies[rng.Intn(len(mockCurrencies))]
+	}
+	if _, ok := mockCurrencyRates[currency]; ok {
+		return currency
+	}
+	return defaultMockCurrency
+}
+
+// convertMockAmount converts a USD template amount into the given currency
+// JPY has no minor unit, so it is rounded to whole yen
+func convertMockAmount(usdAmount float64, currency string) float64 {
+	amount := usdAmount * mockCurrencyRates[currency]
+	if currency == "JPY" {
+		return math.Round(amount)
+	}
+	return math.Round(amount*100) / 100
+}
+
+// generateMockTransactionsForAnalysis creates realistic transaction data for testing
+// Useful for demo purposes without needing real user data
+func generateMockTransactionsForAnalysis(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	transactions := []map[string]interface{}{}
+
+	// Transaction templates - realistic merchant names and amounts
+	templates := []struct {
+		description string
+		amount      float64
+		txType      string
+	}{
+		// Food & Dining
+		{"Starbucks Coffee", 8.50, "send"},
+		{"Chipotle Mexican Grill", 15.75, "send"},
+		{"Whole Foods Market", 67.30, "send"},
+		{"DoorDash - Pizza Delivery", 32.50, "send"},
+		{"Local Coffee Shop", 6.25, "send"},
+		// Transportation
+		{"Uber Ride", 18.50, "send"},
+		{"Gas Station", 45.00, "send"},
+		{"Lyft Ride", 22.75, "send"},
+		{"Metro Card Reload", 30.00, "send"},
+		// Shopping
+		{"Amazon.com", 89.99, "send"},
+		{"Target Store", 54.25, "send"},
+		{"Nike Store", 125.00, "send"},
+		// Entertainment
+		{"Netflix Subscription", 15.99, "send"},
+		{"Spotify Premium", 10.99, "send"},
+		{"Movie Theater", 28.50, "send"},
+		{"Steam Games", 59.99, "send"},
+		// Bills
+		{"Electric Bill Payment", 125.50, "send"},
+		{"Internet Service", 79.99, "send"},
+		{"Phone Bill", 65.00, "send"},
+		// Income
+		{"Payroll Deposit", 2500.00, "receive"},
+		{"Freelance Payment", 450.00, "receive"},
+		{"Refund from Amazon", 29.99, "receive"},
+		{"Payment from @alice", 75.00, "receive"},
+	}
+
+	days = min(max(days, 1), maxMockDays)
+
+	// Scale the count with the window (±15%) so long windows aren't implausibly sparse
+	numTxs := opts.Count
+	if numTxs <= 0 {
+		expected := float64(days) * mockTransactionsPerDay
+		numTxs = int(expected * (0.85 + rng.Float64()*0.3))
+		if numTxs < 1 {
+			numTxs = 1
+		}
+	}
+	numTxs = min(numTxs, maxMockTransactions)
+	for i := 0; i < numTxs; i++ {
+		template := templates[rng.Intn(len(templates))]
+		daysAgo := rng.Intn(days)
+		txDate := now.AddDate(0, 0, -daysAgo)
+
+		// Add variance to amounts (80% - 120%) to make it more realistic
+		variance := 0.8 + rng.Float64()*0.4
+		txCurrency := pickMockCurrency(opts.Currency, rng)
+		amount := convertMockAmount(template.amount*variance, txCurrency)
+
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_mock_%d", i),
+			"type":        template.txType,
+			"amount":      amount,
+			"description": template.description,
+			"date":        txDate.Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    txCurrency,
+		})
+	}
+
+	return transactions
+}
+
+// generateMockSubscriptionTransactions creates recurring payment patterns for subscription detection
+func generateMockSubscriptionTransactions(months int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	transactions := []map[string]interface{}{}
+
+	// Subscription templates with recurring patterns
+	subscriptions := []struct {
+		merchant  string
+		amount    float64
+		frequency int // days between payments
+	}{
+		{"Netflix Subscription", 15.99, 30},
+		{"Spotify Premium", 10.99, 30},
+		{"Amazon Prime", 14.99, 30},
+		{"Adobe Creative Cloud", 54.99, 30},
+		{"Planet Fitness", 24.99, 30},
+		{"New York Times Digital", 17.00, 30},
+		{"Hulu (No Ads)", 17.99, 30},
+		{"iCloud Storage 200GB", 2.99, 30},
+		{"GitHub Pro", 7.00, 30},
+		{"Dropbox Plus", 11.99, 30},
+	}
+
+	// Add some irregular subscriptions
+	irregularSubs := []struct {
+		merchant  string
+		amount    float64
+		frequency int
+	}{
+		{"Annual Software License", 299.00, 365},
+		{"Quarterly Insurance", 450.00, 90},
+		{"Biweekly Meal Delivery", 89.99, 14},
+	}
+
+	subscriptions = append(subscriptions, irregularSubs...)
+
+	// Select 5-8 random subscriptions for this user
+	numSubs := 5 + rng.Intn(4)
+	selectedSubs := make([]struct {
+		merchant  string
+		amount    float64
+		frequency int
+	}, numSubs)
+	for i := 0; i < numSubs; i++ {
+		selectedSubs[i] = subscriptions[rng.Intn(len(subscriptions))]
+	}
+
+	// Generate recurring transactions for each subscription
+	daysToGenerate := months * 30
+	for _, sub := range selectedSubs {
+		// A subscription always bills in the same currency
+		subCurrency := pickMockCurrency(opts.Currency, rng)
+		// Some subscriptions started as a free trial
+		startedAsTrial := rng.Intn(4) == 0
+		numOccurrences := daysToGenerate / sub.frequency
+		for j := 0; j < numOccurrences; j++ {
+			daysAgo := j * sub.frequency
+			if daysAgo > daysToGenerate {
+				break
+			}
+
+			txDate := now.AddDate(0, 0, -daysAgo)
+			// Add small variance to amounts (±2%) to simulate real-world pricing variations
+			variance := 0.98 + rng.Float64()*0.04
+			amount := convertMockAmount(sub.amount*variance, subCurrency)
+			if startedAsTrial && j == numOccurrences-1 && numOccurrences >= 3 {
+				amount = 0
+			}
+
+			transactions = append(transactions, map[string]interface{}{
+				"id":          fmt.Sprintf("tx_sub_%s_%d", sub.merchant, j),
+				"type":        "send",
+				"amount":      amount,
+				"description": sub.merchant,
+				"date":        txDate.Format(time.RFC3339),
+				"status":      "completed",
+				"currency":    subCurrency,
+			})
+		}
+	}
+
+	// Add some one-time purchases to make the data more realistic
+	oneTimePurchases := []string{
+		"Whole Foods Market",
+		"Target Store",
+		"Uber Ride",
+		"Amazon.com",
+		"Starbucks Coffee",
+		"Gas Station",
+	}
+
+	for i := 0; i < 20; i++ {
+		purchase := oneTimePurchases[rng.Intn(len(oneTimePurchases))]
+		daysAgo := rng.Intn(daysToGenerate)
+		txDate := now.AddDate(0, 0, -daysAgo)
+		amount := 10.00 + rng.Float64()*90.00
+		txCurrency := pickMockCurrency(opts.Currency, rng)
+
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_once_%d", i),
+			"type":        "send",
+			"amount":      convertMockAmount(amount, txCurrency),
+			"description": purchase,
+			"date":        txDate.Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    txCurrency,
+		})
+	}
+
+	return transactions
+}
+
+// generateMockPayrollTransactions creates a biweekly paycheck series for income tools
+// The pay cycle's phase is random so "days since payday" varies between runs
+func generateMockPayrollTransactions(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	currency := pickMockCurrency(opts.Currency, rng)
+	transactions := []map[string]interface{}{}
+
+	for daysAgo, i := rng.Intn(14), 0; daysAgo <= days; daysAgo, i = daysAgo+14, i+1 {
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_payroll_%d", i),
+			"type":        "receive",
+			"amount":      convertMockAmount(2500.00, currency),
+			"description": "Payroll Deposit",
+			"date":        now.AddDate(0, 0, -daysAgo).Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    currency,
+		})
+	}
+	return transactions
+}
+
+// ============================================================================
+// CUSTOM TOOL: SPENDING ANALYZER
+// ============================================================================
+
+// createSpendingAnalyzerTool builds a tool that analyzes spending patterns
+// Returns insights on categories, velocity, and cash flow
+func createSpendingAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("analyze_spending").
+		Description("Analyze the user's spending patterns over a specified time period. Returns insights about spending velocity, categories, and trends. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":                      tools.IntegerProperty(fmt.Sprintf("Number of days to analyze (default: %d, max: %d)", defaultSpendingDays, maxMockDays)),
+			"use_mock":                  tools.BoolProperty("Use mock data for testing (default: true)"),
+			"include_transactions":      tools.BoolProperty("Include the analyzed transactions in the result (default: false)"),
+			"max_result_transactions":   tools.IntegerProperty("Maximum number of transactions to include when include_transactions is set (default: 200)"),
+			"mock_currency":             tools.StringEnumProperty("Currency for mock data (default: USD)", "USD", "EUR", "GBP", "JPY", "mixed"),
+			"category_weights":          categoryWeightsProperty(),
+			"mock_seed":                 tools.IntegerProperty("Seed for repeatable mock data (default: random)"),
+			"use_sign_convention":       tools.BoolProperty("Treat negative amounts as spending and positive as income, overriding the type field (default: false)"),
+			"insights_only":             tools.BoolProperty("Return only the insights and headline totals, without category and transaction detail (default: false)"),
+			"mock_transaction_count":    tools.IntegerProperty(fmt.Sprintf("Exact number of mock transactions to generate (default: scales with days, max: %d)", maxMockTransactions)),
+			"separate_savings":          tools.BoolProperty("Report deposits to savings as amount_saved instead of counting them as spending (default: false)"),
+			"min_transaction_amount":    tools.NumberProperty("Leave transactions below this amount out of counts and velocity, e.g. 1 to ignore $0.99 app charges (default: 0 = count everything)"),
+			"exclude_small_from_totals": tools.BoolProperty("Also leave transactions below min_transaction_amount out of totals and categories (default: false)"),
+			"focus_category":            tools.StringProperty("Return a deep-dive on just this category instead of the full analysis: its transactions, merchants, daily/weekly trend and share of spending and income, e.g. 'Food & Dining'"),
+			"language":                  languageProperty(),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			// Parse input parameters
+			var params struct {
+				Days                   int              `json:"days"`
+				UseMock                bool             `json:"use_mock"`
+				IncludeTransactions    bool             `json:"include_transactions"`
+				MaxResultTransactions  int              `json:"max_result_transactions"`
+				MockCurrency           string           `json:"mock_currency"`
+				CategoryWeights        []categoryWeight `json:"category_weights"`
+				UseSignConvention      bool             `json:"use_sign_convention"`
+				MockSeed               int64            `json:"mock_seed"`
+				InsightsOnly           bool             `json:"insights_only"`
+				MockTransactionCount   int              `json:"mock_transaction_count"`
+				SeparateSavings        bool             `json:"separate_savings"`
+				MinTransactionAmount   float64          `json:"min_transaction_amount"`
+				ExcludeSmallFromTotals bool             `json:"exclude_small_from_totals"`
+				FocusCategory          string           `json:"focus_category"`
+				Language               string           `json:"language"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				// Default to mock mode
+				params.UseMock = true
+				params.Days = defaultSpendingDays
+			}
+
+			// Fall back to the configured default window if not specified
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+			// Bounds the daily series and monthly buckets built from days
+			params.Days = min(params.Days, maxMockDays)
+			if params.MaxResultTransactions <= 0 {
+				params.MaxResultTransactions = 200
+			}
+
+			var transactions []map[string]interface{}
+
+			// STEP 1: Get transaction data (mock or real)
+			if params.UseMock {
+				// Generate mock transactions
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{Currency: params.MockCurrency, Seed: params.MockSeed, Count: params.MockTransactionCount})
+				log.Printf("📊 Generated %d mock transactions for analysis", len(transactions))
+			} else {
+				// Fetch real transactions from Liminal API
+				var err error
+				transactions, err = fetchRawTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit": 100,
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			// STEP 2: Analyze the data
+			transactions = normalizeTransactionAmounts(transactions, params.UseSignConvention)
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in spending analysis: %v", err)
+			}
+			minCounted := math.Max(params.MinTransactionAmount, 0)
+			if minCounted > 0 && params.ExcludeSmallFromTotals {
+				kept := make([]Transaction, 0, len(parsed))
+				for _, tx := range parsed {
+					if tx.Amount >= minCounted {
+						kept = append(kept, tx)
+					}
+				}
+				parsed = kept
+			}
+
+			// A tight answer to "tell me about my dining", without the rest of the analysis
+			if focus := strings.TrimSpace(params.FocusCategory); focus != "" {
+				return &core.ToolResult{
+					Success: true,
+					Data: map[string]interface{}{
+						"period_days":    params.Days,
+						"category_focus": analyzeCategory(parsed, focus, params.CategoryWeights, params.Days, params.MaxResultTransactions, time.Now()),
+						"skipped_count":  len(parseErrs),
+						"data_source":    map[string]bool{"is_mock": params.UseMock},
+						"generated_at":   time.Now().Format(time.RFC3339),
+					},
+				}, nil
+			}
+
+			analysis := analyzeTransactions(parsed, params.Days, params.CategoryWeights, params.Language, params.SeparateSavings, minCounted)
+
+			// Talking points only: keeps the tool result small in the LLM context
+			if params.InsightsOnly {
+				compact := map[string]interface{}{}
+				for _, key := range []string{"insights", "summary", "total_spent", "total_received", "net_cash_flow", "avg_daily_spend", "amount_saved", "savings_rate_percent"} {
+					if value, ok := analysis[key]; ok {
+						compact[key] = value
+					}
+				}
+				return &core.ToolResult{
+					Success: true,
+					Data: map[string]interface{}{
+						"period_days":  params.Days,
+						"analysis":     compact,
+						"data_source":  map[string]bool{"is_mock": params.UseMock},
+						"generated_at": time.Now().Format(time.RFC3339),
+					},
+				}, nil
+			}
+
+			// STEP 3: Return insights
+			result := map[string]interface{}{
+				"period_days":        params.Days,
+				"total_transactions": len(transactions),
+				"analysis":           analysis,
+				"skipped_count":      len(parseErrs),
+				"data_source":        map[string]bool{"is_mock": params.UseMock},
+				"generated_at":       time.Now().Format(time.RFC3339),
+			}
+
+			// Echo transactions back, capped so large histories don't balloon the payload
+			if params.IncludeTransactions {
+				sample, truncated := capTransactions(transactions, params.MaxResultTransactions)
+				result["transactions"] = sample
+				result["transactions_truncated"] = truncated
+				result["transactions_total"] = len(transactions)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// capTransactions returns at most max transactions, most recent first
+// The second return value reports whether any were dropped
+func capTransactions(transactions []map[string]interface{}, max int) ([]map[string]interface{}, bool) {
+	// Compare parsed times: raw strings misorder offsets and non-zero-padded dates
+	type dated struct {
+		tx   map[string]interface{}
+		date time.Time
+		ok   bool
+	}
+	entries := make([]dated, len(transactions))
+	for i, tx := range transactions {
+		dateStr := transactionDateString(tx)
+		date, err := parseTransactionDate(dateStr)
+		entries[i] = dated{tx: tx, date: date, ok: err == nil}
+	}
+	// Undated transactions go last
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].ok != entries[j].ok {
+			return entries[i].ok
+		}
+		return entries[j].date.Before(entries[i].date)
+	})
+
+	sorted := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		sorted[i] = entry.tx
+	}
+
+	if len(sorted) <= max {
+		return sorted, false
+	}
+	return sorted[:max], true
+}
+
+// spendingInsight is one analyze_spending insight with the weight it was ranked by
+// insight_ranking returns these in the same order as the plain insights list
+type spendingInsight struct {
+	Key    string `json:"key"`
+	Text   string `json:"text"`
+	Impact int    `json:"impact"`
+}
+
+// insightImpact weights each insight by how much it should change what the user does:
+// alerts first, then things to act on or celebrate, then context, then plain counts
+var insightImpact = map[string]int{
+	"spending.negative_flow":   100,
+	"spending.top_category":    70,
+	"spending.amount_saved":    65,
+	"spending.none":            65,
+	"spending.positive_flow":   60,
+	"spending.uncategorizable": 50,
+	"spending.sparse_history":  45,
+	"spending.refunds":         35,
+	"spending.avg_daily":       20,
+	"spending.count":           10,
+}
+
+// analyzeTransactions processes transaction data and returns spending insights
+// Calculates totals, categories, velocity, and generates actionable insights
+// Transactions below minCounted still add to totals but not to counts or velocity; 0 counts everything
+func analyzeTransactions(transactions []Transaction, days int, weights []categoryWeight, lang string, separateSavings bool, minCounted float64) map[string]interface{} {
+	// Optionally pull deposits to savings out of spending so they can be celebrated instead
+	var amountSaved float64
+	var depositCount int
+	if separateSavings {
+		kept := make([]Transaction, 0, len(transactions))
+		for _, tx := range transactions {
+			if tx.Type == "send" && isSavingsDeposit(tx.Description) {
+				amountSaved += tx.Amount
+				depositCount++
+				continue
+			}
+			kept = append(kept, tx)
+		}
+		transactions = kept
+	}
+
+	if len(transactions) == 0 {
+		return map[string]interface{}{
+			"summary": "No transactions found in the specified period",
+		}
+	}
+
+	// Calculate basic metrics
+	var totalSpent, totalReceived float64
+	// Refunds are money coming back, not income, so they're tracked on their own
+	var refundTotal float64
+	var refundCount int
+	refundSources := make(map[string]float64)
+	var spendCount, receiveCount, smallCount int
+	categorySpending, categoryCount := spendByCategory(transactions, weights)
+
+	// Monthly buckets let each category be compared with its own earlier months
+	categoryMonthly := monthlyCategorySpend(transactions, weights, days/30, time.Now())
+
+	// Track the span the data actually covers, which can be shorter than the window
+	earliest, latest := transactions[0].Date, transactions[0].Date
+	// Spending with no description can't be categorized; count it rather than hide it in the fallback
+	uncategorizableCount := 0
+
+	for _, tx := range transactions {
+		if tx.Date.Before(earliest) {
+			earliest = tx.Date
+		}
+		if tx.Date.After(latest) {
+			latest = tx.Date
+		}
+		// Tiny charges still cost money, but they'd inflate counts and the velocity label
+		counted := tx.Amount >= minCounted
+		if !counted {
+			smallCount++
+		}
+		switch tx.Type {
+		case "send":
+			totalSpent += tx.Amount
+			if counted {
+				spendCount++
+			}
+			if strings.TrimSpace(tx.Description) == "" {
+				uncategorizableCount++
+			}
+		case "receive":
+			totalReceived += tx.Amount
+			if counted {
+				receiveCount++
+			}
+			if isRefund(tx.Description) {
+				refundTotal += tx.Amount
+				refundCount++
+				refundSources[refundSource(tx.Description)] += tx.Amount
+			}
+		}
+	}
+
+	activeDays := int(latest.Sub(earliest).Hours()/24) + 1
+	if activeDays > days {
+		activeDays = days
+	}
+	avgDailySpend := totalSpent / float64(days)
+	avgDailySpendActive := totalSpent / float64(activeDays)
+	netCashFlow := totalReceived - totalSpent
+
+	// Find top spending categories
+	type categoryInfo struct {
+		name       string
+		amount     float64
+		count      int
+		percentage float64
+	}
+	categories := []categoryInfo{}
+	for name, amount := range categorySpending {
+		percentage := 0.0
+		if totalSpent > 0 {
+			percentage = (amount / totalSpent) * 100
+		}
+		categories = append(categories, categoryInfo{
+			name:       name,
+			amount:     amount,
+			count:      categoryCount[name],
+			percentage: percentage,
+		})
+	}
+	// Sort by amount (highest first)
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].amount > categories[j].amount
+	})
+
+	// Take top 5 categories
+	topCategories := []map[string]interface{}{}
+	for i := 0; i < len(categories) && i < 5; i++ {
+		entry := map[string]interface{}{
+			"category":   categories[i].name,
+			"amount":     fmt.Sprintf("%.2f", categories[i].amount),
+			"count":      categories[i].count,
+			"percentage": fmt.Sprintf("%.1f%%", categories[i].percentage),
+		}
+		if change, ok := changeVsAverage(categoryMonthly[categories[i].name]); ok {
+			direction := "above"
+			if change < 0 {
+				direction = "below"
+			}
+			entry["vs_average_percent"] = fmt.Sprintf("%+.1f%%", change)
+			entry["vs_average"] = fmt.Sprintf("%.0f%% %s your normal month", math.Abs(change), direction)
+		}
+		topCategories = append(topCategories, entry)
+	}
+
+	// Generate human-readable insights, ranked by impact below
+	ranked := []spendingInsight{}
+	add := func(key string, args ...interface{}) {
+		ranked = append(ranked, spendingInsight{Key: key, Text: message(lang, key, args...), Impact: insightImpact[key]})
+	}
+	if amountSaved > 0 {
+		add("spending.amount_saved", amountSaved, depositCount)
+	}
+	// Income only: say so instead of "0 transactions" and "$0.00 a day"
+	if totalSpent == 0 {
+		add("spending.none")
+	} else {
+		add("spending.count", spendCount, days)
+		add("spending.avg_daily", avgDailySpend, days)
+	}
+	// Only worth calling out when the data is clearly sparser than the window, and there's spending to average
+	if activeDays < days*3/4 && totalSpent > 0 {
+		add("spending.sparse_history", activeDays, avgDailySpendActive)
+	}
+
+	if uncategorizableCount > 0 {
+		add("spending.uncategorizable", uncategorizableCount)
+	}
+
+	if netCashFlow > 0 {
+		add("spending.positive_flow", netCashFlow)
+	} else if netCashFlow < 0 {
+		add("spending.negative_flow", math.Abs(netCashFlow))
+	}
+
+	if refundCount > 0 {
+		add("spending.refunds", refundTotal, refundCount)
+	}
+
+	if len(topCategories) > 0 {
+		topCat := categories[0]
+		add("spending.top_category", topCat.name, topCat.percentage)
+	}
+
+	// Most important first, so an agent that only mentions one leads with it
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Impact > ranked[j].Impact })
+	insights := make([]string, len(ranked))
+	for i, insight := range ranked {
+		insights[i] = insight.Text
+	}
+
+	result := map[string]interface{}{
+		"total_spent":                 fmt.Sprintf("%.2f", totalSpent),
+		"total_received":              fmt.Sprintf("%.2f", totalReceived),
+		"total_income":                fmt.Sprintf("%.2f", totalReceived-refundTotal),
+		"refunds":                     summarizeRefunds(refundTotal, refundCount, refundSources),
+		"net_cash_flow":               fmt.Sprintf("%.2f", netCashFlow),
+		"spend_count":                 spendCount,
+		"receive_count":               receiveCount,
+		"avg_daily_spend":             fmt.Sprintf("%.2f", avgDailySpend),
+		"avg_daily_spend_over_window": fmt.Sprintf("%.2f", avgDailySpend),
+		"avg_daily_spend_active":      fmt.Sprintf("%.2f", avgDailySpendActive),
+		"active_days":                 activeDays,
+		"uncategorizable_count":       uncategorizableCount,
+		"velocity":                    calculateVelocity(spendCount, days),
+		"top_categories":              topCategories,
+		"daily_spend_series":          dailySpendSeries(transactions, days, time.Now()),
+		"insights":                    insights,
+		"insight_ranking":             ranked,
+	}
+	if minCounted > 0 {
+		result["min_transaction_amount"] = fmt.Sprintf("%.2f", minCounted)
+		result["small_transaction_count"] = smallCount
+	}
+	if separateSavings {
+		// Deposits are already out of totalSpent, so this rate counts them as saved
+		savingsRate := 0.0
+		if income := totalReceived - refundTotal; income > 0 {
+			savingsRate = (income - totalSpent) / income * 100
+		}
+		result["amount_saved"] = fmt.Sprintf("%.2f", amountSaved)
+		result["savings_deposits"] = depositCount
+		result["savings_rate_percent"] = fmt.Sprintf("%.1f", savingsRate)
+	}
+	return result
+}
+
+// spendByCategory totals spending amount and transaction count per category
+func spendByCategory(transactions []Transaction, weights []categoryWeight) (map[string]float64, map[string]int) {
+	amounts := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, tx := range transactions {
+		if tx.Type != "send" {
+			continue
+		}
+		category := categorizeTransactionWeighted(tx.Description, weights)
+		amounts[category] += tx.Amount
+		counts[category]++
+	}
+	return amounts, counts
+}
+
+// monthlyCategorySpend buckets spending per category into 30-day months counted
+// back from now (index 0 = most recent). Needs at least 2 months to be useful.
+func monthlyCategorySpend(transactions []Transaction, weights []categoryWeight, months int, now time.Time) map[string][]float64 {
+	monthly := make(map[string][]float64)
+	if months < 2 {
+		return monthly
+	}
+	for _, tx := range transactions {
+		if tx.Type != "send" {
+			continue
+		}
+		month := int(now.Sub(tx.Date).Hours() / 24 / 30)
+		if month < 0 || month >= months {
+			continue
+		}
+		category := categorizeTransactionWeighted(tx.Description, weights)
+		if monthly[category] == nil {
+			monthly[category] = make([]float64, months)
+		}
+		monthly[category][month] += tx.Amount
+	}
+	return monthly
+}
+
+// dailySpendSeries totals spending per calendar day for a sparkline: exactly days
+// entries, oldest first, ending today, with zeros on days without spending
+func dailySpendSeries(transactions []Transaction, days int, now time.Time) []map[string]interface{} {
+	if days <= 0 {
+		return []map[string]interface{}{}
+	}
+	byDay := make(map[string]float64)
+	for _, tx := range transactions {
+		if tx.Type == "send" {
+			byDay[tx.Date.In(now.Location()).Format("2006-01-02")] += tx.Amount
+		}
+	}
+	series := make([]map[string]interface{}, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+		series = append(series, map[string]interface{}{
+			"date":   date,
+			"amount": math.Round(byDay[date]*100) / 100,
+		})
+	}
+	return series
+}
+
+// analyzeCategory is analyze_spending's focus_category deep-dive: one category's
+// transactions (newest first, capped at maxTransactions), merchants, daily and
+// weekly series, weekly trend, and its share of total spending and of income.
+// The category matches case-insensitively; an unknown one lists the categories found.
+func analyzeCategory(transactions []Transaction, category string, weights []categoryWeight, days, maxTransactions int, now time.Time) map[string]interface{} {
+	var totalSpent, income float64
+	matched := []Transaction{}
+	found := map[string]bool{}
+	for _, tx := range transactions {
+		switch tx.Type {
+		case "receive":
+			if !isRefund(tx.Description) {
+				income += tx.Amount
+			}
+		case "send":
+			totalSpent += tx.Amount
+			name := categorizeTransactionWeighted(tx.Description, weights)
+			found[name] = true
+			if strings.EqualFold(name, category) {
+				category = name
+				matched = append(matched, tx)
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return map[string]interface{}{
+			"category":             category,
+			"total":                "0.00",
+			"count":                0,
+			"available_categories": sortedKeys(found),
+			"summary":              fmt.Sprintf("No %s spending in the last %d days.", category, days),
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Date.After(matched[j].Date) })
+
+	total := 0.0
+	merchantTotals := make(map[string]float64)
+	merchantCounts := make(map[string]int)
+	listed := []map[string]interface{}{}
+	// Full 7-day buckets counted back from now (index 0 = most recent), as categoryTrend
+	// expects; a partial week at the far end would drag the trend down
+	weeks := days / 7
+	if weeks < 1 {
+		weeks = 1
+	}
+	weekly := make([]float64, weeks)
+	for _, tx := range matched {
+		total += tx.Amount
+		merchantTotals[tx.Description] += tx.Amount
+		merchantCounts[tx.Description]++
+		if week := int(now.Sub(tx.Date).Hours() / 24 / 7); week >= 0 && week < weeks {
+			weekly[week] += tx.Amount
+		}
+		if len(listed) < maxTransactions {
+			listed = append(listed, map[string]interface{}{
+				"id":          tx.ID,
+				"date":        tx.Date.Format(time.RFC3339),
+				"description": tx.Description,
+				"amount":      fmt.Sprintf("%.2f", tx.Amount),
+			})
+		}
+	}
+
+	merchants := []map[string]interface{}{}
+	for name, amount := range merchantTotals {
+		merchants = append(merchants, map[string]interface{}{
+			"merchant": name,
+			"total":    math.Round(amount*100) / 100,
+			"count":    merchantCounts[name],
+		})
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		return merchants[i]["total"].(float64) > merchants[j]["total"].(float64)
+	})
+
+	weeklySeries := []map[string]interface{}{}
+	for i := weeks - 1; i >= 0; i-- {
+		weeklySeries = append(weeklySeries, map[string]interface{}{
+			"week_start": now.AddDate(0, 0, -(i+1)*7).Format("2006-01-02"),
+			"amount":     fmt.Sprintf("%.2f", weekly[i]),
+		})
+	}
+	trend := categoryTrend(weekly)
+
+	result := map[string]interface{}{
+		"category":               category,
+		"total":                  fmt.Sprintf("%.2f", total),
+		"count":                  len(matched),
+		"average_transaction":    fmt.Sprintf("%.2f", total/float64(len(matched))),
+		"share_of_spending":      fmt.Sprintf("%.1f%%", total/totalSpent*100),
+		"merchants":              merchants,
+		"transactions":           listed,
+		"transactions_truncated": len(matched) > len(listed),
+		"daily_series":           dailySpendSeries(matched, days, now),
+		"weekly_series":          weeklySeries,
+		"direction":              trend.direction,
+		"slope_per_week":         fmt.Sprintf("%+.2f", trend.slope),
+		"projected_next_week":    fmt.Sprintf("%.2f", trend.projected),
+	}
+	summary := fmt.Sprintf("You spent $%.2f on %s across %d transactions (%.0f%% of spending), mostly at %s. It's %s week to week.",
+		total, category, len(matched), total/totalSpent*100, merchants[0]["merchant"], trend.direction)
+	if income > 0 {
+		result["share_of_income"] = fmt.Sprintf("%.1f%%", total/income*100)
+		summary = fmt.Sprintf("You spent $%.2f on %s across %d transactions (%.0f%% of spending, %.0f%% of income), mostly at %s. It's %s week to week.",
+			total, category, len(matched), total/totalSpent*100, total/income*100, merchants[0]["merchant"], trend.direction)
+	}
+	result["summary"] = summary
+	return result
+}
+
+// changeVsAverage compares the most recent month (index 0) with the average of
+// the earlier months. It reports false when there is no earlier spend to
+// compare against.
+func changeVsAverage(monthly []float64) (float64, bool) {
+	if len(monthly) < 2 {
+		return 0, false
+	}
+	var earlier float64
+	for _, amount := range monthly[1:] {
+		earlier += amount
+	}
+	average := earlier / float64(len(monthly)-1)
+	if average <= 0 {
+		return 0, false
+	}
+	return (monthly[0] - average) / average * 100, true
+}
+
+// categoryRule maps a spending category to the keywords that identify it
+type categoryRule struct {
+	Name     string
+	Keywords []string
+}
+
+// fallbackCategory labels transactions that match no rule (FALLBACK_CATEGORY)
+var fallbackCategory = "Other"
+
+// categoryRules lists categories in priority order; earlier rules win ties
+var categoryRules = []categoryRule{
+	{"Food & Dining", []string{"starbucks", "coffee", "chipotle", "pizza", "food", "doordash", "restaurant", "cafe"}},
+	{"Transportation", []string{"uber", "lyft", "gas", "metro", "parking"}},
+	{"Shopping", []string{"amazon", "target", "nike", "store"}},
+	{"Entertainment", []string{"netflix", "spotify", "movie", "steam", "hulu", "disney"}},
+	{"Bills & Utilities", []string{"bill", "electric", "internet", "phone"}},
+}
+
+// categoryMatch is one category's score for a description and the keywords that hit
+type categoryMatch struct {
+	Category string
+	Score    float64
+	Keywords []string
+}
+
+// categoryWeight lets callers boost, dampen, or add a keyword for a category
+// A weight of 0 disables the keyword for that category
+type categoryWeight struct {
+	Category string  `json:"category"`
+	Keyword  string  `json:"keyword"`
+	Weight   float64 `json:"weight"`
+}
+
+// defaultKeywordWeights dampens generic keywords that overlap other categories
+// (e.g. "Netflix bill" should be Entertainment, not Bills). Unlisted keywords weigh 1.
+var defaultKeywordWeights = map[string]float64{
+	"amazon": 0.75,
+	"bill":   0.75,
+	"store":  0.5,
+}
+
+// scoreCategoriesWeighted scores every category against a description
+// Each matched keyword adds its weight; only categories with a positive score
+// are returned, highest score first with rule order breaking ties
+func scoreCategoriesWeighted(description string, weights []categoryWeight) []categoryMatch {
+	text := strings.ToLower(description)
+
+	overrides := make(map[string]map[string]float64)
+	for _, w := range weights {
+		category := strings.ToLower(w.Category)
+		if overrides[category] == nil {
+			overrides[category] = make(map[string]float64)
+		}
+		overrides[category][strings.ToLower(w.Keyword)] = w.Weight
+	}
+
+	matches := []categoryMatch{}
+	seen := make(map[string]bool)
+	for _, rule := range categoryRules {
+		match := categoryMatch{Category: rule.Name}
+		ruleOverrides := overrides[strings.ToLower(rule.Name)]
+		seen[strings.ToLower(rule.Name)] = true
+
+		for _, keyword := range rule.Keywords {
+			if !strings.Contains(text, keyword) {
+				continue
+			}
+			weight := 1.0
+			if w, ok := defaultKeywordWeights[keyword]; ok {
+				weight = w
+			}
+			if w, ok := ruleOverrides[keyword]; ok {
+				weight = w
+			}
+			if weight > 0 {
+				match.Score += weight
+				match.Keywords = append(match.Keywords, keyword)
+			}
+		}
+		// Caller-supplied keywords that aren't part of the built-in rule
+		for keyword, weight := range ruleOverrides {
+			if weight > 0 && !containsString(rule.Keywords, keyword) && strings.Contains(text, keyword) {
+				match.Score += weight
+				match.Keywords = append(match.Keywords, keyword)
+			}
+		}
+
+		if match.Score > 0 {
+			matches = append(matches, match)
+		}
+	}
+
+	// Weights may also introduce categories that have no built-in rule
+	for _, w := range weights {
+		category := strings.ToLower(w.Category)
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		match := categoryMatch{Category: w.Category}
+		for keyword, weight := range overrides[category] {
+			if weight > 0 && strings.Contains(text, keyword) {
+				match.Score += weight
+				match.Keywords = append(match.Keywords, keyword)
+			}
+		}
+		if match.Score > 0 {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// categorizeTransaction maps merchant descriptions to spending categories
+// Uses keyword scoring to classify transactions
+func categorizeTransaction(description string) string {
+	return categorizeTransactionWeighted(description, nil)
+}
+
+// categorizeTransactionWeighted categorizes with caller-supplied keyword weights
+func categorizeTransactionWeighted(description string, weights []categoryWeight) string {
+	if matches := scoreCategoriesWeighted(description, weights); len(matches) > 0 {
+		return matches[0].Category
+	}
+	return fallbackCategory
+}
+
+// categoryWeightsProperty is the shared schema for the category_weights param
+func categoryWeightsProperty() map[string]interface{} {
+	return tools.ArrayProperty("Optional keyword weights to override categorization, e.g. [{\"category\": \"Entertainment\", \"keyword\": \"prime video\", \"weight\": 3}]",
+		tools.ObjectSchema(map[string]interface{}{
+			"category": tools.StringProperty("Category the keyword points to"),
+			"keyword":  tools.StringProperty("Case-insensitive keyword to match in the description"),
+			"weight":   tools.NumberProperty("Score added per match (default keywords weigh 1; 0 disables)"),
+		}, "category", "keyword", "weight"))
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateVelocity determines spending frequency (low/moderate/high)
+// Based on average transactions per week
+func calculateVelocity(transactionCount, days int) string {
+	txPerWeek := float64(transactionCount) / float64(days) * 7
+
+	switch {
+	case txPerWeek < 2:
+		return "low"
+	case txPerWeek < 7:
+		return "moderate"
+	default:
+		return "high"
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: SUBSCRIPTION ANALYZER
+// ============================================================================
+
+// createSubscriptionAnalyzerTool builds a tool that detects recurring payments
+// Identifies subscriptions by finding payment patterns with regular intervals
+func createSubscriptionAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("analyze_subscriptions").
+		Description("Scan transaction history to identify recurring subscriptions and recurring payments. Returns subscription patterns, total monthly costs, and cancellation insights. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"timeframe_months":    tools.IntegerProperty(fmt.Sprintf("Number of months to analyze for recurring patterns (default: %d)", defaultSubscriptionMonths)),
+			"min_amount":          tools.NumberProperty("Minimum amount to be considered as subscription (default: 1.00)"),
+			"max_amount":          tools.NumberProperty("Maximum amount to be considered as a subscription (default: 999.99)"),
+			"use_mock":            tools.BoolProperty("Use mock data for testing (default: true)"),
+			"mock_currency":       tools.StringEnumProperty("Currency for mock data (default: USD)", "USD", "EUR", "GBP", "JPY", "mixed"),
+			"mock_seed":           tools.IntegerProperty("Seed for repeatable mock data (default: random)"),
+			"use_sign_convention": tools.BoolProperty("Treat negative amounts as spending and positive as income, overriding the type field (default: false)"),
+			"verbose":             tools.BoolProperty("Include full detail (occurrences, total paid, confidence score) for each subscription (default: false)"),
+			"expensive_threshold": tools.NumberProperty("Monthly cost above which a single subscription is flagged for review (default: 30)"),
+			"language":            languageProperty(),
+			"max_warnings":        tools.IntegerProperty("Maximum warnings to return, most severe first (default: 5)"),
+			"primary_currency":    tools.StringProperty("The user's home currency; subscriptions billed in any other are listed as foreign (default: the currency most transactions use)"),
+			"exchange_rates":      exchangeRatesProperty(),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				TimeframeMonths    int                `json:"timeframe_months"`
+				MinAmount          float64            `json:"min_amount"`
+				MaxAmount          float64            `json:"max_amount"`
+				UseMock            bool               `json:"use_mock"`
+				MockCurrency       string             `json:"mock_currency"`
+				UseSignConvention  bool               `json:"use_sign_convention"`
+				MockSeed           int64              `json:"mock_seed"`
+				Verbose            bool               `json:"verbose"`
+				ExpensiveThreshold float64            `json:"expensive_threshold"`
+				Language           string             `json:"language"`
+				MaxWarnings        int                `json:"max_warnings"`
+				PrimaryCurrency    string             `json:"primary_currency"`
+				ExchangeRates      map[string]float64 `json:"exchange_rates"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				// Default to mock mode
+				params.UseMock = true
+				params.TimeframeMonths = defaultSubscriptionMonths
+				params.MinAmount = 1.00
+				params.MaxAmount = 999.99
+			}
+
+			// Set defaults
+			if params.TimeframeMonths == 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			if params.MinAmount == 0 {
+				params.MinAmount = 1.00
+			}
+			if params.MaxAmount == 0 {
+				params.MaxAmount = 999.99
+			}
+			if params.ExpensiveThreshold <= 0 {
+				params.ExpensiveThreshold = 30
+			}
+			if params.MaxWarnings <= 0 {
+				params.MaxWarnings = defaultMaxWarnings
+			}
+
+			var transactions []map[string]interface{}
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+
+			// Get transaction data (mock or real)
+			if params.UseMock {
+				// Generate mock subscription transactions
+				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{Currency: params.MockCurrency, Seed: params.MockSeed})
+				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+			} else {
+				// Fetch real transactions
+				var err error
+				transactions, err = fetchRawTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			// Same window for mock and real data, so every figure below covers exactly timeframe_months
+			transactions = transactionsSince(normalizeTransactionAmounts(transactions, params.UseSignConvention), cutoffDate)
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, params.MinAmount, params.MaxAmount)
+			primary := strings.ToUpper(strings.TrimSpace(params.PrimaryCurrency))
+			if primary == "" {
+				primary = dominantCurrency(transactions)
+			}
+			rates := exchangeRates(primary, params.UseMock, params.ExchangeRates)
+			result := map[string]interface{}{
+				"analysis_period":                fmt.Sprintf("%d months", params.TimeframeMonths),
+				"total_transactions_scanned":     len(transactions),
+				"subscriptions_found":            len(subscriptions),
+				"subscriptions":                  formatSubscriptions(subscriptions, params.Verbose),
+				"total_monthly_cost":             calculateTotalMonthlyCost(subscriptions),
+				"cost_by_frequency":              calculateCostByFrequency(subscriptions),
+				"expensive_subscriptions":        findExpensiveSubscriptions(subscriptions, params.ExpensiveThreshold),
+				"converted_trials":               findConvertedTrials(transactions, cutoffDate),
+				"primary_currency":               primary,
+				"foreign_currency_subscriptions": findForeignCurrencySubscriptions(subscriptions, primary, rates),
+				"warnings":                       generateWarnings(subscriptions, params.Language, params.MaxWarnings),
+				"data_source":                    map[string]bool{"is_mock": params.UseMock},
+				"generated_at":                   now.Format(time.RFC3339),
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// RecurringOpts controls which transactions detectRecurring considers and how it groups them
+type RecurringOpts struct {
+	Type      string    // transaction type to scan: "send" or "receive"
+	Cutoff    time.Time // ignore transactions before this date
+	MinAmount float64   // 0 means no lower bound
+	MaxAmount float64   // 0 means no upper bound
+	// GroupByAmount splits a counterparty's payments into amount clusters, so
+	// two plans from the same merchant are separate patterns. Payments within
+	// the merchant's tolerance (see amountTolerance) stay in one cluster.
+	// Income leaves it off since paychecks often vary.
+	GroupByAmount bool
+	// Tolerances overrides amountTolerances for this scan; nil uses the configured ones
+	Tolerances map[string]float64
+	// DetectTrials sets aside a first payment well below the rest (e.g. a $0
+	// free trial) so the pattern is built from the full-price charges.
+	DetectTrials bool
+}
+
+// defaultAmountTolerance is how far (as a fraction) a merchant's payments may
+// drift and still count as the same recurring charge (AMOUNT_TOLERANCE)
+var defaultAmountTolerance = 0.05
+
+// amountTolerances overrides the default per merchant keyword or category
+// (AMOUNT_TOLERANCES). Usage-based bills swing month to month and need more
+// slack than fixed-price subscriptions.
+//
+// Keep a tolerance below the smallest price increase you'd want to notice: an
+// increase inside it is treated as noise and averaged into one pattern, while
+// one above it starts a new pattern at the new price. detectPriceChange reads
+// raw payments, so price_increase_impact sees increases either way.
+var amountTolerances = map[string]float64{
+	"bills & utilities": 0.35,
+}
+
+// amountTolerance resolves the tolerance for a merchant: a keyword match in the
+// merchant name first, then its category, then defaultAmountTolerance
+func amountTolerance(merchant string, overrides map[string]float64) float64 {
+	if overrides == nil {
+		overrides = amountTolerances
+	}
+	lower := strings.ToLower(merchant)
+	best, bestLen := -1.0, 0
+	for key, tolerance := range overrides {
+		if len(key) > bestLen && strings.Contains(lower, key) {
+			best, bestLen = tolerance, len(key)
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	if tolerance, ok := overrides[strings.ToLower(categorizeTransaction(merchant))]; ok {
+		return tolerance
+	}
+	return defaultAmountTolerance
+}
+
+// parseTolerances parses "electric=0.4,Bills & Utilities=0.3" into lowercase keys
+// Invalid entries are logged and skipped
+func parseTolerances(raw string) map[string]float64 {
+	tolerances := make(map[string]float64)
+	for _, entry := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || key == "" || err != nil || tolerance < 0 {
+			if strings.TrimSpace(entry) != "" {
+				log.Printf("⚠️  Ignoring invalid AMOUNT_TOLERANCES entry %q", entry)
+			}
+			continue
+		}
+		tolerances[key] = tolerance
+	}
+	return tolerances
+}
+
+// RecurringPattern is one regularly repeating payment found by detectRecurring
+type RecurringPattern struct {
+	Name            string
+	Currency        string  // uppercase; empty when the transactions carry none
+	Amount          float64 // average amount per occurrence
+	Frequency       string
+	Dates           []time.Time // chronological
+	Intervals       []int       // days between consecutive dates
+	EstimatedNext   string
+	Confidence      string
+	ConfidenceScore float64
+	// Set when DetectTrials found a discounted first payment before Dates[0]
+	TrialDate   time.Time
+	TrialAmount float64
+}
+
+// HasTrial reports whether the pattern started with a trial that converted to paid
+func (p RecurringPattern) HasTrial() bool {
+	return !p.TrialDate.IsZero()
+}
+
+// Occurrences is the number of payments in the pattern
+func (p RecurringPattern) Occurrences() int {
+	return len(p.Dates)
+}
+
+// LastOccurrence is the date of the most recent payment
+func (p RecurringPattern) LastOccurrence() time.Time {
+	return p.Dates[len(p.Dates)-1]
+}
+
+// detectRecurring is the interval-pattern engine shared by subscription, income and bill detection
+// Groups matching transactions by counterparty (and amount), then keeps groups with regular intervals
+func detectRecurring(transactions []map[string]interface{}, opts RecurringOpts) []RecurringPattern {
+	// Payments in different currencies are never one pattern: their amounts aren't comparable
+	type groupKey struct {
+		name     string
+		currency string
+		cluster  int
+	}
+	type payment struct {
+		date   time.Time
+		amount float64
+	}
+	groups := make(map[groupKey][]payment)
+
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		if txType != opts.Type {
+			continue
+		}
+
+		amount, _ := tx["amount"].(float64)
+		if amount < opts.MinAmount || (opts.MaxAmount > 0 && amount > opts.MaxAmount) {
+			continue
+		}
+
+		name := merchantName(tx)
+
+		txDateStr, ok := tx["date"].(string)
+		if !ok {
+			continue
+		}
+		txDate, err := time.Parse(time.RFC3339, txDateStr)
+		if err != nil {
+			continue
+		}
+		if txDate.Before(opts.Cutoff) {
+			continue
+		}
+
+		currency, _ := tx["currency"].(string)
+		key := groupKey{name: name, currency: strings.ToUpper(strings.TrimSpace(currency))}
+		groups[key] = append(groups[key], payment{date: txDate, amount: amount})
+	}
+
+	// Split each counterparty into amount clusters: sorted by amount, a payment
+	// more than the tolerance above the cluster's smallest starts a new one
+	if opts.GroupByAmount {
+		clustered := make(map[groupKey][]payment)
+		for key, payments := range groups {
+			tolerance := amountTolerance(key.name, opts.Tolerances)
+			sort.Slice(payments, func(i, j int) bool { return payments[i].amount < payments[j].amount })
+			floor := payments[0].amount
+			for _, p := range payments {
+				if p.amount > floor*(1+tolerance)+0.005 {
+					key.cluster++
+					floor = p.amount
+				}
+				clustered[key] = append(clustered[key], p)
+			}
+		}
+		groups = clustered
+	}
+
+	patterns := []RecurringPattern{}
+	for key, payments := range groups {
+		if len(payments) < 2 { // Need at least 2 occurrences to detect pattern
+			continue
+		}
+
+		// Sort payments chronologically
+		sort.Slice(payments, func(i, j int) bool {
+			return payments[i].date.Before(payments[j].date)
+		})
+
+		// A first charge under half a steady full price is a trial, not part of the pattern
+		var trial *payment
+		if opts.DetectTrials && len(payments) >= 3 {
+			var restTotal float64
+			for _, p := range payments[1:] {
+				restTotal += p.amount
+			}
+			fullPrice := restTotal / float64(len(payments)-1)
+			steady := true
+			for _, p := range payments[1:] {
+				steady = steady && math.Abs(p.amount-fullPrice) <= fullPrice*0.1
+			}
+			if steady && payments[0].amount < fullPrice*0.5 {
+				trial = &payments[0]
+				payments = payments[1:]
+			}
+		}
+
+		// Calculate intervals between payments
+		dates := make([]time.Time, len(payments))
+		intervals := make([]int, 0, len(payments)-1)
+		var total float64
+		for i, p := range payments {
+			dates[i] = p.date
+			total += p.amount
+			if i > 0 {
+				intervals = append(intervals, int(p.date.Sub(payments[i-1].date).Hours()/24))
+			}
+		}
+
+		// Check if intervals form a regular pattern
+		if !isRegularPattern(intervals) {
+			continue
+		}
+
+		frequency := detectFrequency(intervals)
+		pattern := RecurringPattern{
+			Name:            key.name,
+			Currency:        key.currency,
+			Amount:          math.Round(total/float64(len(payments))*100) / 100,
+			Frequency:       frequency,
+			Dates:           dates,
+			Intervals:       intervals,
+			EstimatedNext:   estimateNextPayment(dates[len(dates)-1], frequency),
+			Confidence:      calculateConfidence(len(dates), intervals),
+			ConfidenceScore: calculateConfidenceScore(len(dates), intervals),
+		}
+		if trial != nil {
+			pattern.TrialDate = trial.date
+			pattern.TrialAmount = trial.amount
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Name != patterns[j].Name {
+			return patterns[i].Name < patterns[j].Name
+		}
+		return patterns[i].Currency < patterns[j].Currency
+	})
+	return patterns
+}
+
+// transactionsSince keeps transactions dated on or after cutoff, dropping undated ones
+func transactionsSince(transactions []map[string]interface{}, cutoff time.Time) []map[string]interface{} {
+	kept := make([]map[string]interface{}, 0, len(transactions))
+	for _, tx := range transactions {
+		date, err := parseTransactionDate(transactionDateString(tx))
+		if err != nil || date.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	return kept
+}
+
+// analyzeForSubscriptions detects recurring payment patterns
+// Groups transactions by merchant and amount cluster, checks for regular intervals
+// Payments before cutoffDate are ignored whether the data is mock or real
+func analyzeForSubscriptions(transactions []map[string]interface{}, cutoffDate time.Time, minAmount, maxAmount float64) []map[string]interface{} {
+	patterns := detectRecurring(transactions, RecurringOpts{
+		Type:          "send", // Only look at outgoing payments
+		Cutoff:        cutoffDate,
+		MinAmount:     minAmount,
+		MaxAmount:     maxAmount,
+		GroupByAmount: true,
+	})
+
+	subscriptions := []map[string]interface{}{}
+	for _, pattern := range patterns {
+		subscriptions = append(subscriptions, map[string]interface{}{
+			"merchant":         pattern.Name,
+			"currency":         pattern.Currency,
+			"amount":           pattern.Amount,
+			"frequency":        pattern.Frequency,
+			"occurrences":      pattern.Occurrences(),
+			"last_occurrence":  pattern.LastOccurrence().Format("2006-01-02"),
+			"estimated_next":   pattern.EstimatedNext,
+			"total_paid":       pattern.Amount * float64(pattern.Occurrences()),
+			"confidence":       pattern.Confidence,
+			"confidence_score": pattern.ConfidenceScore,
+		})
+	}
+	return subscriptions
+}
+
+// findConvertedTrials finds subscriptions that started with a free or discounted trial
+// Groups by merchant only, since the trial charge never matches the full price
+func findConvertedTrials(transactions []map[string]interface{}, cutoffDate time.Time) []map[string]interface{} {
+	patterns := detectRecurring(transactions, RecurringOpts{
+		Type:         "send",
+		Cutoff:       cutoffDate,
+		DetectTrials: true,
+	})
+
+	trials := []map[string]interface{}{}
+	for _, pattern := range patterns {
+		if !pattern.HasTrial() || monthlyEquivalent(pattern.Amount, pattern.Frequency) == 0 {
+			continue
+		}
+		trials = append(trials, map[string]interface{}{
+			"merchant":          pattern.Name,
+			"trial_amount":      pattern.TrialAmount,
+			"trial_start":       pattern.TrialDate.Format("2006-01-02"),
+			"trial_end":         pattern.Dates[0].Format("2006-01-02"),
+			"first_full_charge": pattern.Dates[0].Format("2006-01-02"),
+			"full_price":        pattern.Amount,
+			"frequency":         pattern.Frequency,
+			"message": fmt.Sprintf("Heads up: your %s trial converted to a paid plan on %s and now costs $%.2f %s.",
+				pattern.Name, pattern.Dates[0].Format("Jan 2"), pattern.Amount, pattern.Frequency),
+		})
+	}
+	return trials
+}
+
+// findForeignCurrencySubscriptions lists subscriptions billed in a currency other than primary,
+// which may carry FX fees on every charge. monthly_cost_local converts the monthly cost into
+// primary when a rate is known; otherwise it's left out and only the billed currency is shown.
+func findForeignCurrencySubscriptions(subscriptions []map[string]interface{}, primary string, rates map[string]float64) []map[string]interface{} {
+	foreign := []map[string]interface{}{}
+	for _, sub := range subscriptions {
+		currency, _ := sub["currency"].(string)
+		if currency == "" || currency == primary {
+			continue
+		}
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		monthly := monthlyEquivalent(amount, frequency)
+		entry := map[string]interface{}{
+			"merchant":     sub["merchant"],
+			"currency":     currency,
+			"amount":       amount,
+			"frequency":    frequency,
+			"monthly_cost": math.Round(monthly*100) / 100,
+			"note":         fmt.Sprintf("Billed in %s, so each charge may carry a currency conversion fee", currency),
+		}
+		if rate, ok := rates[currency]; ok {
+			entry["monthly_cost_local"] = math.Round(monthly/rate*100) / 100
+			entry["local_currency"] = primary
+		}
+		foreign = append(foreign, entry)
+	}
+	return foreign
+}
+
+// findExpensiveSubscriptions flags subscriptions whose monthly equivalent exceeds threshold
+// Sorted most expensive first, each with a nudge to review whether it's still worth it
+func findExpensiveSubscriptions(subscriptions []map[string]interface{}, threshold float64) []map[string]interface{} {
+	expensive := []map[string]interface{}{}
+	for _, sub := range subscriptions {
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		merchant, _ := sub["merchant"].(string)
+		monthly := monthlyEquivalent(amount, frequency)
+		if monthly <= threshold {
+			continue
+		}
+		expensive = append(expensive, map[string]interface{}{
+			"merchant":     merchant,
+			"amount":       amount,
+			"frequency":    frequency,
+			"monthly_cost": math.Round(monthly*100) / 100,
+			"yearly_cost":  math.Round(monthly*12*100) / 100,
+			"review":       fmt.Sprintf("%s costs $%.2f/month ($%.2f/year). Still getting your money's worth?", merchant, monthly, monthly*12),
+		})
+	}
+	sort.Slice(expensive, func(i, j int) bool {
+		return expensive[i]["monthly_cost"].(float64) > expensive[j]["monthly_cost"].(float64)
+	})
+	return expensive
+}
+
+// formatSubscriptions adds each subscription's monthly cost and, unless verbose,
+// trims it to the fields users care about to keep the LLM context small
+func formatSubscriptions(subscriptions []map[string]interface{}, verbose bool) []map[string]interface{} {
+	formatted := make([]map[string]interface{}, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		monthlyCost := math.Round(monthlyEquivalent(amount, frequency)*100) / 100
+
+		if verbose {
+			full := make(map[string]interface{}, len(sub)+1)
+			for key, value := range sub {
+				full[key] = value
+			}
+			full["monthly_cost"] = monthlyCost
+			formatted = append(formatted, full)
+			continue
+		}
+		formatted = append(formatted, map[string]interface{}{
+			"merchant":       sub["merchant"],
+			"amount":         amount,
+			"currency":       sub["currency"],
+			"frequency":      frequency,
+			"estimated_next": sub["estimated_next"],
+			"monthly_cost":   monthlyCost,
+			"confidence":     sub["confidence"],
+		})
+	}
+	return formatted
+}
+
+// isRegularPattern checks if payment intervals are consistent (within 20% tolerance)
+// Returns true if 70% or more intervals fall within tolerance
+func isRegularPattern(intervals []int) bool {
+	if len(intervals) == 0 {
+		return false
+	}
+	sum := 0
+	for _, interval := range intervals {
+		sum += interval
+	}
+	avg := float64(sum) / float64(len(intervals))
+
+	withinTolerance := 0
+	tolerance := avg * 0.2 // 20% tolerance
+	for _, interval := range intervals {
+		if math.Abs(float64(interval)-avg) <= tolerance {
+			withinTolerance++
+		}
+	}
+	return float64(withinTolerance)/float64(len(intervals)) >= 0.7
+}
+
+// detectFrequency classifies payment frequency based on average interval
+func detectFrequency(intervals []int) string {
+	if len(intervals) == 0 {
+		return "unknown"
+	}
+	sum := 0
+	for _, interval := range intervals {
+		sum += interval
+	}
+	avgDays := float64(sum) / float64(len(intervals))
+
+	switch {
+	case avgDays >= 25 && avgDays <= 35:
+		return "monthly"
+	case avgDays >= 80 && avgDays <= 100:
+		return "quarterly"
+	case avgDays >= 170 && avgDays <= 190:
+		return "semi-annual"
+	case avgDays >= 350 && avgDays <= 380:
+		return "annual"
+	case avgDays >= 7 && avgDays <= 14:
+		return "biweekly"
+	case avgDays >= 1 && avgDays <= 7:
+		return "weekly"
+	default:
+		return "irregular"
+	}
+}
+
+// estimateNextPayment predicts the next payment date based on frequency
+func estimateNextPayment(lastPayment time.Time, frequency string) string {
+	switch frequency {
+	case "daily":
+		return lastPayment.AddDate(0, 0, 1).Format("2006-01-02")
+	case "monthly":
+		return lastPayment.AddDate(0, 1, 0).Format("2006-01-02")
+	case "semi-monthly":
+		return lastPayment.AddDate(0, 0, 15).Format("2006-01-02")
+	case "quarterly":
+		return lastPayment.AddDate(0, 3, 0).Format("2006-01-02")
+	case "semi-annual":
+		return lastPayment.AddDate(0, 6, 0).Format("2006-01-02")
+	case "annual":
+		return lastPayment.AddDate(1, 0, 0).Format("2006-01-02")
+	case "biweekly":
+		return lastPayment.AddDate(0, 0, 14).Format("2006-01-02")
+	case "weekly":
+		return lastPayment.AddDate(0, 0, 7).Format("2006-01-02")
+	default:
+		return "unknown"
+	}
+}
+
+// calculateConfidence maps the numeric confidence score to a label
+// Cutoffs: >= 0.75 is "high", >= 0.5 is "medium", anything lower is "low"
+func calculateConfidence(occurrences int, intervals []int) string {
+	score := calculateConfidenceScore(occurrences, intervals)
+	switch {
+	case score >= 0.75:
+		return "high"
+	case score >= 0.5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// calculateConfidenceScore rates a detected pattern from 0 to 1
+// 60% comes from interval regularity (1 - coefficient of variation) and 40%
+// from the number of occurrences, which saturates at 6 payments
+func calculateConfidenceScore(occurrences int, intervals []int) float64 {
+	if len(intervals) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, interval := range intervals {
+		sum += float64(interval)
+	}
+	mean := sum / float64(len(intervals))
+
+	regularity := 0.0
+	if mean > 0 {
+		var variance float64
+		for _, interval := range intervals {
+			diff := float64(interval) - mean
+			variance += diff * diff
+		}
+		cv := math.Sqrt(variance/float64(len(intervals))) / mean
+		regularity = math.Max(0, 1-cv)
+	}
+
+	occurrenceFactor := math.Min(1, float64(occurrences-1)/5)
+
+	score := 0.6*regularity + 0.4*occurrenceFactor
+	return math.Round(score*100) / 100
+}
+
+// calculateTotalMonthlyCost normalizes all subscriptions to monthly cost
+// Converts quarterly, annual, etc. to equivalent monthly amount
+func calculateTotalMonthlyCost(subscriptions []map[string]interface{}) float64 {
+	var totalMonthly float64
+	for _, sub := range subscriptions {
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		totalMonthly += monthlyEquivalent(amount, frequency)
+	}
+	return math.Round(totalMonthly*100) / 100
+}
+
+// calculateCostByFrequency splits the monthly total by billing frequency
+// Every value is a monthly equivalent, so the parts sum to total_monthly_cost
+func calculateCostByFrequency(subscriptions []map[string]interface{}) map[string]float64 {
+	costByFrequency := make(map[string]float64)
+	for _, sub := range subscriptions {
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		if monthly := monthlyEquivalent(amount, frequency); monthly > 0 {
+			costByFrequency[frequency] += monthly
+		}
+	}
+	for frequency, cost := range costByFrequency {
+		costByFrequency[frequency] = math.Round(cost*100) / 100
+	}
+	return costByFrequency
+}
+
+// monthlyEquivalent converts a payment at the given frequency to its monthly cost
+// It is the single source of truth for frequency multipliers; irregular or unknown frequencies return 0
+func monthlyEquivalent(amount float64, frequency string) float64 {
+	switch frequency {
+	case "daily":
+		return amount * daysPerMonth
+	case "monthly":
+		return amount
+	case "semi-monthly":
+		return amount * 2
+	case "quarterly":
+		return amount / 3
+	case "semi-annual":
+		return amount / 6
+	case "annual":
+		return amount / 12
+	case "biweekly":
+		return amount * 2.167 // ~26 payments/year ÷ 12 months
+	case "weekly":
+		return amount * 4.333 // ~52 payments/year ÷ 12 months
+	default:
+		return 0
+	}
+}
+
+// subscriptionGroups maps a kind of service to merchant keywords, for spotting overlapping subscriptions
+var subscriptionGroups = map[string][]string{
+	"streaming": {"netflix", "hulu", "disney", "prime", "spotify", "hbo", "apple tv", "youtube premium"},
+	"music":     {"spotify", "apple music", "youtube music", "tidal", "pandora"},
+	"cloud":     {"dropbox", "google one", "icloud", "onedrive"},
+	"fitness":   {"peloton", "classpass", "apple fitness", "strava", "planet fitness"},
+	"software":  {"adobe", "github", "office"},
+}
+
+// inSubscriptionGroup reports whether a merchant matches any keyword of the group
+func inSubscriptionGroup(merchant, group string) bool {
+	lower := strings.ToLower(merchant)
+	for _, keyword := range subscriptionGroups[group] {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownSubscription reports whether a merchant matches any subscription group,
+// or calls itself a subscription
+func isKnownSubscription(merchant string) bool {
+	if strings.Contains(strings.ToLower(merchant), "subscription") {
+		return true
+	}
+	for group := range subscriptionGroups {
+		if inSubscriptionGroup(merchant, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Warning severities, least to most urgent
+const (
+	severityInfo       = "info"
+	severitySuggestion = "suggestion"
+	severityAlert      = "alert"
+)
+
+// severityRank orders severities so the most urgent sort first
+var severityRank = map[string]int{severityAlert: 0, severitySuggestion: 1, severityInfo: 2}
+
+// defaultMaxWarnings caps generateWarnings when the caller doesn't choose
+const defaultMaxWarnings = 5
+
+// subscriptionWarning is one generated warning; severity lets the frontend style it
+type subscriptionWarning struct {
+	Severity string `json:"severity"`
+	Text     string `json:"text"`
+}
+
+// generateWarnings creates actionable insights about subscriptions
+// Identifies duplicate categories, inactive subscriptions, and savings opportunities.
+// Results are sorted alert → suggestion → info and capped at maxWarnings.
+func generateWarnings(subscriptions []map[string]interface{}, lang string, maxWarnings int) []subscriptionWarning {
+	warnings := make([]subscriptionWarning, 0)
+	add := func(severity, text string) {
+		warnings = append(warnings, subscriptionWarning{Severity: severity, Text: text})
+	}
+	if len(subscriptions) == 0 {
+		add(severityInfo, message(lang, "subscriptions.none"))
+		return warnings
+	}
+
+	totalMonthly := calculateTotalMonthlyCost(subscriptions)
+	add(severityInfo, message(lang, "subscriptions.monthly", totalMonthly))
+
+	// Check for duplicate categories (e.g., multiple streaming services)
+	merchantCategories := make(map[string][]string)
+	for _, sub := range subscriptions {
+		merchant, _ := sub["merchant"].(string)
+		for category := range subscriptionGroups {
+			if inSubscriptionGroup(merchant, category) {
+				merchantCategories[category] = append(merchantCategories[category], merchant)
+			}
+		}
+	}
+
+	// Warn about duplicate categories, in a stable order
+	categories := make([]string, 0, len(merchantCategories))
+	for category := range merchantCategories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if merchants := merchantCategories[category]; len(merchants) > 1 {
+			add(severitySuggestion, message(lang, "subscriptions.duplicates", category, strings.Join(merchants, ", ")))
+		}
+	}
+
+	// Check for potentially inactive subscriptions
+	now := time.Now()
+	for _, sub := range subscriptions {
+		if looksInactive(sub, now) {
+			merchant, _ := sub["merchant"].(string)
+			lastDateStr, _ := sub["last_occurrence"].(string)
+			add(severityAlert, message(lang, "subscriptions.inactive", merchant, lastDateStr))
+		}
+	}
+
+	// Suggest potential savings
+	if totalMonthly > 50 {
+		savings := math.Round(totalMonthly*0.1*100) / 100
+		add(severitySuggestion, message(lang, "subscriptions.savings_tip", savings))
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return severityRank[warnings[i].Severity] < severityRank[warnings[j].Severity]
+	})
+	if maxWarnings > 0 && len(warnings) > maxWarnings {
+		warnings = warnings[:maxWarnings]
+	}
+	return warnings
+}
+
+// looksInactive is the inactivity heuristic used when there is no usage data:
+// fewer than 3 payments and none in the last 90 days
+func looksInactive(sub map[string]interface{}, now time.Time) bool {
+	occurrences, _ := sub["occurrences"].(int)
+	lastDateStr, _ := sub["last_occurrence"].(string)
+	lastDate, err := time.Parse("2006-01-02", lastDateStr)
+	return err == nil && occurrences < 3 && now.Sub(lastDate).Hours()/24 > 90
+}
+
+// ============================================================================
+// SHARED LIMINAL HELPERS
+// ============================================================================
+
+// liminalMaxRetries is how many times a failed read is retried (LIMINAL_MAX_RETRIES)
+var liminalMaxRetries = 2
+
+// liminalRetryBaseDelay is the first backoff delay; it doubles on each retry
+var liminalRetryBaseDelay = 250 * time.Millisecond
+
+// executeReadWithRetry runs an idempotent read against Liminal, retrying
+// network errors, 5xx and 429 responses with exponential backoff and jitter.
+// Never use this for write operations like send_money.
+func executeReadWithRetry(ctx context.Context, liminalExecutor core.ToolExecutor, req *core.ExecuteRequest) (*core.ExecuteResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := liminalExecutor.Execute(ctx, req)
+		if isAuthFailure(resp) {
+			log.Printf("🔑 %s rejected the session: %s", req.Tool, resp.Error)
+			return nil, errAuthExpired
+		}
+		if !isRetryableLiminalFailure(resp, err) || attempt >= liminalMaxRetries {
+			return resp, err
+		}
+
+		delay := liminalRetryBaseDelay << attempt
+		delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+		log.Printf("🔁 %s failed (attempt %d/%d), retrying in %s", req.Tool, attempt+1, liminalMaxRetries+1, delay.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// errAuthExpired means Liminal rejected the user's JWT, usually because it expired
+var errAuthExpired = errors.New("Your session expired — please log in again")
+
+// isAuthFailure reports whether Liminal rejected the call as unauthenticated (HTTP 401)
+func isAuthFailure(resp *core.ExecuteResponse) bool {
+	return resp != nil && !resp.Success && strings.HasPrefix(resp.Error, "HTTP 401")
+}
+
+// toolErrorResult turns a handler error into a failed ToolResult
+// Expired sessions get a user-facing message and error_code "auth_expired" so
+// the frontend can send the user back through login instead of showing a generic error
+func toolErrorResult(err error) *core.ToolResult {
+	if errors.Is(err, errAuthExpired) {
+		return &core.ToolResult{
+			Success: false,
+			Error:   errAuthExpired.Error(),
+			Data:    map[string]interface{}{"error_code": "auth_expired"},
+		}
+	}
+	return &core.ToolResult{
+		Success: false,
+		Error:   err.Error(),
+	}
+}
+
+// isRetryableLiminalFailure reports whether a failed read is worth retrying
+// Only transient failures qualify: network errors, and 5xx or 429 responses.
+// Marshalling errors, cancellations, auth failures and other 4xx responses won't
+// succeed on a second attempt.
+func isRetryableLiminalFailure(resp *core.ExecuteResponse, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+	if resp == nil || resp.Success {
+		return false
+	}
+	code, ok := liminalStatusCode(resp.Error)
+	return ok && (code >= 500 || code == http.StatusTooManyRequests)
+}
+
+// liminalStatusCode extracts the status from the HTTP executor's "HTTP <code>: <body>" errors
+func liminalStatusCode(message string) (int, bool) {
+	var code int
+	if _, err := fmt.Sscanf(message, "HTTP %d:", &code); err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// fetchTransactions calls get_transactions with the given request and returns the transactions
+// with amounts normalized (see normalizeTransactionAmounts), so negative-amount sends can't reduce totals
+func fetchTransactions(ctx context.Context, liminalExecutor core.ToolExecutor, toolParams *core.ToolParams, txRequest map[string]interface{}) ([]map[string]interface{}, error) {
+	transactions, err := fetchRawTransactions(ctx, liminalExecutor, toolParams, txRequest)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeTransactionAmounts(transactions, false), nil
+}
+
+// fetchRawTransactions is fetchTransactions without amount normalization
+// Only for tools that apply their own sign convention via normalizeTransactionAmounts
+func fetchRawTransactions(ctx context.Context, liminalExecutor core.ToolExecutor, toolParams *core.ToolParams, txRequest map[string]interface{}) ([]map[string]interface{}, error) {
+	txRequestJSON, _ := json.Marshal(txRequest)
+	txResponse, err := executeReadWithRetry(ctx, liminalExecutor, &core.ExecuteRequest{
+		UserID:    toolParams.UserID,
+		Tool:      "get_transactions",
+		Input:     txRequestJSON,
+		RequestID: toolParams.RequestID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	if !txResponse.Success {
+		return nil, fmt.Errorf("transaction fetch failed: %s", txResponse.Error)
+	}
+
+	var transactions []map[string]interface{}
+	var txData map[string]interface{}
+	if err := json.Unmarshal(txResponse.Data, &txData); err == nil {
+		if txArray, ok := txData["transactions"].([]interface{}); ok {
+			for _, tx := range txArray {
+				if txMap, ok := tx.(map[string]interface{}); ok {
+					if txType, ok := txMap["type"].(string); ok {
+						txMap["type"] = normalizeTransactionType(txType)
+					}
+					// Analyzers read "date" and "description"; fill them from Liminal's createdAt/counterparty/note
+					if _, ok := txMap["date"]; !ok {
+						if date := transactionDateString(txMap); date != "" {
+							txMap["date"] = date
+						}
+					}
+					if _, ok := txMap["description"]; !ok {
+						if description := transactionDescription(txMap); description != "" {
+							txMap["description"] = description
+						}
+					}
+					transactions = append(transactions, txMap)
+				}
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// sendAliases are transaction type labels treated as outgoing "send" (SEND_TYPE_ALIASES)
+var sendAliases = map[string]bool{"send": true}
+
+// receiveAliases are transaction type labels treated as incoming "receive" (RECEIVE_TYPE_ALIASES)
+var receiveAliases = map[string]bool{"receive": true}
+
+// normalizeTransactionType maps a source's type label onto "send" or "receive"
+// Unknown labels pass through unchanged so analyzers simply skip them
+func normalizeTransactionType(txType string) string {
+	key := strings.ToLower(strings.TrimSpace(txType))
+	switch {
+	case sendAliases[key]:
+		return "send"
+	case receiveAliases[key]:
+		return "receive"
+	default:
+		return txType
+	}
+}
+
+// normalizeTransactionAmounts makes every amount a positive float64 with an explicit type
+// Some sources encode outgoing payments as negative amounts instead of type "send".
+// When the type is missing (or useSignConvention is set) the sign decides:
+// negative is a send, positive is a receive. Unparseable amounts are left as they
+// are for parseTransactions to report. Input maps are not modified.
+func normalizeTransactionAmounts(transactions []map[string]interface{}, useSignConvention bool) []map[string]interface{} {
+	normalized := make([]map[string]interface{}, 0, len(transactions))
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		txType = normalizeTransactionType(txType)
+
+		clean := make(map[string]interface{}, len(tx))
+		for key, value := range tx {
+			clean[key] = value
+		}
+		if amount, err := parseAmount(tx["amount"]); err == nil {
+			txType = signedTransactionType(txType, amount, useSignConvention)
+			clean["amount"] = math.Abs(amount)
+		}
+		clean["type"] = txType
+		normalized = append(normalized, clean)
+	}
+	return normalized
+}
+
+// signedTransactionType infers send/receive from the amount's sign when the type is
+// missing, or always when useSignConvention is set
+func signedTransactionType(txType string, amount float64, useSignConvention bool) string {
+	ambiguous := txType == ""
+	if useSignConvention {
+		ambiguous = ambiguous || txType == "send" || txType == "receive"
+	}
+	switch {
+	case !ambiguous || amount == 0:
+		return txType
+	case amount < 0:
+		return "send"
+	default:
+		return "receive"
+	}
+}
+
+// ============================================================================
+// TRANSACTION PARSING
+// ============================================================================
+// Liminal and the mock generators hand back loosely typed maps. Parsing them
+// once into Transaction keeps type assertions (and their silent zero values)
+// out of the analyzers.
+
+// Transaction is a single parsed wallet transaction
+type Transaction struct {
+	ID          string
+	Type        string // "send" or "receive"
+	Description string
+	Currency    string
+	Status      string
+	Amount      float64
+	Date        time.Time
+}
+
+// transactionDateLayouts are the date formats accepted by parseTransactions, most common first
+var transactionDateLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-1-2", // some sources don't zero-pad month and day
+}
+
+// skippedTransactionStatuses never moved money, so they're left out of analysis
+var skippedTransactionStatuses = map[string]bool{
+	"failed":    true,
+	"cancelled": true,
+	"canceled":  true,
+	"rejected":  true,
+	"reversed":  true,
+}
+
+// parseTransactions converts raw transaction maps into Transactions
+// Amounts may be numbers or numeric strings and are stored as absolute values, with a
+// missing type inferred from the sign; failed or cancelled transactions are dropped,
+// as are zero amounts, which moved no money and would only inflate counts and velocity.
+// Transactions that can't be parsed, including NaN or infinite amounts, are skipped
+// and reported in the returned errors.
+func parseTransactions(raw []map[string]interface{}) ([]Transaction, []error) {
+	transactions := make([]Transaction, 0, len(raw))
+	var errs []error
+	for i, tx := range raw {
+		id, _ := tx["id"].(string)
+		if id == "" {
+			id = fmt.Sprintf("#%d", i)
+		}
+
+		status, _ := tx["status"].(string)
+		if skippedTransactionStatuses[strings.ToLower(status)] {
+			continue
+		}
+
+		amount, err := parseAmount(tx["amount"])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transaction %s: %w", id, err))
+			continue
+		}
+		if amount == 0 {
+			continue
+		}
+
+		dateStr := transactionDateString(tx)
+		date, err := parseTransactionDate(dateStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transaction %s: %w", id, err))
+			continue
+		}
+
+		txType, _ := tx["type"].(string)
+		txType = signedTransactionType(normalizeTransactionType(txType), amount, false)
+		description := transactionDescription(tx)
+		currency, _ := tx["currency"].(string)
+		transactions = append(transactions, Transaction{
+			ID:          id,
+			Type:        txType,
+			Description: description,
+			Currency:    currency,
+			Status:      status,
+			Amount:      math.Abs(amount),
+			Date:        date,
+		})
+	}
+	return transactions, errs
+}
+
+// unknownMerchant labels transactions with no description or counterparty (UNKNOWN_MERCHANT)
+var unknownMerchant = "Unknown merchant"
+
+// merchantName returns the label to group a raw transaction under
+// Falls back from description to counterparty to unknownMerchant
+func merchantName(tx map[string]interface{}) string {
+	if description := transactionDescription(tx); description != "" {
+		return description
+	}
+	for _, field := range []string{"recipient", "sender"} {
+		if value, ok := tx[field].(string); ok && strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return unknownMerchant
+}
+
+// transactionDateString returns a raw transaction's timestamp
+// Liminal sends "createdAt"; mock and older payloads use "date" or "created_at"
+func transactionDateString(tx map[string]interface{}) string {
+	for _, field := range []string{"date", "createdAt", "created_at"} {
+		if value, ok := tx[field].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// transactionDescription returns a raw transaction's description
+// Liminal has no description field, so it falls back to counterparty, then note
+func transactionDescription(tx map[string]interface{}) string {
+	for _, field := range []string{"description", "counterparty", "note"} {
+		if value, ok := tx[field].(string); ok && strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseAmount reads a numeric or numeric-string amount
+// NaN and ±Inf (which ParseFloat accepts as "NaN", "Inf") are rejected so they can't poison totals
+func parseAmount(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("non-finite amount %v", n)
+		}
+		return n, nil
+	case string:
+		amount, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", n)
+		}
+		if math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return 0, fmt.Errorf("non-finite amount %q", n)
+		}
+		return amount, nil
+	case nil:
+		return 0, fmt.Errorf("missing amount")
+	default:
+		return 0, fmt.Errorf("invalid amount %v", v)
+	}
+}
+
+// parseTransactionDate tries each accepted layout in turn
+func parseTransactionDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	for _, layout := range transactionDateLayouts {
+		if date, err := time.Parse(layout, s); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// mockVaultAPY is the savings rate used when tools run in mock mode
+const mockVaultAPY = 4.5
+
+// vaultAPYIsFraction is set when get_vault_rates reports APY as a fraction (0.045)
+// rather than a percentage (4.5) (VAULT_APY_UNIT=fraction)
+var vaultAPYIsFraction = false
+
+// fetchVaultAPY returns the best available vault APY as a percentage (e.g. 4.5)
+// The unit comes from configuration, never from the value: 0.5 is a real 0.5% APY
+func fetchVaultAPY(ctx context.Context, liminalExecutor core.ToolExecutor, toolParams *core.ToolParams) (float64, error) {
+	resp, err := executeReadWithRetry(ctx, liminalExecutor, &core.ExecuteRequest{
+		UserID:    toolParams.UserID,
+		Tool:      "get_vault_rates",
+		Input:     json.RawMessage(`{}`),
+		RequestID: toolParams.RequestID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch vault rates: %w", err)
+	}
+	if !resp.Success {
+		return 0, fmt.Errorf("vault rate fetch failed: %s", resp.Error)
+	}
+
+	var rates executor.GetVaultRatesResponse
+	if err := json.Unmarshal(resp.Data, &rates); err != nil {
+		return 0, fmt.Errorf("failed to parse vault rates: %w", err)
+	}
+
+	best := 0.0
+	for _, vault := range rates.Vaults {
+		apy, err := parseAmount(vault.APY)
+		if err != nil {
+			log.Printf("⚠️  Skipping %s vault rate: %v", vault.Currency, err)
+			continue
+		}
+		best = math.Max(best, apy)
+	}
+
+	if best == 0 {
+		return 0, fmt.Errorf("no APY found in vault rates")
+	}
+	if vaultAPYIsFraction {
+		best *= 100
+	}
+	return best, nil
+}
+
+// toFloat coerces a JSON number or numeric string to float64, returning 0 otherwise
+// NaN and ±Inf also come back as 0
+func toFloat(v interface{}) float64 {
+	f, err := parseAmount(v)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// futureValueOfMonthly projects regular monthly contributions compounded monthly at apy%
+func futureValueOfMonthly(monthly, apy float64, years int) float64 {
+	months := float64(years * 12)
+	rate := apy / 100 / 12
+	if rate == 0 {
+		return monthly * months
+	}
+	return monthly * (math.Pow(1+rate, months) - 1) / rate
+}
+
+// ============================================================================
+// CUSTOM TOOL: HABIT COST
+// ============================================================================
+
+// habitOccurrencesPerYear maps a habit frequency to how often it happens in a year
+var habitOccurrencesPerYear = map[string]float64{
+	"daily":    365,
+	"weekdays": 260,
+	"weekly":   52,
+	"biweekly": 26,
+	"monthly":  12,
+}
+
+// createHabitCostTool builds a tool that projects the true cost of a recurring habit
+// Shows weekly/monthly/annual cost and what the money could grow to in savings
+func createHabitCostTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("habit_cost").
+		Description("Calculate what a recurring habit (e.g. a daily $6 coffee) really costs per week, month, and year, and what that money would grow to in 1, 5, and 10 years if saved at the current vault APY.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"per_occurrence_amount": tools.NumberProperty("Cost each time the habit happens (e.g. 6.00)"),
+			"frequency":             tools.StringEnumProperty("How often the habit happens (default: daily)", "daily", "weekdays", "weekly", "biweekly", "monthly"),
+			"use_mock":              tools.BoolProperty("Use a mock APY instead of live vault rates (default: true)"),
+		}, "per_occurrence_amount")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				PerOccurrenceAmount float64 `json:"per_occurrence_amount"`
+				Frequency           string  `json:"frequency"`
+				UseMock             bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+
+			if params.PerOccurrenceAmount <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "per_occurrence_amount must be greater than 0",
+				}, nil
+			}
+			if params.Frequency == "" {
+				params.Frequency = "daily"
+			}
+			perYear, ok := habitOccurrencesPerYear[params.Frequency]
+			if !ok {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("unsupported frequency %q", params.Frequency),
+				}, nil
+			}
+
+			// Get the savings rate (mock or real)
+			apy := mockVaultAPY
+			if !params.UseMock {
+				liveAPY, err := fetchVaultAPY(ctx, liminalExecutor, toolParams)
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				apy = liveAPY
+			}
+
+			annual := params.PerOccurrenceAmount * perYear
+			monthly := annual / 12
+
+			growth := map[string]string{}
+			for _, years := range []int{1, 5, 10} {
+				growth[fmt.Sprintf("%d_years", years)] = fmt.Sprintf("%.2f", futureValueOfMonthly(monthly, apy, years))
+			}
+
+			result := map[string]interface{}{
+				"per_occurrence_amount": fmt.Sprintf("%.2f", params.PerOccurrenceAmount),
+				"frequency":             params.Frequency,
+				"weekly_cost":           fmt.Sprintf("%.2f", annual/52),
+				"monthly_cost":          fmt.Sprintf("%.2f", monthly),
+				"annual_cost":           fmt.Sprintf("%.2f", annual),
+				"apy_used":              apy,
+				"if_saved_instead":      growth,
+				"insight": fmt.Sprintf("This %s habit costs $%.2f a year. Saved at %.2f%% APY instead, it would grow to $%s in 10 years.",
+					params.Frequency, annual, apy, growth["10_years"]),
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": time.Now().Format(time.RFC3339),
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: MERCHANT COMPARISON
+// ============================================================================
+
+// createMerchantComparisonTool builds a tool that compares merchants within a category
+// Surfaces the cheapest and priciest places (and weekdays) to spend in that category
+func createMerchantComparisonTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("compare_merchants").
+		Description("Within a spending category (e.g. 'Food & Dining'), compare the average ticket at each merchant and on each day of the week. Returns the cheapest and most expensive merchant and day so the agent can suggest substitutions. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"category": tools.StringProperty("Spending category to compare, e.g. 'Food & Dining'"),
+			"days":     tools.IntegerProperty("Number of days to analyze (default: 90)"),
+			"timezone": timezoneProperty(),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "category")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Category string `json:"category"`
+				Days     int    `json:"days"`
+				Timezone string `json:"timezone"`
+				UseMock  bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.Category == "" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "category is required",
+				}, nil
+			}
+			if params.Days == 0 {
+				params.Days = 90
+			}
+			loc, tzWarning := resolveTimezone(params.Timezone)
+
+			cutoffDate := time.Now().AddDate(0, 0, -params.Days)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for merchant comparison", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			result := map[string]interface{}{
+				"period_days":                  params.Days,
+				"timezone":                     loc.String(),
+				"category_merchant_comparison": compareCategoryMerchants(transactions, params.Category, loc),
+				"data_source":                  map[string]bool{"is_mock": params.UseMock},
+				"generated_at":                 time.Now().Format(time.RFC3339),
+			}
+			if tzWarning != "" {
+				result["timezone_warning"] = tzWarning
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// compareCategoryMerchants averages ticket size per merchant and weekday within one category
+// Weekdays are taken in loc so a late-evening purchase lands on the user's day
+func compareCategoryMerchants(transactions []map[string]interface{}, category string, loc *time.Location) map[string]interface{} {
+	type ticketStats struct {
+		name  string
+		total float64
+		count int
+	}
+	merchants := make(map[string]*ticketStats)
+	weekdays := make(map[string]*ticketStats)
+
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		if txType != "send" {
+			continue
+		}
+		description, _ := tx["description"].(string)
+		if !strings.EqualFold(categorizeTransaction(description), category) {
+			continue
+		}
+		amount, _ := tx["amount"].(float64)
+
+		merchant := merchantName(tx)
+		if merchants[merchant] == nil {
+			merchants[merchant] = &ticketStats{name: merchant}
+		}
+		merchants[merchant].total += amount
+		merchants[merchant].count++
+
+		if dateStr, ok := tx["date"].(string); ok {
+			if txDate, err := time.Parse(time.RFC3339, dateStr); err == nil {
+				day := txDate.In(loc).Weekday().String()
+				if weekdays[day] == nil {
+					weekdays[day] = &ticketStats{name: day}
+				}
+				weekdays[day].total += amount
+				weekdays[day].count++
+			}
+		}
+	}
+
+	if len(merchants) == 0 {
+		return map[string]interface{}{
+			"category": category,
+			"summary":  fmt.Sprintf("No spending found in %s for this period", category),
+		}
+	}
+
+	// rank orders stats by average ticket, cheapest first
+	rank := func(stats map[string]*ticketStats) []map[string]interface{} {
+		list := make([]*ticketStats, 0, len(stats))
+		for _, st := range stats {
+			list = append(list, st)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].total/float64(list[i].count) < list[j].total/float64(list[j].count)
+		})
+		ranked := make([]map[string]interface{}, 0, len(list))
+		for _, st := range list {
+			ranked = append(ranked, map[string]interface{}{
+				"name":           st.name,
+				"average_ticket": fmt.Sprintf("%.2f", st.total/float64(st.count)),
+				"count":          st.count,
+				"total":          fmt.Sprintf("%.2f", st.total),
+			})
+		}
+		return ranked
+	}
+
+	merchantRanking := rank(merchants)
+	dayRanking := rank(weekdays)
+	cheapest := merchantRanking[0]
+	priciest := merchantRanking[len(merchantRanking)-1]
+
+	comparison := map[string]interface{}{
+		"category":          category,
+		"merchants":         merchantRanking,
+		"cheapest_merchant": cheapest,
+		"priciest_merchant": priciest,
+		"by_weekday":        dayRanking,
+	}
+	if len(dayRanking) > 0 {
+		comparison["cheapest_day"] = dayRanking[0]["name"]
+		comparison["priciest_day"] = dayRanking[len(dayRanking)-1]["name"]
+	}
+	if len(merchantRanking) > 1 {
+		comparison["insight"] = fmt.Sprintf("Your average %s ticket is $%s at %s but $%s at %s.",
+			category, priciest["average_ticket"], priciest["name"], cheapest["average_ticket"], cheapest["name"])
+	}
+	return comparison
+}
+
+// ============================================================================
+// CASH FLOW HELPERS
+// ============================================================================
+
+// daysPerMonth is the average month length used to scale daily rates to months
+const daysPerMonth = 30.44
+
+// cashFlowSummary holds monthly-normalized totals for a transaction window
+type cashFlowSummary struct {
+	MonthlySpend          float64
+	MonthlyIncome         float64
+	MonthlyEssentialSpend float64
+}
+
+// summarizeCashFlow normalizes sends and receives over a window of days to monthly figures
+func summarizeCashFlow(transactions []map[string]interface{}, days int) cashFlowSummary {
+	return summarizeCashFlowWith(transactions, days, essentialCategories)
+}
+
+// summarizeCashFlowWith is summarizeCashFlow with a caller-chosen set of essential categories
+func summarizeCashFlowWith(transactions []map[string]interface{}, days int, essentials essentialSet) cashFlowSummary {
+	var spent, received, essential, refunds float64
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		amount, _ := tx["amount"].(float64)
+		switch txType {
+		case "send":
+			spent += amount
+			description, _ := tx["description"].(string)
+			if essentials.has(categorizeTransaction(description)) {
+				essential += amount
+			}
+		case "receive":
+			// A refund undoes a purchase rather than adding income
+			description, _ := tx["description"].(string)
+			if isRefund(description) {
+				refunds += amount
+				continue
+			}
+			received += amount
+		}
+	}
+	spent = math.Max(spent-refunds, 0)
+
+	if days <= 0 {
+		return cashFlowSummary{}
+	}
+	scale := daysPerMonth / float64(days)
+	return cashFlowSummary{
+		MonthlySpend:          spent * scale,
+		MonthlyIncome:         received * scale,
+		MonthlyEssentialSpend: essential * scale,
+	}
+}
+
+// savingsDepositPatterns are description keywords that mark a send as money moved to the user's own savings
+var savingsDepositPatterns = []string{"savings deposit", "deposit to savings", "transfer to savings", "vault deposit"}
+
+// isSavingsDeposit reports whether a send description looks like a deposit to savings rather than spending
+func isSavingsDeposit(description string) bool {
+	lower := strings.ToLower(description)
+	for _, pattern := range savingsDepositPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// refundPatterns are description keywords that mark a receive as money coming back
+var refundPatterns = []string{"refund", "reversal", "chargeback", "returned"}
+
+// isRefund reports whether a receive description looks like a refund
+func isRefund(description string) bool {
+	lower := strings.ToLower(description)
+	for _, pattern := range refundPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// refundSource extracts who paid the refund, e.g. "Refund from Amazon" -> "Amazon"
+func refundSource(description string) string {
+	if i := strings.Index(strings.ToLower(description), " from "); i >= 0 {
+		if source := strings.TrimSpace(description[i+len(" from "):]); source != "" {
+			return source
+		}
+	}
+	if strings.TrimSpace(description) == "" {
+		return unknownMerchant
+	}
+	return description
+}
+
+// summarizeRefunds builds the refunds block with the top 3 sources by amount
+func summarizeRefunds(total float64, count int, sources map[string]float64) map[string]interface{} {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return sources[names[i]] > sources[names[j]] })
+
+	top := []map[string]interface{}{}
+	for i := 0; i < len(names) && i < 3; i++ {
+		top = append(top, map[string]interface{}{
+			"source": names[i],
+			"amount": fmt.Sprintf("%.2f", sources[names[i]]),
+		})
+	}
+	return map[string]interface{}{
+		"total":       fmt.Sprintf("%.2f", total),
+		"count":       count,
+		"top_sources": top,
+	}
+}
+
+// ============================================================================
+// RECURRING INCOME DETECTION
+// ============================================================================
+
+// detectRecurringIncome finds regular inflows such as payroll
+// Groups receives by source only, since paychecks often vary slightly in amount
+func detectRecurringIncome(transactions []map[string]interface{}, cutoffDate time.Time) []map[string]interface{} {
+	patterns := detectRecurring(transactions, RecurringOpts{
+		Type:   "receive",
+		Cutoff: cutoffDate,
+	})
+
+	income := []map[string]interface{}{}
+	for _, pattern := range patterns {
+		income = append(income, map[string]interface{}{
+			"source":             pattern.Name,
+			"average_amount":     pattern.Amount,
+			"frequency":          pattern.Frequency,
+			"occurrences":        pattern.Occurrences(),
+			"last_occurrence":    pattern.LastOccurrence().Format("2006-01-02"),
+			"estimated_next":     pattern.EstimatedNext,
+			"monthly_equivalent": math.Round(monthlyEquivalent(pattern.Amount, pattern.Frequency)*100) / 100,
+		})
+	}
+
+	sort.Slice(income, func(i, j int) bool {
+		return income[i]["monthly_equivalent"].(float64) > income[j]["monthly_equivalent"].(float64)
+	})
+	return income
+}
+
+// recurringMonthlyIncome sums the monthly equivalent of detected recurring income
+func recurringMonthlyIncome(income []map[string]interface{}) float64 {
+	var total float64
+	for _, inc := range income {
+		monthly, _ := inc["monthly_equivalent"].(float64)
+		total += monthly
+	}
+	return total
+}
+
+// ============================================================================
+// CUSTOM TOOL: INCOME CHANGE SIMULATOR
+// ============================================================================
+
+// createIncomeChangeTool builds a "what if I got a raise?" simulator
+// Applies a change to recurring income while holding spending constant
+func createIncomeChangeTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("simulate_income_change").
+		Description("Simulate a raise or pay cut. Applies a percentage or absolute monthly change to the user's recurring income, holds spending constant, and reports the new monthly net, savings rate, and months to reach an emergency fund. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"change_percent":        tools.NumberProperty("Percentage change to income, e.g. 10 for a 10% raise or -5 for a 5% cut"),
+			"change_amount":         tools.NumberProperty("Absolute monthly change to income, e.g. 500 or -200 (used if change_percent is not set)"),
+			"days":                  tools.IntegerProperty("Number of days of history to base the simulation on (default: 90)"),
+			"current_savings":       tools.NumberProperty("Amount already saved toward the emergency fund (default: 0)"),
+			"emergency_fund_months": tools.IntegerProperty("Months of essential spending the emergency fund should cover (default: 3)"),
+			"essential_categories":  essentialCategoriesProperty(),
+			"use_mock":              tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				ChangePercent       float64  `json:"change_percent"`
+				ChangeAmount        float64  `json:"change_amount"`
+				Days                int      `json:"days"`
+				CurrentSavings      float64  `json:"current_savings"`
+				EmergencyFundMonths int      `json:"emergency_fund_months"`
+				EssentialCategories []string `json:"essential_categories"`
+				UseMock             bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.ChangePercent == 0 && params.ChangeAmount == 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "provide either change_percent or change_amount",
+				}, nil
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			if params.EmergencyFundMonths == 0 {
+				params.EmergencyFundMonths = 3
+			}
+
+			// summarizeCashFlow divides by Days, so the fetch must cover exactly that window
+			cutoffDate := time.Now().AddDate(0, 0, -params.Days)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for income simulation", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			cashFlow := summarizeCashFlowWith(transactions, params.Days, resolveEssentials(params.EssentialCategories))
+			recurring := detectRecurringIncome(transactions, cutoffDate)
+
+			// Prefer detected recurring income; fall back to the average of all inflows
+			currentIncome := recurringMonthlyIncome(recurring)
+			incomeSource := "recurring"
+			if currentIncome == 0 {
+				currentIncome = cashFlow.MonthlyIncome
+				incomeSource = "average_inflows"
+			}
+
+			change := params.ChangeAmount
+			if params.ChangePercent != 0 {
+				change = currentIncome * params.ChangePercent / 100
+			}
+			newIncome := currentIncome + change
+
+			currentNet := currentIncome - cashFlow.MonthlySpend
+			newNet := newIncome - cashFlow.MonthlySpend
+
+			savingsRate := func(income, net float64) float64 {
+				if income <= 0 {
+					return 0
+				}
+				return math.Round(net/income*1000) / 10
+			}
+
+			fundTarget := cashFlow.MonthlyEssentialSpend * float64(params.EmergencyFundMonths)
+			monthsToFund := func(net float64) interface{} {
+				remaining := fundTarget - params.CurrentSavings
+				if remaining <= 0 {
+					return 0
+				}
+				if net <= 0 {
+					return "never at this rate"
+				}
+				return math.Ceil(remaining / net)
+			}
+
+			result := map[string]interface{}{
+				"income_source":    incomeSource,
+				"recurring_income": recurring,
+				"monthly_spending": fmt.Sprintf("%.2f", cashFlow.MonthlySpend),
+				"income_change":    fmt.Sprintf("%.2f", change),
+				"current": map[string]interface{}{
+					"monthly_income":           fmt.Sprintf("%.2f", currentIncome),
+					"monthly_net":              fmt.Sprintf("%.2f", currentNet),
+					"savings_rate_percent":     savingsRate(currentIncome, currentNet),
+					"months_to_emergency_fund": monthsToFund(currentNet),
+				},
+				"simulated": map[string]interface{}{
+					"monthly_income":           fmt.Sprintf("%.2f", newIncome),
+					"monthly_net":              fmt.Sprintf("%.2f", newNet),
+					"savings_rate_percent":     savingsRate(newIncome, newNet),
+					"months_to_emergency_fund": monthsToFund(newNet),
+				},
+				"emergency_fund_target": fmt.Sprintf("%.2f", fundTarget),
+				"data_source":           map[string]bool{"is_mock": params.UseMock},
+				"generated_at":          time.Now().Format(time.RFC3339),
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: UNCATEGORIZED SPENDING
+// ============================================================================
+
+// createUncategorizedSpendTool builds a tool that reports spending the categorizer couldn't classify
+// Helps users see which merchants need new categorization rules
+func createUncategorizedSpendTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("find_uncategorized").
+		Description(fmt.Sprintf("Find spending that couldn't be automatically categorized (it landed in '%s'). Returns the uncategorized total, its share of spending, the top uncategorized merchants, and the transactions themselves. Uses mock data by default for demo purposes.", fallbackCategory)).
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":     tools.IntegerProperty(fmt.Sprintf("Number of days to analyze (default: %d)", defaultSpendingDays)),
+			"limit":    tools.IntegerProperty("Maximum number of merchants to return (default: 10)"),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days    int  `json:"days"`
+				Limit   int  `json:"limit"`
+				UseMock bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days == 0 {
+				params.Days = defaultSpendingDays
+			}
+			if params.Limit == 0 {
+				params.Limit = 10
+			}
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for uncategorized scan", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit": 100,
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			result := findUncategorizedSpending(transactions, params.Limit)
+			result["period_days"] = params.Days
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = time.Now().Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// findUncategorizedSpending collects sends that match no category rule
+// Checks for rule matches rather than the fallback label, which is configurable
+func findUncategorizedSpending(transactions []map[string]interface{}, limit int) map[string]interface{} {
+	var totalSpent, uncategorizedTotal float64
+	merchantTotals := make(map[string]float64)
+	merchantCounts := make(map[string]int)
+	uncategorized := []map[string]interface{}{}
+
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		if txType != "send" {
+			continue
+		}
+		amount, _ := tx["amount"].(float64)
+		description, _ := tx["description"].(string)
+		totalSpent += amount
+
+		if len(scoreCategoriesWeighted(description, nil)) > 0 {
+			continue
+		}
+		uncategorizedTotal += amount
+		merchant := merchantName(tx)
+		merchantTotals[merchant] += amount
+		merchantCounts[merchant]++
+		uncategorized = append(uncategorized, tx)
+	}
+
+	merchants := make([]string, 0, len(merchantTotals))
+	for merchant := range merchantTotals {
+		merchants = append(merchants, merchant)
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		return merchantTotals[merchants[i]] > merchantTotals[merchants[j]]
+	})
+
+	topMerchants := []map[string]interface{}{}
+	for i := 0; i < len(merchants) && i < limit; i++ {
+		topMerchants = append(topMerchants, map[string]interface{}{
+			"merchant": merchants[i],
+			"amount":   fmt.Sprintf("%.2f", merchantTotals[merchants[i]]),
+			"count":    merchantCounts[merchants[i]],
+		})
+	}
+
+	share := 0.0
+	if totalSpent > 0 {
+		share = uncategorizedTotal / totalSpent * 100
+	}
+
+	return map[string]interface{}{
+		"uncategorized_total": fmt.Sprintf("%.2f", uncategorizedTotal),
+		"uncategorized_count": len(uncategorized),
+		"share_of_spending":   fmt.Sprintf("%.1f%%", share),
+		"top_merchants":       topMerchants,
+		"transactions":        uncategorized,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: MERCHANT HISTORY
+// ============================================================================
+
+// normalizeMerchant lowercases a merchant name and strips punctuation so
+// "AMAZON.COM*Order" and "Amazon.com" compare equal-ish
+func normalizeMerchant(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// createMerchantHistoryTool builds a tool that shows everything spent at one merchant
+// Cross-references the subscription detector to flag recurring charges
+func createMerchantHistoryTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("merchant_history").
+		Description("Show the user's full history with a specific merchant: a timeline of transactions, total spent, average/min/max amount, and whether it's a detected subscription. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"merchant":         tools.StringProperty("Merchant name or part of it, e.g. 'netflix'"),
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Number of months of history to search (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "merchant")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Merchant        string `json:"merchant"`
+				TimeframeMonths int    `json:"timeframe_months"`
+				UseMock         bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			query := normalizeMerchant(params.Merchant)
+			if query == "" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "merchant is required",
+				}, nil
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+
+			cutoffDate := time.Now().AddDate(0, -params.TimeframeMonths, 0)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{}),
+					generateMockTransactionsForAnalysis(params.TimeframeMonths*30, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for merchant history", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			// Filter to outgoing payments whose normalized name contains the query
+			matches := []map[string]interface{}{}
+			for _, tx := range transactions {
+				txType, _ := tx["type"].(string)
+				description, _ := tx["description"].(string)
+				if txType == "send" && strings.Contains(normalizeMerchant(description), query) {
+					matches = append(matches, tx)
+				}
+			}
+
+			result := summarizeMerchantHistory(matches)
+			result["merchant_query"] = params.Merchant
+
+			// Cross-reference the subscription detector
+			var subscription map[string]interface{}
+			for _, sub := range analyzeForSubscriptions(matches, cutoffDate, 0, math.MaxFloat64) {
+				subscription = sub
+				break
+			}
+			result["is_subscription"] = subscription != nil
+			if subscription != nil {
+				result["subscription"] = subscription
+			}
+
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = time.Now().Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// summarizeMerchantHistory builds a chronological timeline and amount stats for matched transactions
+func summarizeMerchantHistory(matches []map[string]interface{}) map[string]interface{} {
+	if len(matches) == 0 {
+		return map[string]interface{}{
+			"transaction_count": 0,
+			"summary":           "No transactions found for this merchant",
+		}
+	}
+
+	timeline := make([]map[string]interface{}, len(matches))
+	copy(timeline, matches)
+	sort.SliceStable(timeline, func(i, j int) bool {
+		di, _ := timeline[i]["date"].(string)
+		dj, _ := timeline[j]["date"].(string)
+		return di < dj
+	})
+
+	var total float64
+	minAmount, maxAmount := math.MaxFloat64, 0.0
+	merchantNames := make(map[string]bool)
+	for _, tx := range timeline {
+		amount, _ := tx["amount"].(float64)
+		total += amount
+		minAmount = math.Min(minAmount, amount)
+		maxAmount = math.Max(maxAmount, amount)
+		if description, ok := tx["description"].(string); ok {
+			merchantNames[description] = true
+		}
+	}
+
+	names := make([]string, 0, len(merchantNames))
+	for name := range merchantNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return map[string]interface{}{
+		"matched_merchants": names,
+		"transaction_count": len(timeline),
+		"total_spent":       fmt.Sprintf("%.2f", total),
+		"average_amount":    fmt.Sprintf("%.2f", total/float64(len(timeline))),
+		"min_amount":        fmt.Sprintf("%.2f", minAmount),
+		"max_amount":        fmt.Sprintf("%.2f", maxAmount),
+		"first_seen":        timeline[0]["date"],
+		"last_seen":         timeline[len(timeline)-1]["date"],
+		"timeline":          timeline,
+	}
+}
+
+// ============================================================================
+// PER-USER STORE
+// ============================================================================
+// In-memory state that persists across conversations for the lifetime of the
+// process. Swap for a database if you need it to survive restarts. Keyed on
+// ToolParams.UserID, which liminalAuthFunc derives from the user's JWT.
+
+// userData is everything we remember about a single user
+type userData struct {
+	SpendingTarget *spendingTarget
+	Goals          []storedGoal
+	PendingAlerts  []pendingAlert
+}
+
+// userStore is a concurrency-safe map of user ID to userData
+type userStore struct {
+	mu    sync.Mutex
+	users map[string]*userData
+}
+
+// users is the process-wide per-user store
+var users = &userStore{users: make(map[string]*userData)}
+
+// sharedStateWarning explains, for callers without a session, that what they save
+// is shared with everyone else who isn't logged in. Empty for real users.
+func sharedStateWarning(userID string) string {
+	if userID != anonymousUserID {
+		return ""
+	}
+	return "You're not logged in, so this is saved in a shared space that anyone else without a session can see and change. Log in to keep it private."
+}
+
+// get returns a copy of the user's data (zero value if unknown)
+func (s *userStore) get(userID string) userData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data, ok := s.users[userID]; ok {
+		return *data
+	}
+	return userData{}
+}
+
+// update applies fn to the user's data under the store lock
+func (s *userStore) update(userID string, fn func(data *userData)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.users[userID]
+	if !ok {
+		data = &userData{}
+		s.users[userID] = data
+	}
+	fn(data)
+}
+
+// reset forgets one user's data, or everyone's when userID is empty
+// Returns the IDs that were cleared
+func (s *userStore) reset(userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cleared := []string{}
+	for id := range s.users {
+		if userID == "" || id == userID {
+			cleared = append(cleared, id)
+			delete(s.users, id)
+		}
+	}
+	sort.Strings(cleared)
+	return cleared
+}
+
+// ============================================================================
+// QUIET HOURS
+// ============================================================================
+// Proactive checks (run on a schedule or at conversation start rather than
+// because the user asked) shouldn't ping anyone at 3am. During quiet hours
+// their alerts are queued in the per-user store and handed back by the next
+// check outside quiet hours. Configure with QUIET_HOURS_START/QUIET_HOURS_END
+// ("22:00", "07:00") and QUIET_HOURS_TIMEZONE.
+
+// quietHours is a daily window, which may wrap midnight, when proactive alerts are held
+// Start and End are offsets from local midnight; Start == End disables the window
+type quietHours struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// alertQuietHours is the configured window (disabled by default)
+var alertQuietHours = quietHours{Location: time.UTC}
+
+// contains reports whether t falls inside the window
+func (q quietHours) contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	t = t.In(q.Location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// parseQuietHours reads the QUIET_HOURS_* settings
+// Invalid values are logged and leave quiet hours disabled
+func parseQuietHours(start, end, timezone string) quietHours {
+	disabled := quietHours{Location: time.UTC}
+	if start == "" && end == "" {
+		return disabled
+	}
+	startOffset, err := parseClock(start)
+	if err != nil {
+		log.Printf("⚠️  Ignoring quiet hours: invalid QUIET_HOURS_START=%q", start)
+		return disabled
+	}
+	endOffset, err := parseClock(end)
+	if err != nil {
+		log.Printf("⚠️  Ignoring quiet hours: invalid QUIET_HOURS_END=%q", end)
+		return disabled
+	}
+	loc, tzWarning := resolveTimezone(timezone)
+	if tzWarning != "" {
+		log.Printf("⚠️  QUIET_HOURS_TIMEZONE: %s", tzWarning)
+	}
+	return quietHours{Start: startOffset, End: endOffset, Location: loc}
+}
+
+// parseClock reads "HH:MM" as an offset from midnight
+func parseClock(raw string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// pendingAlert is an alert held back during quiet hours
+type pendingAlert struct {
+	Tool     string `json:"tool"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	RaisedAt string `json:"raised_at"`
+}
+
+// maxPendingAlerts caps one user's quiet-hours queue; the oldest alerts are dropped first
+const maxPendingAlerts = 20
+
+// proactiveProperty is the shared schema for the proactive param
+func proactiveProperty() map[string]interface{} {
+	return tools.BoolProperty("Set when the check runs on a schedule or unprompted rather than because the user asked; alerts raised during quiet hours are then queued instead of returned (default: false)")
+}
+
+// holdForQuietHours queues a proactive alert raised during quiet hours
+// A repeat from the same tool replaces its earlier alert, so a check run every few
+// minutes overnight leaves only its latest result; the queue is capped at maxPendingAlerts.
+// Returns false, queuing nothing, when the alert should be delivered now
+func holdForQuietHours(userID string, proactive bool, alert pendingAlert, now time.Time) bool {
+	if !proactive || !alertQuietHours.contains(now) {
+		return false
+	}
+	users.update(userID, func(data *userData) {
+		queue := make([]pendingAlert, 0, len(data.PendingAlerts)+1)
+		for _, queued := range data.PendingAlerts {
+			if queued.Tool != alert.Tool {
+				queue = append(queue, queued)
+			}
+		}
+		queue = append(queue, alert)
+		if len(queue) > maxPendingAlerts {
+			queue = queue[len(queue)-maxPendingAlerts:]
+		}
+		data.PendingAlerts = queue
+	})
+	log.Printf("🌙 Queued %s alert until quiet hours end", alert.Tool)
+	return true
+}
+
+// flushPendingAlerts returns and clears the user's queued alerts once quiet hours are over
+// Returns nil while quiet hours are still in effect
+func flushPendingAlerts(userID string, now time.Time) []pendingAlert {
+	if alertQuietHours.contains(now) {
+		return nil
+	}
+	var pending []pendingAlert
+	users.update(userID, func(data *userData) {
+		pending, data.PendingAlerts = data.PendingAlerts, nil
+	})
+	return pending
+}
+
+// quietHoursResult is what a proactive check returns after queuing its alert
+func quietHoursResult(now time.Time) *core.ToolResult {
+	return &core.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"alert_queued": true,
+			"message":      "Quiet hours are in effect; the alert is queued and will be returned by the next check after they end.",
+			"generated_at": now.Format(time.RFC3339),
+		},
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOLS: SPENDING TARGET
+// ============================================================================
+
+// spendingTarget is an overall weekly or monthly spend limit
+// Monthly targets run from CycleStartDay, e.g. 15 for someone paid on the 15th
+type spendingTarget struct {
+	Period        string  `json:"period"`
+	Amount        float64 `json:"amount"`
+	CycleStartDay int     `json:"cycle_start_day,omitempty"`
+	SetAt         string  `json:"set_at"`
+}
+
+// cycleStartDayProperty is the shared schema for the cycle_start_day param
+func cycleStartDayProperty() map[string]interface{} {
+	return tools.IntegerProperty("Day of the month the user's budget cycle starts, e.g. their payday (1-31, default: 1 = calendar month). Days past a month's end use its last day")
+}
+
+// validCycleStartDay reports whether day is usable as a cycle start (0 means unset)
+func validCycleStartDay(day int) bool {
+	return day >= 0 && day <= 31
+}
+
+// createSetSpendingTargetTool builds a tool that saves an overall spending target for the user
+func createSetSpendingTargetTool() core.Tool {
+	return tools.New("set_spending_target").
+		Description("Set the user's overall weekly or monthly spending target. The target is remembered across conversations and can be checked with check_spending_target.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"period":          tools.StringEnumProperty("Target period", "weekly", "monthly"),
+			"amount":          tools.NumberProperty("Maximum total spend for the period"),
+			"cycle_start_day": cycleStartDayProperty(),
+		}, "period", "amount")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Period        string  `json:"period"`
+				Amount        float64 `json:"amount"`
+				CycleStartDay int     `json:"cycle_start_day"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.Period != "weekly" && params.Period != "monthly" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "period must be 'weekly' or 'monthly'",
+				}, nil
+			}
+			if params.Amount <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "amount must be greater than 0",
+				}, nil
+			}
+			if !validCycleStartDay(params.CycleStartDay) {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "cycle_start_day must be between 1 and 31",
+				}, nil
+			}
+
+			target := spendingTarget{
+				Period:        params.Period,
+				Amount:        params.Amount,
+				CycleStartDay: params.CycleStartDay,
+				SetAt:         time.Now().Format(time.RFC3339),
+			}
+			users.update(toolParams.UserID, func(data *userData) {
+				data.SpendingTarget = &target
+			})
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"target":  target,
+					"message": fmt.Sprintf("Saved a %s spending target of $%.2f", target.Period, target.Amount),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// createCheckSpendingTargetTool builds a tool that reports progress against the saved spending target
+func createCheckSpendingTargetTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("check_spending_target").
+		Description("Check progress against the user's saved weekly or monthly spending target. Returns spend so far, pace (on track / ahead / behind), and the projected end-of-period total. Monthly targets follow the user's pay cycle when cycle_start_day is set. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"cycle_start_day": cycleStartDayProperty(),
+			"timezone":        timezoneProperty(),
+			"proactive":       proactiveProperty(),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				CycleStartDay int    `json:"cycle_start_day"`
+				Timezone      string `json:"timezone"`
+				Proactive     bool   `json:"proactive"`
+				UseMock       bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if !validCycleStartDay(params.CycleStartDay) {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "cycle_start_day must be between 1 and 31",
+				}, nil
+			}
+
+			target := users.get(toolParams.UserID).SpendingTarget
+			if target == nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "no spending target set; use set_spending_target first",
+				}, nil
+			}
+
+			loc, tzWarning := resolveTimezone(params.Timezone)
+			now := time.Now().In(loc)
+			// The param overrides the cycle saved with the target
+			cycleStartDay := target.CycleStartDay
+			if params.CycleStartDay > 0 {
+				cycleStartDay = params.CycleStartDay
+			}
+			periodStart, periodDays := spendingPeriodBounds(now, target.Period, cycleStartDay)
+			daysElapsed := int(now.Sub(periodStart).Hours()/24) + 1
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(daysElapsed, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for spending target", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": periodStart.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			var spent float64
+			for _, tx := range transactions {
+				txType, _ := tx["type"].(string)
+				if txType != "send" {
+					continue
+				}
+				dateStr, _ := tx["date"].(string)
+				if txDate, err := time.Parse(time.RFC3339, dateStr); err == nil && txDate.Before(periodStart) {
+					continue
+				}
+				amount, _ := tx["amount"].(float64)
+				spent += amount
+			}
+
+			projected := spent / float64(daysElapsed) * float64(periodDays)
+			expectedSoFar := target.Amount * float64(daysElapsed) / float64(periodDays)
+
+			// "ahead" means spending slower than the target pace, "behind" means faster
+			pace := "on track"
+			switch {
+			case spent > expectedSoFar*1.1:
+				pace = "behind"
+			case spent < expectedSoFar*0.9:
+				pace = "ahead"
+			}
+
+			result := map[string]interface{}{
+				"target":            target,
+				"period_start":      periodStart.Format("2006-01-02"),
+				"period_end":        periodStart.AddDate(0, 0, periodDays-1).Format("2006-01-02"),
+				"days_elapsed":      daysElapsed,
+				"days_in_period":    periodDays,
+				"spent_so_far":      fmt.Sprintf("%.2f", spent),
+				"expected_so_far":   fmt.Sprintf("%.2f", expectedSoFar),
+				"remaining":         fmt.Sprintf("%.2f", target.Amount-spent),
+				"projected_total":   fmt.Sprintf("%.2f", projected),
+				"percent_used":      fmt.Sprintf("%.1f%%", spent/target.Amount*100),
+				"pace":              pace,
+				"projected_over_by": fmt.Sprintf("%.2f", math.Max(0, projected-target.Amount)),
+				"timezone":          loc.String(),
+				"data_source":       map[string]bool{"is_mock": params.UseMock},
+				"generated_at":      now.Format(time.RFC3339),
+			}
+			if tzWarning != "" {
+				result["timezone_warning"] = tzWarning
+			}
+			if pending := flushPendingAlerts(toolParams.UserID, now); len(pending) > 0 {
+				result["pending_alerts"] = pending
+			}
+			if pace == "behind" {
+				alert := pendingAlert{
+					Tool:     "check_spending_target",
+					Severity: "watch",
+					Message:  fmt.Sprintf("You've spent $%.2f of your $%.2f %s target and are on pace for $%.2f.", spent, target.Amount, target.Period, projected),
+					RaisedAt: now.Format(time.RFC3339),
+				}
+				if holdForQuietHours(toolParams.UserID, params.Proactive, alert, now) {
+					return quietHoursResult(now), nil
+				}
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// spendingPeriodBounds returns the start of the current week (Monday) or monthly
+// cycle and its length in days. Monthly cycles start on cycleStartDay; 0 or 1 is
+// the calendar month. Boundaries are midnight in now's location.
+func spendingPeriodBounds(now time.Time, period string, cycleStartDay int) (time.Time, int) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if period == "weekly" {
+		offset := (int(today.Weekday()) + 6) % 7 // days since Monday
+		return today.AddDate(0, 0, -offset), 7
+	}
+	start, end := payCycleBounds(today, cycleStartDay)
+	return start, int(math.Round(end.Sub(start).Hours() / 24))
+}
+
+// payCycleBounds returns the monthly cycle containing day: [start, end)
+// A cycle day past a month's end falls on its last day (31 -> Feb 28)
+func payCycleBounds(day time.Time, cycleStartDay int) (time.Time, time.Time) {
+	if cycleStartDay < 1 {
+		cycleStartDay = 1
+	}
+	start := cycleDate(day.Year(), day.Month(), cycleStartDay, day.Location())
+	if start.After(day) {
+		start = cycleDate(day.Year(), day.Month()-1, cycleStartDay, day.Location())
+	}
+	return start, cycleDate(start.Year(), start.Month()+1, cycleStartDay, day.Location())
+}
+
+// cycleDate is midnight on the given day of a month, clamped to the month's length
+func cycleDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// ============================================================================
+// CUSTOM TOOL: EXPLAIN CATEGORY
+// ============================================================================
+
+// createExplainCategoryTool builds a tool that explains why a description got its category
+// Shows the winning keywords and any categories that also matched but lost
+func createExplainCategoryTool() core.Tool {
+	return tools.New("explain_category").
+		Description("Explain how a transaction description was categorized: the matched category, the keyword(s) that triggered it, its score, and any other categories that also matched but narrowly lost.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"description":      tools.StringProperty("The transaction description to explain, e.g. 'Amazon Prime Video'"),
+			"category_weights": categoryWeightsProperty(),
+		}, "description")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Description     string           `json:"description"`
+				CategoryWeights []categoryWeight `json:"category_weights"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if strings.TrimSpace(params.Description) == "" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "description is required",
+				}, nil
+			}
+
+			matches := scoreCategoriesWeighted(params.Description, params.CategoryWeights)
+			if len(matches) == 0 {
+				return &core.ToolResult{
+					Success: true,
+					Data: map[string]interface{}{
+						"description":      params.Description,
+						"category":         fallbackCategory,
+						"matched_keywords": []string{},
+						"near_misses":      []map[string]interface{}{},
+						"explanation":      fmt.Sprintf("No category keywords matched, so it fell through to %s.", fallbackCategory),
+					},
+				}, nil
+			}
+
+			winner := matches[0]
+			nearMisses := []map[string]interface{}{}
+			for _, miss := range matches[1:] {
+				nearMisses = append(nearMisses, map[string]interface{}{
+					"category": miss.Category,
+					"score":    miss.Score,
+					"keywords": miss.Keywords,
+				})
+			}
+
+			explanation := fmt.Sprintf("Matched %s because the description contains %q.", winner.Category, strings.Join(winner.Keywords, `", "`))
+			if len(nearMisses) > 0 {
+				explanation += fmt.Sprintf(" It also matched %s but scored lower or lost the tie on rule priority.", matches[1].Category)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"description":      params.Description,
+					"category":         winner.Category,
+					"score":            winner.Score,
+					"matched_keywords": winner.Keywords,
+					"near_misses":      nearMisses,
+					"explanation":      explanation,
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: FIXED COST FLOOR
+// ============================================================================
+
+// createFixedCostFloorTool builds a tool that estimates the user's baseline monthly cost
+// Combines detected subscriptions with average essential-category spending
+func createFixedCostFloorTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("fixed_cost_floor").
+		Description("Estimate the user's baseline monthly cost before any discretionary spending: the monthly equivalent of all detected subscriptions plus average monthly spending in essential categories. Returns the floor and its breakdown. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"timeframe_months":     tools.IntegerProperty(fmt.Sprintf("Number of months of history to average over (default: 3, max: %d)", maxTimeframeMonths)),
+			"essential_categories": essentialCategoriesProperty(),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				TimeframeMonths     int      `json:"timeframe_months"`
+				EssentialCategories []string `json:"essential_categories"`
+				UseMock             bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = 3
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{}),
+					generateMockTransactionsForAnalysis(params.TimeframeMonths*30, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for fixed cost floor", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99)
+			subscriptionMonthly := calculateTotalMonthlyCost(subscriptions)
+
+			// Essential spending, excluding charges already counted as subscriptions
+			subscriptionMerchants := make(map[string]bool)
+			for _, sub := range subscriptions {
+				merchant, _ := sub["merchant"].(string)
+				subscriptionMerchants[merchant] = true
+			}
+			essentialNames := resolveEssentials(params.EssentialCategories)
+			essentialByCategory := make(map[string]float64)
+			for _, tx := range transactions {
+				txType, _ := tx["type"].(string)
+				description, _ := tx["description"].(string)
+				if txType != "send" || subscriptionMerchants[description] {
+					continue
+				}
+				if category := categorizeTransaction(description); essentialNames.has(category) {
+					amount, _ := tx["amount"].(float64)
+					essentialByCategory[category] += amount
+				}
+			}
+
+			var essentialMonthly float64
+			essentials := map[string]float64{}
+			for category, total := range essentialByCategory {
+				monthly := total / float64(params.TimeframeMonths)
+				essentials[category] = math.Round(monthly*100) / 100
+				essentialMonthly += monthly
+			}
+			essentialMonthly = math.Round(essentialMonthly*100) / 100
+			floor := subscriptionMonthly + essentialMonthly
+
+			result := map[string]interface{}{
+				"fixed_cost_floor": fmt.Sprintf("%.2f", floor),
+				"breakdown": map[string]interface{}{
+					"subscriptions_monthly":  fmt.Sprintf("%.2f", subscriptionMonthly),
+					"essentials_monthly":     fmt.Sprintf("%.2f", essentialMonthly),
+					"essentials_by_category": essentials,
+					"subscription_count":     len(subscriptions),
+				},
+				"summary":      fmt.Sprintf("Your baseline monthly cost is about $%.2f before any discretionary spending.", floor),
+				"period":       fmt.Sprintf("%d months", params.TimeframeMonths),
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CASH FLOW FORECASTING
+// ============================================================================
+
+// mockWalletBalance is the wallet balance used when tools run in mock mode
+const mockWalletBalance = 2500.00
+
+// mockSavingsBalance is the savings balance used when tools run in mock mode
+const mockSavingsBalance = 1200.00
+
+// cashEvent is a predicted inflow (positive) or outflow (negative) on a date
+type cashEvent struct {
+	Date        time.Time
+	Amount      float64
+	Description string
+	Kind        string // "bill" or "income"
+}
+
+// fetchWalletBalance returns the wallet balance for a currency from get_balance
+// Walks the response for an object whose currency matches and reads its amount
+func fetchWalletBalance(ctx context.Context, liminalExecutor core.ToolExecutor, toolParams *core.ToolParams, currency string) (float64, error) {
+	input, _ := json.Marshal(map[string]string{"currency": currency})
+	resp, err := executeReadWithRetry(ctx, liminalExecutor, &core.ExecuteRequest{
+		UserID:    toolParams.UserID,
+		Tool:      "get_balance",
+		Input:     input,
+		RequestID: toolParams.RequestID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch balance: %w", err)
+	}
+	if !resp.Success {
+		return 0, fmt.Errorf("balance fetch failed: %s", resp.Error)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return 0, fmt.Errorf("failed to parse balance: %w", err)
+	}
+
+	total, found := sumCurrencyAmounts(data, currency)
+	if !found {
+		return 0, fmt.Errorf("no %s balance found", currency)
+	}
+	return total, nil
+}
+
+// fetchSavingsBalance returns the total savings balance in the given currency across all positions
+func fetchSavingsBalance(ctx context.Context, liminalExecutor core.ToolExecutor, toolParams *core.ToolParams, currency string) (float64, error) {
+	resp, err := executeReadWithRetry(ctx, liminalExecutor, &core.ExecuteRequest{
+		UserID:    toolParams.UserID,
+		Tool:      "get_savings_balance",
+		Input:     json.RawMessage(`{}`),
+		RequestID: toolParams.RequestID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch savings balance: %w", err)
+	}
+	if !resp.Success {
+		return 0, fmt.Errorf("savings balance fetch failed: %s", resp.Error)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return 0, fmt.Errorf("failed to parse savings balance: %w", err)
+	}
+
+	// No positions in this currency just means nothing saved yet
+	total, _ := sumCurrencyAmounts(data, currency)
+	return total, nil
+}
+
+// sumCurrencyAmounts walks a balance response and adds up the amount of every
+// object tagged with the currency, so it tolerates shape changes
+func sumCurrencyAmounts(data interface{}, currency string) (float64, bool) {
+	total, found := 0.0, false
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			if cur, ok := node["currency"].(string); ok && strings.EqualFold(cur, currency) {
+				for _, key := range []string{"amount", "balance", "available"} {
+					if value, ok := node[key]; ok {
+						total += toFloat(value)
+						found = true
+						return
+					}
+				}
+			}
+			for _, child := range node {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range node {
+				walk(child)
+			}
+		}
+	}
+	walk(data)
+
+	return total, found
+}
+
+// predictCashEvents projects detected bills and recurring income forward over the horizon
+// Each recurring pattern is stepped forward by its frequency from its next estimated date
+func predictCashEvents(transactions []map[string]interface{}, cutoffDate time.Time, from time.Time, horizonDays int) []cashEvent {
+	end := from.AddDate(0, 0, horizonDays)
+	events := []cashEvent{}
+
+	addSeries := func(nextStr, frequency string, amount float64, description, kind string) {
+		next, err := time.Parse("2006-01-02", nextStr)
+		if err != nil {
+			return
+		}
+		for i := 0; i < 400 && !next.After(end); i++ {
+			if !next.Before(from.Truncate(24 * time.Hour)) {
+				events = append(events, cashEvent{Date: next, Amount: amount, Description: description, Kind: kind})
+			}
+			stepped := estimateNextPayment(next, frequency)
+			if next, err = time.Parse("2006-01-02", stepped); err != nil {
+				return
+			}
+		}
+	}
+
+	for _, sub := range analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99) {
+		amount, _ := sub["amount"].(float64)
+		merchant, _ := sub["merchant"].(string)
+		next, _ := sub["estimated_next"].(string)
+		frequency, _ := sub["frequency"].(string)
+		addSeries(next, frequency, -amount, merchant, "bill")
+	}
+	for _, inc := range detectRecurringIncome(transactions, cutoffDate) {
+		amount, _ := inc["average_amount"].(float64)
+		source, _ := inc["source"].(string)
+		next, _ := inc["estimated_next"].(string)
+		frequency, _ := inc["frequency"].(string)
+		addSeries(next, frequency, amount, source, "income")
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events
+}
+
+// averageDailyDiscretionary estimates daily spending that isn't a detected bill
+func averageDailyDiscretionary(transactions []map[string]interface{}, cutoffDate time.Time, days int) float64 {
+	if days <= 0 {
+		return 0
+	}
+	billMerchants := make(map[string]bool)
+	for _, sub := range analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99) {
+		merchant, _ := sub["merchant"].(string)
+		billMerchants[merchant] = true
+	}
+	var total float64
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		description, _ := tx["description"].(string)
+		if txType == "send" && !billMerchants[description] {
+			amount, _ := tx["amount"].(float64)
+			total += amount
+		}
+	}
+	return total / float64(days)
+}
+
+// projectDailyBalances returns the projected end-of-day balance for each of the next days
+// Index 0 is today. Events land on their date; daily spending is subtracted every day.
+func projectDailyBalances(start time.Time, balance float64, events []cashEvent, dailySpend float64, days int) []float64 {
+	today := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	balances := make([]float64, days)
+	eventIdx := 0
+	for day := 0; day < days; day++ {
+		date := today.AddDate(0, 0, day)
+		for eventIdx < len(events) && !events[eventIdx].Date.After(date) {
+			balance += events[eventIdx].Amount
+			eventIdx++
+		}
+		balance -= dailySpend
+		balances[day] = balance
+	}
+	return balances
+}
+
+// ============================================================================
+// CUSTOM TOOL: PURCHASE TIMING
+// ============================================================================
+
+// createPurchaseTimingTool builds a tool that recommends when to make a large purchase
+// Uses predicted bills and recurring income to find a date with enough headroom
+func createPurchaseTimingTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("when_to_buy").
+		Description("Recommend the best date in the next 30 days to make a large purchase. Projects the wallet balance from predicted bills, recurring income, and typical daily spending, then picks the earliest date after which the balance stays above the purchase amount plus a cushion. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"purchase_amount": tools.NumberProperty("Cost of the planned purchase"),
+			"cushion":         tools.NumberProperty("Minimum balance to keep after the purchase (default: 200)"),
+			"current_balance": tools.NumberProperty("Optional: current wallet balance (fetched from get_balance if omitted)"),
+			"currency":        tools.StringProperty("Currency of the balance (default: USD)"),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "purchase_amount")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				PurchaseAmount float64  `json:"purchase_amount"`
+				Cushion        *float64 `json:"cushion"`
+				CurrentBalance *float64 `json:"current_balance"`
+				Currency       string   `json:"currency"`
+				UseMock        bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.PurchaseAmount <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "purchase_amount must be greater than 0",
+				}, nil
+			}
+			cushion := 200.0
+			if params.Cushion != nil {
+				cushion = *params.Cushion
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			const historyDays, horizonDays = 90, 30
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -historyDays)
+
+			var transactions []map[string]interface{}
+			balance := mockWalletBalance
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(3, mockOptions{}),
+					generateMockTransactionsForAnalysis(historyDays, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for purchase timing", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if params.CurrentBalance == nil {
+					if balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency); err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+			}
+			if params.CurrentBalance != nil {
+				balance = *params.CurrentBalance
+			}
+
+			events := predictCashEvents(transactions, cutoffDate, now, horizonDays)
+			dailySpend := averageDailyDiscretionary(transactions, cutoffDate, historyDays)
+			balances := projectDailyBalances(now, balance, events, dailySpend, horizonDays)
+
+			// Earliest day after which buying still leaves the cushion for the rest of the horizon
+			needed := params.PurchaseAmount + cushion
+			recommendedDay := -1
+			for day := len(balances) - 1; day >= 0; day-- {
+				if balances[day] < needed {
+					break
+				}
+				recommendedDay = day
+			}
+
+			upcoming := []map[string]interface{}{}
+			for _, event := range events {
+				upcoming = append(upcoming, map[string]interface{}{
+					"date":        event.Date.Format("2006-01-02"),
+					"amount":      fmt.Sprintf("%.2f", event.Amount),
+					"description": event.Description,
+					"kind":        event.Kind,
+				})
+			}
+
+			result := map[string]interface{}{
+				"purchase_amount":      fmt.Sprintf("%.2f", params.PurchaseAmount),
+				"cushion":              fmt.Sprintf("%.2f", cushion),
+				"current_balance":      fmt.Sprintf("%.2f", balance),
+				"avg_daily_spend":      fmt.Sprintf("%.2f", dailySpend),
+				"upcoming_cash_events": upcoming,
+				"data_source":          map[string]bool{"is_mock": params.UseMock},
+				"generated_at":         now.Format(time.RFC3339),
+			}
+			if recommendedDay >= 0 {
+				date := now.AddDate(0, 0, recommendedDay)
+				result["recommended_date"] = date.Format("2006-01-02")
+				result["projected_balance_on_date"] = fmt.Sprintf("%.2f", balances[recommendedDay])
+				result["recommendation"] = fmt.Sprintf("Buy on or after %s, when your balance is projected at $%.2f and stays above $%.2f afterwards.",
+					date.Format("Jan 2"), balances[recommendedDay], needed)
+			} else {
+				result["recommended_date"] = nil
+				result["recommendation"] = fmt.Sprintf("Your projected balance doesn't comfortably cover $%.2f plus a $%.2f cushion in the next %d days. Consider saving up first.",
+					params.PurchaseAmount, cushion, horizonDays)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: MONTHLY REPORT
+// ============================================================================
+
+// createMonthlyReportTool builds a tool that assembles an end-of-month financial report
+// Orchestrates categorization, cash flow, and subscription detection into one summary
+func createMonthlyReportTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("monthly_report").
+		Description("Generate a month-end financial report: spending by category, income, net, savings rate, subscription total, top merchants, biggest purchase, and three coaching tips. Returns structured data plus a formatted text version suitable for email. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"month":    tools.StringProperty("Month to report on as YYYY-MM (default: current month)"),
+			"timezone": timezoneProperty(),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Month    string `json:"month"`
+				Timezone string `json:"timezone"`
+				UseMock  bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+
+			loc, tzWarning := resolveTimezone(params.Timezone)
+			now := time.Now().In(loc)
+			monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+			if params.Month != "" {
+				parsed, err := time.ParseInLocation("2006-01", params.Month, loc)
+				if err != nil {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("invalid month %q, expected YYYY-MM", params.Month),
+					}, nil
+				}
+				if parsed.After(now) {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("month %q hasn't started yet", params.Month),
+					}, nil
+				}
+				monthStart = parsed
+			}
+			monthEnd := monthStart.AddDate(0, 1, 0)
+			// Subscriptions need a few months of history to be detected
+			historyStart := monthStart.AddDate(0, -3, 0)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(4, mockOptions{}),
+					generateMockTransactionsForAnalysis(int(now.Sub(monthStart).Hours()/24)+1, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for monthly report", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": historyStart.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			report := buildMonthlyReport(transactions, monthStart, monthEnd, historyStart)
+			report["timezone"] = loc.String()
+			if tzWarning != "" {
+				report["timezone_warning"] = tzWarning
+			}
+			report["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			report["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    report,
+			}, nil
+		}).
+		Build()
+}
+
+// buildMonthlyReport computes the report sections for transactions dated within [monthStart, monthEnd)
+func buildMonthlyReport(transactions []map[string]interface{}, monthStart, monthEnd, historyStart time.Time) map[string]interface{} {
+	var spent, income, refunds float64
+	byCategory := make(map[string]float64)
+	byMerchant := make(map[string]float64)
+	var biggest map[string]interface{}
+	var biggestAmount float64
+
+	for _, tx := range transactions {
+		dateStr, _ := tx["date"].(string)
+		txDate, err := time.Parse(time.RFC3339, dateStr)
+		if err != nil || txDate.Before(monthStart) || !txDate.Before(monthEnd) {
+			continue
+		}
+		txType, _ := tx["type"].(string)
+		amount, _ := tx["amount"].(float64)
+		description, _ := tx["description"].(string)
+
+		switch txType {
+		case "send":
+			spent += amount
+			byCategory[categorizeTransaction(description)] += amount
+			byMerchant[merchantName(tx)] += amount
+			if amount > biggestAmount {
+				biggestAmount = amount
+				biggest = tx
+			}
+		case "receive":
+			if isRefund(description) {
+				refunds += amount
+				continue
+			}
+			income += amount
+		}
+	}
+
+	// Refunds come off spending so the savings rate is measured against real income
+	spent = math.Max(spent-refunds, 0)
+	net := income - spent
+	savingsRate := 0.0
+	if income > 0 {
+		savingsRate = net / income * 100
+	}
+	subscriptions := analyzeForSubscriptions(transactions, historyStart, 1.00, 999.99)
+	subscriptionTotal := calculateTotalMonthlyCost(subscriptions)
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return byCategory[categories[i]] > byCategory[categories[j]]
+	})
+	categoryBreakdown := []map[string]interface{}{}
+	for _, category := range categories {
+		categoryBreakdown = append(categoryBreakdown, map[string]interface{}{
+			"category": category,
+			"amount":   fmt.Sprintf("%.2f", byCategory[category]),
+		})
+	}
+
+	merchants := make([]string, 0, len(byMerchant))
+	for merchant := range byMerchant {
+		merchants = append(merchants, merchant)
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		return byMerchant[merchants[i]] > byMerchant[merchants[j]]
+	})
+	topMerchants := []map[string]interface{}{}
+	for i := 0; i < len(merchants) && i < 5; i++ {
+		topMerchants = append(topMerchants, map[string]interface{}{
+			"merchant": merchants[i],
+			"amount":   fmt.Sprintf("%.2f", byMerchant[merchants[i]]),
+		})
+	}
+
+	// Coaching tips, most relevant first
+	tips := []string{}
+	if net < 0 {
+		tips = append(tips, fmt.Sprintf("You spent $%.2f more than you earned. Pick one category to trim next month.", -net))
+	} else if savingsRate < 20 {
+		tips = append(tips, fmt.Sprintf("You saved %.0f%% of income. Aim for 20%% by automating a deposit right after payday.", savingsRate))
+	} else {
+		tips = append(tips, fmt.Sprintf("Great month! You saved %.0f%% of your income. Consider moving the surplus into savings to earn yield.", savingsRate))
+	}
+	if len(categories) > 0 && spent > 0 {
+		top := categories[0]
+		tips = append(tips, fmt.Sprintf("%s was your biggest category at %.0f%% of spending. A 10%% cut there saves $%.2f.", top, byCategory[top]/spent*100, byCategory[top]*0.1))
+	}
+	if subscriptionTotal > 0 {
+		tips = append(tips, fmt.Sprintf("Subscriptions cost about $%.2f/month. Review any you haven't used recently.", subscriptionTotal))
+	}
+	if len(tips) < 3 {
+		tips = append(tips, "Check in weekly on your spending so there are no surprises at month end.")
+	}
+	tips = tips[:3]
+
+	monthName := monthStart.Format("January 2006")
+	var text strings.Builder
+	fmt.Fprintf(&text, "MONTHLY REPORT: %s\n\n", monthName)
+	fmt.Fprintf(&text, "OVERVIEW\n  Income: $%.2f\n  Spending: $%.2f\n  Net: $%.2f\n  Savings rate: %.1f%%\n  Subscriptions: $%.2f/month\n\n", income, spent, net, savingsRate, subscriptionTotal)
+	text.WriteString("SPENDING BY CATEGORY\n")
+	for _, category := range categories {
+		fmt.Fprintf(&text, "  %s: $%.2f\n", category, byCategory[category])
+	}
+	text.WriteString("\nTOP MERCHANTS\n")
+	for _, merchant := range topMerchants {
+		fmt.Fprintf(&text, "  %s: $%s\n", merchant["merchant"], merchant["amount"])
+	}
+	if biggest != nil {
+		fmt.Fprintf(&text, "\nBIGGEST PURCHASE\n  %s: $%.2f\n", biggest["description"], biggestAmount)
+	}
+	text.WriteString("\nTIPS FOR NEXT MONTH\n")
+	for i, tip := range tips {
+		fmt.Fprintf(&text, "  %d. %s\n", i+1, tip)
+	}
+
+	return map[string]interface{}{
+		"month":                monthStart.Format("2006-01"),
+		"total_income":         fmt.Sprintf("%.2f", income),
+		"total_spent":          fmt.Sprintf("%.2f", spent),
+		"net":                  fmt.Sprintf("%.2f", net),
+		"savings_rate":         fmt.Sprintf("%.1f%%", savingsRate),
+		"spending_by_category": categoryBreakdown,
+		"subscription_total":   fmt.Sprintf("%.2f", subscriptionTotal),
+		"top_merchants":        topMerchants,
+		"biggest_purchase":     biggest,
+		"coaching_tips":        tips,
+		"report_text":          text.String(),
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: LIFESTYLE INFLATION
+// ============================================================================
+
+// createLifestyleInflationTool builds a tool that detects spending creeping up faster than income
+// Compares the first third of the window against the last third
+func createLifestyleInflationTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("detect_lifestyle_inflation").
+		Description("Detect lifestyle inflation: compares average monthly spending and income in the first third of a multi-month window against the last third. Flags when spending rose while income stayed flat or grew more slowly, and names the categories driving the increase. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"timeframe_months": tools.IntegerProperty("Number of months to analyze, at least 3 (default: 6)"),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				TimeframeMonths int  `json:"timeframe_months"`
+				UseMock         bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.TimeframeMonths == 0 {
+				params.TimeframeMonths = 6
+			}
+			if params.TimeframeMonths < 3 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "timeframe_months must be at least 3 to compare the start and end of the window",
+				}, nil
+			}
+
+			now := time.Now()
+			windowStart := now.AddDate(0, -params.TimeframeMonths, 0)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(int(now.Sub(windowStart).Hours()/24), mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for lifestyle inflation", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": windowStart.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			result := detectLifestyleInflation(transactions, windowStart, now)
+			result["period"] = fmt.Sprintf("%d months", params.TimeframeMonths)
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// detectLifestyleInflation compares monthly-normalized spend and income between the window's outer thirds
+func detectLifestyleInflation(transactions []map[string]interface{}, windowStart, windowEnd time.Time) map[string]interface{} {
+	third := windowEnd.Sub(windowStart) / 3
+	earlyEnd := windowStart.Add(third)
+	lateStart := windowEnd.Add(-third)
+	thirdMonths := third.Hours() / 24 / daysPerMonth
+
+	var earlySpend, lateSpend, earlyIncome, lateIncome float64
+	earlyByCategory := make(map[string]float64)
+	lateByCategory := make(map[string]float64)
+
+	for _, tx := range transactions {
+		dateStr, _ := tx["date"].(string)
+		txDate, err := time.Parse(time.RFC3339, dateStr)
+		if err != nil {
+			continue
+		}
+		txType, _ := tx["type"].(string)
+		amount, _ := tx["amount"].(float64)
+		description, _ := tx["description"].(string)
+
+		inEarly := !txDate.Before(windowStart) && txDate.Before(earlyEnd)
+		inLate := !txDate.Before(lateStart) && !txDate.After(windowEnd)
+
+		switch {
+		case txType == "send" && inEarly:
+			earlySpend += amount
+			earlyByCategory[categorizeTransaction(description)] += amount
+		case txType == "send" && inLate:
+			lateSpend += amount
+			lateByCategory[categorizeTransaction(description)] += amount
+		case txType == "receive" && inEarly:
+			earlyIncome += amount
+		case txType == "receive" && inLate:
+			lateIncome += amount
+		}
+	}
+
+	// Normalize each third to a monthly average
+	earlySpend /= thirdMonths
+	lateSpend /= thirdMonths
+	earlyIncome /= thirdMonths
+	lateIncome /= thirdMonths
+
+	growth := func(before, after float64) float64 {
+		if before == 0 {
+			return 0
+		}
+		return (after - before) / before * 100
+	}
+	spendGrowth := growth(earlySpend, lateSpend)
+	incomeGrowth := growth(earlyIncome, lateIncome)
+
+	// Categories whose monthly spend grew the most
+	categories := make(map[string]bool)
+	for category := range earlyByCategory {
+		categories[category] = true
+	}
+	for category := range lateByCategory {
+		categories[category] = true
+	}
+	type categoryDelta struct {
+		name  string
+		delta float64
+	}
+	deltas := []categoryDelta{}
+	for category := range categories {
+		delta := (lateByCategory[category] - earlyByCategory[category]) / thirdMonths
+		if delta > 0 {
+			deltas = append(deltas, categoryDelta{category, delta})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].delta > deltas[j].delta
+	})
+	drivers := []map[string]interface{}{}
+	for i := 0; i < len(deltas) && i < 3; i++ {
+		drivers = append(drivers, map[string]interface{}{
+			"category":         deltas[i].name,
+			"monthly_increase": fmt.Sprintf("%.2f", deltas[i].delta),
+		})
+	}
+
+	inflating := spendGrowth > 5 && spendGrowth > incomeGrowth
+	summary := "No lifestyle inflation detected: spending is keeping pace with (or below) income."
+	if inflating {
+		summary = fmt.Sprintf("Spending grew %.1f%% while income grew %.1f%%. Watch the categories driving the increase.", spendGrowth, incomeGrowth)
+	}
+
+	return map[string]interface{}{
+		"lifestyle_inflation_detected": inflating,
+		"inflation_rate":               fmt.Sprintf("%.1f%%", spendGrowth),
+		"income_growth_rate":           fmt.Sprintf("%.1f%%", incomeGrowth),
+		"early_monthly_spend":          fmt.Sprintf("%.2f", earlySpend),
+		"late_monthly_spend":           fmt.Sprintf("%.2f", lateSpend),
+		"early_monthly_income":         fmt.Sprintf("%.2f", earlyIncome),
+		"late_monthly_income":          fmt.Sprintf("%.2f", lateIncome),
+		"top_contributing_categories":  drivers,
+		"summary":                      summary,
+	}
+}
+
+// ============================================================================
+// DEMO SCRIPT
+// ============================================================================
+// A canned, repeatable walkthrough of the analyzers for live demos. It uses
+// seeded mock data and calls the analysis functions directly, so it needs
+// neither the LLM nor the network.
+
+// demoSeed keeps the demo's mock data identical across runs
+const demoSeed = 20240101
+
+// handleDemo serves GET /api/demo with the combined output of each analyzer
+func handleDemo(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	spendingTxs := generateMockTransactionsForAnalysis(30, mockOptions{Seed: demoSeed})
+	parsedSpendingTxs, _ := parseTransactions(spendingTxs)
+	subscriptionTxs := generateMockSubscriptionTransactions(6, mockOptions{Seed: demoSeed})
+	subscriptionCutoff := now.AddDate(0, -6, 0)
+	subscriptions := analyzeForSubscriptions(subscriptionTxs, subscriptionCutoff, 1.00, 999.99)
+
+	steps := []map[string]interface{}{
+		{
+			"step":   "analyze_spending",
+			"prompt": "How am I spending my money this month?",
+			"result": analyzeTransactions(parsedSpendingTxs, 30, nil, defaultLanguage, false, 0),
+		},
+		{
+			"step":   "analyze_subscriptions",
+			"prompt": "What subscriptions am I paying for?",
+			"result": map[string]interface{}{
+				"subscriptions":      subscriptions,
+				"total_monthly_cost": calculateTotalMonthlyCost(subscriptions),
+				"cost_by_frequency":  calculateCostByFrequency(subscriptions),
+				"warnings":           generateWarnings(subscriptions, defaultLanguage, defaultMaxWarnings),
+			},
+		},
+		{
+			"step":   "compare_merchants",
+			"prompt": "Where's the cheapest place I buy food?",
+			"result": compareCategoryMerchants(spendingTxs, "Food & Dining", time.UTC),
+		},
+		{
+			"step":   "find_uncategorized",
+			"prompt": "What spending couldn't you categorize?",
+			"result": findUncategorizedSpending(spendingTxs, 5),
+		},
+		{
+			"step":   "detect_recurring_income",
+			"prompt": "When do I get paid?",
+			"result": detectRecurringIncome(spendingTxs, now.AddDate(0, 0, -30)),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"seed":         demoSeed,
+		"steps":        steps,
+		"generated_at": now.Format(time.RFC3339),
+	})
+}
+
+// ============================================================================
+// CUSTOM TOOL: GOAL ALLOCATION
+// ============================================================================
+
+// savingsGoal is one goal the user wants to split spare money across
+type savingsGoal struct {
+	Name     string  `json:"name"`
+	Target   float64 `json:"target"`
+	Current  float64 `json:"current"`
+	Priority int     `json:"priority"`
+}
+
+// goalsProperty is the shared schema for a list of savings goals
+func goalsProperty(description string) map[string]interface{} {
+	return tools.ArrayProperty(description,
+		tools.ObjectSchema(map[string]interface{}{
+			"name":     tools.StringProperty("Goal name, e.g. 'Emergency fund'"),
+			"target":   tools.NumberProperty("Target amount for the goal"),
+			"current":  tools.NumberProperty("Amount already saved toward the goal (default: 0)"),
+			"priority": tools.IntegerProperty("Priority, 1 = most important (default: 1)"),
+		}, "name", "target"))
+}
+
+// createGoalAllocationTool builds a tool that plans how to split spare money across savings goals
+// It never moves money itself; it returns deposit_savings payloads for the user to confirm
+func createGoalAllocationTool() core.Tool {
+	return tools.New("allocate_to_goals").
+		Description("Plan how to split an available amount across several savings goals, either by priority (fill the most important goal first) or proportionally to what each goal still needs. Returns the per-goal allocation, updated progress, and deposit_savings payloads to confirm. Does not move any money.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"goals":            goalsProperty("Savings goals to fund"),
+			"available_amount": tools.NumberProperty("Amount available to allocate"),
+			"strategy":         tools.StringEnumProperty("How to split the amount (default: priority)", "priority", "proportional"),
+			"currency":         tools.StringProperty("Currency for the deposit payloads: 'USD' or 'EUR' (default: USD)"),
+		}, "goals", "available_amount")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Goals           []savingsGoal `json:"goals"`
+				AvailableAmount float64       `json:"available_amount"`
+				Strategy        string        `json:"strategy"`
+				Currency        string        `json:"currency"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if len(params.Goals) == 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "at least one goal is required",
+				}, nil
+			}
+			if params.AvailableAmount <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "available_amount must be greater than 0",
+				}, nil
+			}
+			for _, goal := range params.Goals {
+				if goal.Target <= 0 {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("goal %q needs a target greater than 0", goal.Name),
+					}, nil
+				}
+			}
+			if params.Strategy == "" {
+				params.Strategy = "priority"
+			}
+			if params.Strategy != "priority" && params.Strategy != "proportional" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "strategy must be 'priority' or 'proportional'",
+				}, nil
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+			params.Currency = strings.ToUpper(params.Currency)
+
+			allocations := allocateToGoals(params.Goals, params.AvailableAmount, params.Strategy)
+
+			var allocated float64
+			goalResults := []map[string]interface{}{}
+			deposits := []map[string]interface{}{}
+			for i, goal := range params.Goals {
+				amount := allocations[i]
+				allocated += amount
+				newTotal := goal.Current + amount
+				goalResults = append(goalResults, map[string]interface{}{
+					"name":             goal.Name,
+					"priority":         goalPriority(goal),
+					"target":           fmt.Sprintf("%.2f", goal.Target),
+					"allocation":       fmt.Sprintf("%.2f", amount),
+					"new_total":        fmt.Sprintf("%.2f", newTotal),
+					"remaining":        fmt.Sprintf("%.2f", math.Max(goal.Target-newTotal, 0)),
+					"progress_percent": fmt.Sprintf("%.1f%%", math.Min(newTotal/goal.Target*100, 100)),
+				})
+				if amount > 0 {
+					deposits = append(deposits, map[string]interface{}{
+						"goal": goal.Name,
+						"tool": "deposit_savings",
+						"input": map[string]interface{}{
+							"amount":   fmt.Sprintf("%.2f", amount),
+							"currency": params.Currency,
+						},
+					})
+				}
+			}
+
+			unallocated := math.Round((params.AvailableAmount-allocated)*100) / 100
+			summary := fmt.Sprintf("Split $%.2f across %d goals by %s", allocated, len(deposits), params.Strategy)
+			if unallocated > 0 {
+				summary += fmt.Sprintf("; $%.2f is left over because every goal is fully funded", unallocated)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"strategy":         params.Strategy,
+					"available_amount": fmt.Sprintf("%.2f", params.AvailableAmount),
+					"allocated":        fmt.Sprintf("%.2f", allocated),
+					"unallocated":      fmt.Sprintf("%.2f", unallocated),
+					"goals":            goalResults,
+					"deposit_payloads": deposits,
+					"summary":          summary,
+					"note":             "Nothing has been moved. Confirm each deposit_savings payload to fund the goals.",
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// allocateToGoals returns the amount for each goal (same order as goals),
+// rounded down to cents and never more than what a goal still needs
+func allocateToGoals(goals []savingsGoal, available float64, strategy string) []float64 {
+	needs := make([]float64, len(goals))
+	var totalNeed float64
+	for i, goal := range goals {
+		needs[i] = math.Max(goal.Target-goal.Current, 0)
+		totalNeed += needs[i]
+	}
+
+	allocations := make([]float64, len(goals))
+	if totalNeed == 0 {
+		return allocations
+	}
+
+	if strategy == "proportional" {
+		share := math.Min(available/totalNeed, 1)
+		for i := range goals {
+			allocations[i] = math.Floor(needs[i]*share*100) / 100
+		}
+		return allocations
+	}
+
+	order := make([]int, len(goals))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return goalPriority(goals[order[a]]) < goalPriority(goals[order[b]])
+	})
+
+	remaining := available
+	for _, i := range order {
+		amount := math.Floor(math.Min(needs[i], remaining)*100) / 100
+		allocations[i] = amount
+		remaining -= amount
+	}
+	return allocations
+}
+
+// goalPriority treats a missing priority as the most important
+func goalPriority(goal savingsGoal) int {
+	if goal.Priority <= 0 {
+		return 1
+	}
+	return goal.Priority
+}
+
+// ============================================================================
+// CUSTOM TOOL: SPENDING BENCHMARK
+// ============================================================================
+
+// createBenchmarkTool builds a tool that compares the user's split of income to a budgeting rule
+// Essential categories count as needs, everything else as wants, and what's left of income as savings
+func createBenchmarkTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("benchmark_spending").
+		Description("Benchmark spending against a needs/wants/savings budgeting rule (50/30/20 by default). Maps each spending category to needs (essential) or wants, computes the user's actual split of monthly income, and reports the difference from the target rule with a verdict. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":                 tools.IntegerProperty("Number of days of history to analyze (default: 30)"),
+			"needs_percent":        tools.NumberProperty("Target share of income for needs (default: 50)"),
+			"wants_percent":        tools.NumberProperty("Target share of income for wants (default: 30)"),
+			"savings_percent":      tools.NumberProperty("Target share of income for savings (default: 20)"),
+			"monthly_income":       tools.NumberProperty("Monthly take-home income, if known (default: estimated from deposits)"),
+			"essential_categories": essentialCategoriesProperty(),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days                int      `json:"days"`
+				NeedsPercent        *float64 `json:"needs_percent"`
+				WantsPercent        *float64 `json:"wants_percent"`
+				SavingsPercent      *float64 `json:"savings_percent"`
+				MonthlyIncome       float64  `json:"monthly_income"`
+				EssentialCategories []string `json:"essential_categories"`
+				UseMock             bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+
+			rule := map[string]float64{"needs": 50, "wants": 30, "savings": 20}
+			if params.NeedsPercent != nil {
+				rule["needs"] = *params.NeedsPercent
+			}
+			if params.WantsPercent != nil {
+				rule["wants"] = *params.WantsPercent
+			}
+			if params.SavingsPercent != nil {
+				rule["savings"] = *params.SavingsPercent
+			}
+			if total := rule["needs"] + rule["wants"] + rule["savings"]; math.Abs(total-100) > 0.5 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("needs_percent, wants_percent and savings_percent must add up to 100 (got %.1f)", total),
+				}, nil
+			}
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for spending benchmark", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": time.Now().AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			income := params.MonthlyIncome
+			if income <= 0 {
+				income = summarizeCashFlow(transactions, params.Days).MonthlyIncome
+			}
+			if income <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "no income found in this period; pass monthly_income to benchmark against",
+				}, nil
+			}
+
+			result := benchmarkSpending(transactions, params.Days, income, rule, resolveEssentials(params.EssentialCategories))
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = time.Now().Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// benchmarkSpending splits monthly income into needs, wants and savings and compares it with rule
+// Deltas are percentage points of income; positive means above the target share
+func benchmarkSpending(transactions []map[string]interface{}, days int, monthlyIncome float64, rule map[string]float64, essentials essentialSet) map[string]interface{} {
+	byCategory := make(map[string]float64)
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		if txType != "send" {
+			continue
+		}
+		amount, _ := tx["amount"].(float64)
+		description, _ := tx["description"].(string)
+		byCategory[categorizeTransaction(description)] += amount
+	}
+
+	scale := daysPerMonth / float64(days)
+	monthly := map[string]float64{}
+	names := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		names = append(names, category)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return byCategory[names[i]] > byCategory[names[j]]
+	})
+	categories := []map[string]interface{}{}
+	for _, category := range names {
+		bucket := "wants"
+		if essentials.has(category) {
+			bucket = "needs"
+		}
+		amount := byCategory[category] * scale
+		monthly[bucket] += amount
+		categories = append(categories, map[string]interface{}{
+			"category":       category,
+			"bucket":         bucket,
+			"monthly_amount": fmt.Sprintf("%.2f", amount),
+		})
+	}
+	monthly["savings"] = monthlyIncome - monthly["needs"] - monthly["wants"]
+
+	split := map[string]interface{}{}
+	var worstBucket string
+	var worstDelta, savingsDelta float64
+	for _, bucket := range []string{"needs", "wants", "savings"} {
+		actual := monthly[bucket] / monthlyIncome * 100
+		delta := actual - rule[bucket]
+		split[bucket] = map[string]interface{}{
+			"monthly_amount": fmt.Sprintf("%.2f", monthly[bucket]),
+			"actual_percent": math.Round(actual*10) / 10,
+			"target_percent": rule[bucket],
+			"delta_points":   math.Round(delta*10) / 10,
+		}
+		// Too much on needs/wants or too little saved is what needs coaching
+		off := delta
+		if bucket == "savings" {
+			off = -delta
+			savingsDelta = delta
+		}
+		if off > worstDelta {
+			worstBucket, worstDelta = bucket, off
+		}
+	}
+
+	ruleName := fmt.Sprintf("%.0f/%.0f/%.0f", rule["needs"], rule["wants"], rule["savings"])
+	verdict := fmt.Sprintf("On target: your split is within 5 points of the %s rule.", ruleName)
+	switch {
+	case worstDelta <= 5 && savingsDelta > 5:
+		verdict = fmt.Sprintf("Ahead of the %s rule: you're saving %.0f points more than the target.", ruleName, savingsDelta)
+	case worstDelta <= 5:
+	case worstBucket == "savings":
+		verdict = fmt.Sprintf("You're saving %.0f points less than the %s rule suggests ($%.2f/month short).",
+			worstDelta, ruleName, worstDelta/100*monthlyIncome)
+	default:
+		verdict = fmt.Sprintf("%s take %.0f points more of your income than the %s rule suggests ($%.2f/month over).",
+			strings.ToUpper(worstBucket[:1])+worstBucket[1:], worstDelta, ruleName, worstDelta/100*monthlyIncome)
+	}
+
+	return map[string]interface{}{
+		"rule":           ruleName,
+		"monthly_income": fmt.Sprintf("%.2f", monthlyIncome),
+		"split":          split,
+		"categories":     categories,
+		"verdict":        verdict,
+		"period_days":    days,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: INCOME STATUS
+// ============================================================================
+
+// createIncomeDelayTool builds a tool that checks whether expected income has arrived
+// Uses recurring-income detection to find the next payday and compares it with today
+func createIncomeDelayTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("check_income_status").
+		Description("Check whether the user's regular income (e.g. payroll) has arrived on schedule. Finds the expected next payday from recurring deposits and reports whether it is upcoming, due, or overdue and by how many days. Irregular income is reported as such rather than as late. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":       tools.IntegerProperty("Number of days of history used to learn the pay schedule (default: 90)"),
+			"grace_days": tools.IntegerProperty("Days past the expected date before income counts as overdue (default: 2)"),
+			"use_mock":   tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days      int  `json:"days"`
+				GraceDays *int `json:"grace_days"`
+				UseMock   bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			graceDays := 2
+			if params.GraceDays != nil && *params.GraceDays >= 0 {
+				graceDays = *params.GraceDays
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -params.Days)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockPayrollTransactions(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for income status", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			sources := []map[string]interface{}{}
+			for _, income := range detectRecurringIncome(transactions, cutoffDate) {
+				sources = append(sources, incomeStatus(income, now, graceDays))
+			}
+
+			result := map[string]interface{}{
+				"grace_days":   graceDays,
+				"sources":      sources,
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			if len(sources) == 0 {
+				result["status"] = "irregular"
+				result["message"] = "No regular income pattern found, so there's no expected payday to be late against."
+			} else {
+				// Sources are sorted by monthly equivalent, so the first regular one is the main paycheck
+				primary := sources[0]
+				for _, source := range sources {
+					if source["status"] != "irregular" {
+						primary = source
+						break
+					}
+				}
+				result["status"] = primary["status"]
+				result["message"] = primary["message"]
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// incomeStatus classifies one detected income source as upcoming, due, overdue or irregular
+func incomeStatus(income map[string]interface{}, now time.Time, graceDays int) map[string]interface{} {
+	source, _ := income["source"].(string)
+	frequency, _ := income["frequency"].(string)
+	occurrences, _ := income["occurrences"].(int)
+	nextStr, _ := income["estimated_next"].(string)
+
+	status := map[string]interface{}{
+		"source":         source,
+		"frequency":      frequency,
+		"average_amount": income["average_amount"],
+		"last_received":  income["last_occurrence"],
+		"expected_next":  nextStr,
+	}
+
+	next, err := time.Parse("2006-01-02", nextStr)
+	// Two deposits are a pattern of one interval; "late" needs a few paydays to mean anything
+	if err != nil || occurrences < 3 {
+		status["status"] = "irregular"
+		status["message"] = fmt.Sprintf("%s doesn't arrive on a steady enough schedule to call it late.", source)
+		return status
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysLate := int(today.Sub(next).Hours() / 24)
+	switch {
+	case daysLate > graceDays:
+		status["status"] = "overdue"
+		status["days_overdue"] = daysLate
+		status["message"] = fmt.Sprintf("%s was expected on %s and is %d days late. It's worth checking with the payer; holidays and bank processing can also cause delays.",
+			source, next.Format("Jan 2"), daysLate)
+	case daysLate >= 0:
+		status["status"] = "due"
+		status["days_overdue"] = daysLate
+		status["message"] = fmt.Sprintf("%s is due around %s. A day or two of delay is normal, so no need to worry yet.", source, next.Format("Jan 2"))
+	default:
+		status["status"] = "upcoming"
+		status["days_until"] = -daysLate
+		status["message"] = fmt.Sprintf("%s is on schedule; next expected on %s (in %d days).", source, next.Format("Jan 2"), -daysLate)
+	}
+	return status
+}
+
+// ============================================================================
+// CUSTOM TOOL: RECURRING OVERVIEW
+// ============================================================================
+
+// createRecurringOverviewTool builds a tool that merges subscriptions and recurring income
+// Answers "what's my fixed monthly picture?" with one calendar of inflows and outflows
+func createRecurringOverviewTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("recurring_overview").
+		Description("Show every recurring money movement in one view: subscriptions and bills going out, regular income coming in, a calendar of upcoming recurring events, and the net monthly recurring figure. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Months of history to detect recurring patterns in (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"horizon_days":     tools.IntegerProperty("Days ahead to include in the calendar (default: 30)"),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				TimeframeMonths int  `json:"timeframe_months"`
+				HorizonDays     int  `json:"horizon_days"`
+				UseMock         bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+			if params.HorizonDays <= 0 {
+				params.HorizonDays = 30
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{}),
+					generateMockPayrollTransactions(params.TimeframeMonths*30, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for recurring overview", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99)
+			income := detectRecurringIncome(transactions, cutoffDate)
+			monthlyOut := calculateTotalMonthlyCost(subscriptions)
+			monthlyIn := recurringMonthlyIncome(income)
+			net := monthlyIn - monthlyOut
+
+			calendar := []map[string]interface{}{}
+			for _, event := range predictCashEvents(transactions, cutoffDate, now, params.HorizonDays) {
+				direction := "outflow"
+				if event.Amount > 0 {
+					direction = "inflow"
+				}
+				calendar = append(calendar, map[string]interface{}{
+					"date":        event.Date.Format("2006-01-02"),
+					"description": event.Description,
+					"amount":      fmt.Sprintf("%.2f", math.Abs(event.Amount)),
+					"direction":   direction,
+				})
+			}
+
+			summary := fmt.Sprintf("Recurring income of $%.2f/month against $%.2f/month in recurring payments leaves $%.2f/month", monthlyIn, monthlyOut, net)
+			if net < 0 {
+				summary = fmt.Sprintf("Recurring payments of $%.2f/month exceed recurring income of $%.2f/month by $%.2f", monthlyOut, monthlyIn, -net)
+			}
+
+			result := map[string]interface{}{
+				"analysis_period":       fmt.Sprintf("%d months", params.TimeframeMonths),
+				"inflows":               income,
+				"outflows":              formatSubscriptions(subscriptions, false),
+				"monthly_inflows":       fmt.Sprintf("%.2f", monthlyIn),
+				"monthly_outflows":      fmt.Sprintf("%.2f", monthlyOut),
+				"net_monthly_recurring": fmt.Sprintf("%.2f", net),
+				"calendar":              calendar,
+				"summary":               summary,
+				"data_source":           map[string]bool{"is_mock": params.UseMock},
+				"generated_at":          now.Format(time.RFC3339),
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: SAFE TO SPEND
+// ============================================================================
+
+// createSafeToSpendTool builds a tool that answers "how much can I safely spend today?"
+// Subtracts bills due before the next payday, planned savings, and a cushion from the balance
+func createSafeToSpendTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("safe_to_spend").
+		Description("Calculate how much the user can safely spend right now: current balance minus bills predicted before the next payday, planned savings contributions, and a safety cushion. Returns the safe amount, a per-day figure until payday, and the full breakdown. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"savings_contribution": tools.NumberProperty("Amount the user plans to move to savings goals before payday (default: 0)"),
+			"cushion":              tools.NumberProperty("Minimum balance to keep as a buffer (default: 200)"),
+			"current_balance":      tools.NumberProperty("Override the wallet balance instead of fetching it"),
+			"currency":             tools.StringProperty("Currency of the balance (default: USD)"),
+			"cycle_start_day":      tools.IntegerProperty("Day of the month the user's budget cycle starts (1-31). Used as the end of the period when no payday is detected (default: 30 days ahead)"),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				SavingsContribution float64  `json:"savings_contribution"`
+				Cushion             *float64 `json:"cushion"`
+				CurrentBalance      *float64 `json:"current_balance"`
+				Currency            string   `json:"currency"`
+				CycleStartDay       int      `json:"cycle_start_day"`
+				UseMock             bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if !validCycleStartDay(params.CycleStartDay) {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "cycle_start_day must be between 1 and 31",
+				}, nil
+			}
+			cushion := 200.0
+			if params.Cushion != nil {
+				cushion = *params.Cushion
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			// Without a detected payday, look a month ahead
+			const historyDays, horizonDays = 90, 30
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -historyDays)
+
+			var transactions []map[string]interface{}
+			balance := mockWalletBalance
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(3, mockOptions{}),
+					generateMockPayrollTransactions(historyDays, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for safe to spend", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if params.CurrentBalance == nil {
+					if balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency); err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+			}
+			if params.CurrentBalance != nil {
+				balance = *params.CurrentBalance
+			}
+
+			events := predictCashEvents(transactions, cutoffDate, now, horizonDays)
+
+			var nextPayday *cashEvent
+			for i := range events {
+				if events[i].Kind == "income" {
+					nextPayday = &events[i]
+					break
+				}
+			}
+			until := now.AddDate(0, 0, horizonDays)
+			if nextPayday != nil {
+				until = nextPayday.Date
+			} else if params.CycleStartDay > 0 {
+				_, until = payCycleBounds(now, params.CycleStartDay)
+			}
+
+			var billsTotal float64
+			bills := []map[string]interface{}{}
+			for _, event := range events {
+				if event.Kind != "bill" || !event.Date.Before(until) {
+					continue
+				}
+				billsTotal += -event.Amount
+				bills = append(bills, map[string]interface{}{
+					"date":        event.Date.Format("2006-01-02"),
+					"description": event.Description,
+					"amount":      fmt.Sprintf("%.2f", -event.Amount),
+				})
+			}
+
+			safe := balance - billsTotal - params.SavingsContribution - cushion
+			daysLeft := int(math.Ceil(until.Sub(now).Hours() / 24))
+			if daysLeft < 1 {
+				daysLeft = 1
+			}
+
+			result := map[string]interface{}{
+				"safe_to_spend":  fmt.Sprintf("%.2f", math.Max(safe, 0)),
+				"safe_per_day":   fmt.Sprintf("%.2f", math.Max(safe, 0)/float64(daysLeft)),
+				"days_remaining": daysLeft,
+				"breakdown": map[string]interface{}{
+					"current_balance":      fmt.Sprintf("%.2f", balance),
+					"upcoming_bills":       fmt.Sprintf("%.2f", billsTotal),
+					"savings_contribution": fmt.Sprintf("%.2f", params.SavingsContribution),
+					"cushion":              fmt.Sprintf("%.2f", cushion),
+				},
+				"bills_before_payday": bills,
+				"data_source":         map[string]bool{"is_mock": params.UseMock},
+				"generated_at":        now.Format(time.RFC3339),
+			}
+			if nextPayday != nil {
+				result["next_payday"] = nextPayday.Date.Format("2006-01-02")
+				result["next_payday_source"] = nextPayday.Description
+			} else {
+				result["next_payday"] = nil
+				result["note"] = fmt.Sprintf("No regular income detected, so bills over the next %d days were counted.", horizonDays)
+				if params.CycleStartDay > 0 {
+					result["note"] = fmt.Sprintf("No regular income detected, so bills until your next cycle starts on %s were counted.", until.Format("Jan 2"))
+				}
+			}
+
+			if safe > 0 {
+				result["summary"] = fmt.Sprintf("You can safely spend $%.2f until %s (about $%.2f/day) after $%.2f in bills, $%.2f to savings and a $%.2f cushion.",
+					safe, until.Format("Jan 2"), safe/float64(daysLeft), billsTotal, params.SavingsContribution, cushion)
+			} else {
+				result["summary"] = fmt.Sprintf("Nothing is safe to spend before %s: bills, savings and your cushion need $%.2f more than your balance.",
+					until.Format("Jan 2"), -safe)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: BILLING PLAN COMPARISON
+// ============================================================================
+
+// createPlanComparisonTool builds a tool that compares a monthly plan with its annual equivalent
+// Optionally looks up how long the user has paid the merchant to judge whether annual would have paid off
+func createPlanComparisonTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("compare_billing_plans").
+		Description("Compare a monthly plan with an annual plan for the same service: annual savings, the break-even month, and, if a merchant is given, whether the user's subscription history shows they'd have saved by paying annually. Returns a clear recommendation. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"monthly_price":    tools.NumberProperty("Price per month on the monthly plan"),
+			"annual_price":     tools.NumberProperty("Price per year on the annual plan"),
+			"merchant":         tools.StringProperty("Merchant name to look up in subscription history, e.g. 'Spotify'"),
+			"timeframe_months": tools.IntegerProperty("Months of history to search for the merchant (default: 12)"),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "monthly_price", "annual_price")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				MonthlyPrice    float64 `json:"monthly_price"`
+				AnnualPrice     float64 `json:"annual_price"`
+				Merchant        string  `json:"merchant"`
+				TimeframeMonths int     `json:"timeframe_months"`
+				UseMock         bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.MonthlyPrice <= 0 || params.AnnualPrice <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "monthly_price and annual_price must both be greater than 0",
+				}, nil
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = 12
+			}
+
+			yearlyOnMonthly := params.MonthlyPrice * 12
+			annualSavings := yearlyOnMonthly - params.AnnualPrice
+			breakEvenMonth := int(math.Ceil(params.AnnualPrice / params.MonthlyPrice))
+
+			result := map[string]interface{}{
+				"monthly_price":      fmt.Sprintf("%.2f", params.MonthlyPrice),
+				"annual_price":       fmt.Sprintf("%.2f", params.AnnualPrice),
+				"yearly_on_monthly":  fmt.Sprintf("%.2f", yearlyOnMonthly),
+				"annual_savings":     fmt.Sprintf("%.2f", annualSavings),
+				"savings_percent":    fmt.Sprintf("%.1f%%", annualSavings/yearlyOnMonthly*100),
+				"break_even_month":   breakEvenMonth,
+				"break_even_explain": fmt.Sprintf("The annual plan pays off if you keep the service at least %d months.", breakEvenMonth),
+				"generated_at":       time.Now().Format(time.RFC3339),
+			}
+
+			if annualSavings <= 0 {
+				result["recommendation"] = "Stay monthly: the annual plan costs as much or more than 12 monthly payments."
+				return &core.ToolResult{
+					Success: true,
+					Data:    result,
+				}, nil
+			}
+
+			tenureMonths := -1
+			if params.Merchant != "" {
+				cutoffDate := time.Now().AddDate(0, -params.TimeframeMonths, 0)
+				var transactions []map[string]interface{}
+				if params.UseMock {
+					transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{})
+					log.Printf("📊 Generated %d mock transactions for billing plan comparison", len(transactions))
+				} else {
+					var err error
+					transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+						"limit":      500,
+						"start_date": cutoffDate.Format("2006-01-02"),
+					})
+					if err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+				// Group by merchant only so a price change doesn't reset tenure
+				tenureMonths = merchantTenureMonths(detectRecurring(transactions, RecurringOpts{
+					Type:   "send",
+					Cutoff: cutoffDate,
+				}), params.Merchant)
+				result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			}
+
+			switch {
+			case tenureMonths < 0 && params.Merchant != "":
+				result["tenure"] = fmt.Sprintf("No recurring payments to %s found in the last %d months", params.Merchant, params.TimeframeMonths)
+				result["recommendation"] = fmt.Sprintf("Annual saves $%.2f a year, but only if you're confident you'll keep it %d+ months.", annualSavings, breakEvenMonth)
+			case tenureMonths < 0:
+				result["recommendation"] = fmt.Sprintf("Annual saves $%.2f a year if you'll keep the service at least %d months; otherwise stay monthly.", annualSavings, breakEvenMonth)
+			default:
+				// Cost of the months already paid on each plan, renewing annual each year
+				paidMonthly := float64(tenureMonths) * params.MonthlyPrice
+				paidAnnual := math.Ceil(float64(tenureMonths)/12) * params.AnnualPrice
+				result["tenure_months"] = tenureMonths
+				result["paid_on_monthly"] = fmt.Sprintf("%.2f", paidMonthly)
+				result["would_have_paid_on_annual"] = fmt.Sprintf("%.2f", paidAnnual)
+				result["annual_would_have_saved"] = paidAnnual < paidMonthly
+				if tenureMonths >= breakEvenMonth {
+					result["recommendation"] = fmt.Sprintf("Switch to annual: you've paid %s for %d months, past the %d-month break-even. That's $%.2f saved each year.",
+						params.Merchant, tenureMonths, breakEvenMonth, annualSavings)
+				} else {
+					result["recommendation"] = fmt.Sprintf("Stay monthly for now: you've had %s for %d months, short of the %d-month break-even. Revisit once it's clearly a keeper.",
+						params.Merchant, tenureMonths, breakEvenMonth)
+				}
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// merchantTenureMonths returns how many months the user has paid a merchant, or -1 if not found
+// Matches case-insensitively on the merchant name and counts from the first payment
+func merchantTenureMonths(patterns []RecurringPattern, merchant string) int {
+	needle := strings.ToLower(merchant)
+	tenure := -1
+	for _, pattern := range patterns {
+		if !strings.Contains(strings.ToLower(pattern.Name), needle) {
+			continue
+		}
+		months := int(math.Round(time.Since(pattern.Dates[0]).Hours()/24/daysPerMonth)) + 1
+		if months > tenure {
+			tenure = months
+		}
+	}
+	return tenure
+}
+
+// ============================================================================
+// FINANCIAL HEALTH SCORE
+// ============================================================================
+
+// calculateHealthScore rates overall financial health from 0 to 100
+// Four components: savings rate (40), subscription load (20), essential
+// spending share (20) and income regularity (20), each against income.
+func calculateHealthScore(cashFlow cashFlowSummary, subscriptionMonthly float64, recurringIncome float64) map[string]interface{} {
+	income := cashFlow.MonthlyIncome
+	if income <= 0 {
+		return map[string]interface{}{
+			"score":   0,
+			"grade":   "unknown",
+			"summary": "No income found, so a health score can't be calculated",
+		}
+	}
+
+	// clamp scales value linearly between worst (0 points) and best (max points)
+	clamp := func(value, worst, best, max float64) float64 {
+		share := (value - worst) / (best - worst)
+		return math.Round(math.Max(0, math.Min(1, share))*max*10) / 10
+	}
+
+	savingsRate := (income - cashFlow.MonthlySpend) / income * 100
+	subscriptionShare := subscriptionMonthly / income * 100
+	essentialShare := cashFlow.MonthlyEssentialSpend / income * 100
+	regularShare := math.Min(recurringIncome/income*100, 100)
+
+	components := map[string]interface{}{
+		"savings_rate":       map[string]interface{}{"value_percent": math.Round(savingsRate*10) / 10, "points": clamp(savingsRate, 0, 20, 40), "max": 40},
+		"subscription_load":  map[string]interface{}{"value_percent": math.Round(subscriptionShare*10) / 10, "points": clamp(subscriptionShare, 20, 5, 20), "max": 20},
+		"essential_spending": map[string]interface{}{"value_percent": math.Round(essentialShare*10) / 10, "points": clamp(essentialShare, 80, 50, 20), "max": 20},
+		"income_regularity":  map[string]interface{}{"value_percent": math.Round(regularShare*10) / 10, "points": clamp(regularShare, 0, 80, 20), "max": 20},
+	}
+
+	var score float64
+	for _, component := range components {
+		score += component.(map[string]interface{})["points"].(float64)
+	}
+	score = math.Round(score)
+
+	grade := "needs attention"
+	switch {
+	case score >= 80:
+		grade = "excellent"
+	case score >= 60:
+		grade = "good"
+	case score >= 40:
+		grade = "fair"
+	}
+
+	return map[string]interface{}{
+		"score":      score,
+		"grade":      grade,
+		"components": components,
+		"summary":    fmt.Sprintf("Financial health score: %.0f/100 (%s)", score, grade),
+	}
+}
+
+// ============================================================================
+// FULL ANALYSIS ENDPOINT
+// ============================================================================
+// One round-trip for dashboards: runs every analyzer over the same
+// transaction set and returns the combined result.
+
+// handleAnalyzeFull serves POST /api/analyze/full
+// The body may carry a "transactions" array; without one, seeded mock data is generated.
+func handleAnalyzeFull(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Transactions []map[string]interface{} `json:"transactions"`
+		Days         int                      `json:"days"`
+		MockSeed     int64                    `json:"mock_seed"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, fmt.Sprintf("invalid JSON body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if body.Days <= 0 {
+		body.Days = 90
+	}
+	// Unauthenticated endpoint: cap the window so one request can't ask for
+	// an unbounded amount of mock data
+	if body.Days > 365 {
+		body.Days = 365
+	}
+
+	now := time.Now()
+	cutoffDate := now.AddDate(0, 0, -body.Days)
+	isMock := len(body.Transactions) == 0
+	transactions := body.Transactions
+	if isMock {
+		opts := mockOptions{Seed: body.MockSeed}
+		transactions = generateMockTransactionsForAnalysis(body.Days, opts)
+		transactions = append(transactions, generateMockSubscriptionTransactions(max(1, body.Days/30), opts)...)
+		transactions = append(transactions, generateMockPayrollTransactions(body.Days, opts)...)
+	}
+	// Posted transactions don't come through fetchTransactions, so normalize them the same way
+	transactions = normalizeTransactionAmounts(transactions, false)
+
+	// The analyzers only read the transactions, so they can share them
+	var (
+		wg            sync.WaitGroup
+		spending      map[string]interface{}
+		skipped       int
+		subscriptions []map[string]interface{}
+		income        []map[string]interface{}
+		cashFlow      cashFlowSummary
+	)
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		parsed, errs := parseTransactions(transactions)
+		spending, skipped = analyzeTransactions(parsed, body.Days, nil, defaultLanguage, false, 0), len(errs)
+	}()
+	go func() {
+		defer wg.Done()
+		subscriptions = analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99)
+	}()
+	go func() {
+		defer wg.Done()
+		income = detectRecurringIncome(transactions, cutoffDate)
+	}()
+	go func() {
+		defer wg.Done()
+		cashFlow = summarizeCashFlow(transactions, body.Days)
+	}()
+	wg.Wait()
+
+	subscriptionMonthly := calculateTotalMonthlyCost(subscriptions)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"period_days":        body.Days,
+		"total_transactions": len(transactions),
+		"skipped_count":      skipped,
+		"spending":           spending,
+		"subscriptions": map[string]interface{}{
+			"subscriptions":      formatSubscriptions(subscriptions, false),
+			"total_monthly_cost": subscriptionMonthly,
+			"warnings":           generateWarnings(subscriptions, defaultLanguage, defaultMaxWarnings),
+		},
+		"recurring_income": income,
+		"health_score":     calculateHealthScore(cashFlow, subscriptionMonthly, recurringMonthlyIncome(income)),
+		"data_source":      map[string]bool{"is_mock": isMock},
+		"generated_at":     now.Format(time.RFC3339),
+	})
+}
+
+// ============================================================================
+// CUSTOM TOOL: ROUND-UPS
+// ============================================================================
+
+// createRoundupTool builds a tool that calculates "spare change" savings from rounding up spending
+// Rounds each purchase, or each week's total, up to the next $1, $2 or $5
+func createRoundupTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("calculate_roundups").
+		Description("Calculate how much the user would save by rounding up spending and saving the difference. Rounds each purchase (per_transaction) or each week's total spend (weekly_total) up to the next $1, $2 or $5. Returns the round-up total, a monthly projection, and a deposit_savings payload to confirm. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":     tools.IntegerProperty(fmt.Sprintf("Number of days of spending to round up (default: %d)", defaultSpendingDays)),
+			"round_to": tools.IntegerProperty("Round up to the next multiple of this many dollars: 1, 2 or 5 (default: 1)"),
+			"mode":     tools.StringEnumProperty("Round each transaction or each week's total (default: per_transaction)", "per_transaction", "weekly_total"),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days    int    `json:"days"`
+				RoundTo int    `json:"round_to"`
+				Mode    string `json:"mode"`
+				UseMock bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+			if params.RoundTo == 0 {
+				params.RoundTo = 1
+			}
+			if params.RoundTo != 1 && params.RoundTo != 2 && params.RoundTo != 5 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "round_to must be 1, 2 or 5",
+				}, nil
+			}
+			if params.Mode == "" {
+				params.Mode = "per_transaction"
+			}
+			if params.Mode != "per_transaction" && params.Mode != "weekly_total" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "mode must be 'per_transaction' or 'weekly_total'",
+				}, nil
+			}
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for round-ups", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": time.Now().AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, _ := parseTransactions(transactions)
+			var amounts []float64
+			if params.Mode == "weekly_total" {
+				weekly := make(map[string]float64)
+				for _, tx := range parsed {
+					if tx.Type == "send" {
+						year, week := tx.Date.ISOWeek()
+						weekly[fmt.Sprintf("%d-W%02d", year, week)] += tx.Amount
+					}
+				}
+				for _, total := range weekly {
+					amounts = append(amounts, total)
+				}
+			} else {
+				for _, tx := range parsed {
+					if tx.Type == "send" {
+						amounts = append(amounts, tx.Amount)
+					}
+				}
+			}
+
+			var total float64
+			contributing := 0
+			for _, amount := range amounts {
+				if r := roundUpAmount(amount, params.RoundTo); r > 0 {
+					total += r
+					contributing++
+				}
+			}
+			monthly := total / float64(params.Days) * daysPerMonth
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"mode":              params.Mode,
+					"round_to":          params.RoundTo,
+					"period_days":       params.Days,
+					"rounded_items":     len(amounts),
+					"contributing":      contributing,
+					"roundup_total":     fmt.Sprintf("%.2f", total),
+					"monthly_projected": fmt.Sprintf("%.2f", monthly),
+					"yearly_projected":  fmt.Sprintf("%.2f", monthly*12),
+					"deposit_payload": map[string]interface{}{
+						"tool": "deposit_savings",
+						"input": map[string]interface{}{
+							"amount":   fmt.Sprintf("%.2f", total),
+							"currency": "USD",
+						},
+					},
+					"summary":      fmt.Sprintf("Rounding up to the next $%d (%s) would have saved $%.2f over %d days, about $%.2f/month.", params.RoundTo, strings.ReplaceAll(params.Mode, "_", " "), total, params.Days, monthly),
+					"data_source":  map[string]bool{"is_mock": params.UseMock},
+					"generated_at": time.Now().Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// roundUpAmount returns the difference between amount and the next multiple of roundTo dollars
+// Works in cents so amounts already on a multiple contribute zero, not a full increment
+func roundUpAmount(amount float64, roundTo int) float64 {
+	cents := int64(math.Round(amount * 100))
+	step := int64(roundTo) * 100
+	if cents <= 0 || cents%step == 0 {
+		return 0
+	}
+	return float64(step-cents%step) / 100
+}
+
+// ============================================================================
+// CUSTOM TOOL: SPENDING IMPACT
+// ============================================================================
+
+// impactWeight assigns a relative footprint weight (0 to 1) to a spending category
+type impactWeight struct {
+	Category string  `json:"category"`
+	Weight   float64 `json:"weight"`
+}
+
+// defaultImpactWeights is an illustrative footprint scheme per category
+// Categories not listed use unlistedImpactWeight. Override per call with impact_weights.
+var defaultImpactWeights = map[string]float64{
+	"Transportation":    1.0,
+	"Shopping":          0.8,
+	"Food & Dining":     0.6,
+	"Bills & Utilities": 0.5,
+	"Entertainment":     0.2,
+}
+
+// unlistedImpactWeight applies to categories missing from the weight table
+const unlistedImpactWeight = 0.5
+
+// createSpendingImpactTool builds an opt-in tool that estimates a relative footprint of spending
+// A proxy only: it weights existing category totals, it doesn't measure emissions
+func createSpendingImpactTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("spending_impact").
+		Description("Opt-in, illustrative sustainability view: weights spending by category (e.g. Transportation and Shopping higher) to produce a relative impact score from 0 to 100 and the categories driving it. This is a proxy built on category totals, not a real carbon measurement; say so when presenting it. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days": tools.IntegerProperty(fmt.Sprintf("Number of days to analyze (default: %d)", defaultSpendingDays)),
+			"impact_weights": tools.ArrayProperty("Optional weight table replacing the defaults, e.g. [{\"category\": \"Food & Dining\", \"weight\": 0.9}]",
+				tools.ObjectSchema(map[string]interface{}{
+					"category": tools.StringProperty("Spending category"),
+					"weight":   tools.NumberProperty("Relative impact from 0 (none) to 1 (highest)"),
+				}, "category", "weight")),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days          int            `json:"days"`
+				ImpactWeights []impactWeight `json:"impact_weights"`
+				UseMock       bool           `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+
+			weights := defaultImpactWeights
+			if len(params.ImpactWeights) > 0 {
+				weights = make(map[string]float64, len(params.ImpactWeights))
+				for _, w := range params.ImpactWeights {
+					weights[w.Category] = math.Max(0, math.Min(1, w.Weight))
+				}
+			}
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for spending impact", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": time.Now().AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			result := scoreSpendingImpact(transactions, weights)
+			result["period_days"] = params.Days
+			result["disclaimer"] = "Illustrative only: a weighted view of spending categories, not a measured carbon footprint."
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = time.Now().Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// scoreSpendingImpact weights each category's spend and reports the 0-100 score and its drivers
+// 100 means every dollar went to categories with the maximum weight of 1
+func scoreSpendingImpact(transactions []map[string]interface{}, weights map[string]float64) map[string]interface{} {
+	byCategory := make(map[string]float64)
+	var totalSpent float64
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		if txType != "send" {
+			continue
+		}
+		amount, _ := tx["amount"].(float64)
+		description, _ := tx["description"].(string)
+		byCategory[categorizeTransaction(description)] += amount
+		totalSpent += amount
+	}
+	if totalSpent == 0 {
+		return map[string]interface{}{
+			"impact_score": 0,
+			"drivers":      []map[string]interface{}{},
+			"summary":      "No spending found in this period",
+		}
+	}
+
+	type driver struct {
+		category string
+		weighted float64
+	}
+	var weightedTotal float64
+	drivers := []driver{}
+	for category, amount := range byCategory {
+		weight, ok := weights[category]
+		if !ok {
+			weight = unlistedImpactWeight
+		}
+		drivers = append(drivers, driver{category: category, weighted: amount * weight})
+		weightedTotal += amount * weight
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		return drivers[i].weighted > drivers[j].weighted
+	})
+
+	score := math.Round(weightedTotal / totalSpent * 100)
+	driverList := []map[string]interface{}{}
+	for _, d := range drivers {
+		weight, ok := weights[d.category]
+		if !ok {
+			weight = unlistedImpactWeight
+		}
+		share := 0.0
+		if weightedTotal > 0 {
+			share = d.weighted / weightedTotal * 100
+		}
+		driverList = append(driverList, map[string]interface{}{
+			"category":        d.category,
+			"spend":           fmt.Sprintf("%.2f", byCategory[d.category]),
+			"weight":          weight,
+			"share_of_impact": fmt.Sprintf("%.1f%%", share),
+		})
+	}
+
+	summary := fmt.Sprintf("Relative impact score: %.0f/100", score)
+	if len(drivers) > 0 && weightedTotal > 0 {
+		summary += fmt.Sprintf(", driven mostly by %s", drivers[0].category)
+	}
+	return map[string]interface{}{
+		"impact_score": score,
+		"total_spent":  fmt.Sprintf("%.2f", totalSpent),
+		"drivers":      driverList,
+		"summary":      summary,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: HOUSEHOLD ANALYSIS
+// ============================================================================
+// Shared finances for couples and roommates. Access is opt-in: users must be
+// listed in the same household (HOUSEHOLDS="alice,bob;carol,dave") before one
+// can read the other's transactions. Each member's transactions are fetched
+// with that member's user ID, so the executor must hold credentials for them.
+// Session user IDs are token hashes (see sessionUserID), so a claimed "sub" never
+// matches a household entry; list the "session-..." IDs to grant access.
+
+// households maps each user ID to the set of user IDs in their household
+var households = map[string]map[string]bool{}
+
+// parseHouseholds parses "a,b;c,d" into a member lookup for each user
+func parseHouseholds(raw string) map[string]map[string]bool {
+	parsed := map[string]map[string]bool{}
+	for _, group := range strings.Split(raw, ";") {
+		members := map[string]bool{}
+		for _, id := range strings.Split(group, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				members[id] = true
+			}
+		}
+		for id := range members {
+			parsed[id] = members
+		}
+	}
+	return parsed
+}
+
+// canAccessUser reports whether caller may read target's transactions
+func canAccessUser(caller, target string) bool {
+	return caller == target || households[caller][target]
+}
+
+// createHouseholdTool builds a tool that analyzes the combined finances of several users
+func createHouseholdTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("analyze_household").
+		Description("Analyze shared household finances: merges the transactions of several users (who must be in the caller's household), tags each with its owner, and returns per-user totals plus combined spending and subscription analysis. Real data covers only the caller, since other members' transactions need their own credentials. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"user_ids": tools.ArrayProperty("User IDs to include; the caller is always included", tools.StringProperty("User ID")),
+			"days":     tools.IntegerProperty(fmt.Sprintf("Number of days to analyze (default: 90, max: %d)", maxMockDays)),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "user_ids")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				UserIDs []string `json:"user_ids"`
+				Days    int      `json:"days"`
+				UseMock bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			params.Days = min(params.Days, maxMockDays)
+
+			members := []string{toolParams.UserID}
+			for _, id := range params.UserIDs {
+				id = strings.TrimSpace(id)
+				if id == "" || containsString(members, id) {
+					continue
+				}
+				if !canAccessUser(toolParams.UserID, id) {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("not authorized to view %s's transactions; they must be in your household", id),
+					}, nil
+				}
+				members = append(members, id)
+			}
+
+			// The Liminal executor authenticates with the caller's JWT only, so a
+			// real fetch for anyone else would just return the caller's data again
+			if !params.UseMock && len(members) > 1 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "other household members' transactions can't be fetched with your credentials; use_mock is required for more than one member",
+				}, nil
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -params.Days)
+
+			var combined []map[string]interface{}
+			perUser := []map[string]interface{}{}
+			for i, member := range members {
+				var transactions []map[string]interface{}
+				if params.UseMock {
+					// Offset the seed so each member gets different mock data
+					opts := mockOptions{Seed: now.UnixNano() + int64(i)}
+					transactions = append(generateMockTransactionsForAnalysis(params.Days, opts),
+						generateMockSubscriptionTransactions(max(1, params.Days/30), opts)...)
+				} else {
+					var err error
+					transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+						"limit":      500,
+						"start_date": cutoffDate.Format("2006-01-02"),
+					})
+					if err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+
+				var spent, received float64
+				for _, tx := range transactions {
+					tx["user_id"] = member
+					amount, _ := tx["amount"].(float64)
+					switch tx["type"] {
+					case "send":
+						spent += amount
+					case "receive":
+						received += amount
+					}
+				}
+				combined = append(combined, transactions...)
+				perUser = append(perUser, map[string]interface{}{
+					"user_id":        member,
+					"transactions":   len(transactions),
+					"total_spent":    fmt.Sprintf("%.2f", spent),
+					"total_received": fmt.Sprintf("%.2f", received),
+				})
+			}
+			log.Printf("🏠 Merged %d transactions across %d household members", len(combined), len(members))
+
+			parsed, _ := parseTransactions(combined)
+			subscriptions := analyzeForSubscriptions(combined, cutoffDate, 1.00, 999.99)
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"members":  members,
+					"per_user": perUser,
+					"combined": map[string]interface{}{
+						"spending":                analyzeTransactions(parsed, params.Days, nil, defaultLanguage, false, 0),
+						"subscriptions":           formatSubscriptions(subscriptions, false),
+						"subscription_total_cost": calculateTotalMonthlyCost(subscriptions),
+					},
+					"period_days":  params.Days,
+					"data_source":  map[string]bool{"is_mock": params.UseMock},
+					"generated_at": now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: DEPOSIT SCHEDULE
+// ============================================================================
+
+// createDepositScheduleTool builds a tool that plans automatic savings deposits around paydays
+// Deposits land right after each payday, sized so the projected balance never dips below the cushion
+func createDepositScheduleTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("optimize_deposit_schedule").
+		Description("Propose a savings deposit schedule: the best days to move money to savings (right after each payday, before discretionary spending) and how much, so the projected balance never drops below a cushion. Uses detected recurring income, predicted bills and average daily spending. Returns the schedule, deposit_savings payloads to confirm, and projected monthly savings. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"horizon_days":    tools.IntegerProperty("Days ahead to plan for (default: 30)"),
+			"cushion":         tools.NumberProperty("Minimum balance to keep at all times (default: 200)"),
+			"current_balance": tools.NumberProperty("Override the wallet balance instead of fetching it"),
+			"currency":        tools.StringProperty("Currency of the balance and deposits (default: USD)"),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				HorizonDays    int      `json:"horizon_days"`
+				Cushion        *float64 `json:"cushion"`
+				CurrentBalance *float64 `json:"current_balance"`
+				Currency       string   `json:"currency"`
+				UseMock        bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.HorizonDays <= 0 {
+				params.HorizonDays = 30
+			}
+			cushion := 200.0
+			if params.Cushion != nil {
+				cushion = *params.Cushion
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			const historyDays = 90
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -historyDays)
+
+			var transactions []map[string]interface{}
+			balance := mockWalletBalance
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(3, mockOptions{}),
+					generateMockPayrollTransactions(historyDays, mockOptions{})...)
+				// Only the everyday spending; the payroll series above stands in for income
+				for _, tx := range generateMockTransactionsForAnalysis(historyDays, mockOptions{}) {
+					if tx["type"] == "send" {
+						transactions = append(transactions, tx)
+					}
+				}
+				log.Printf("📊 Generated %d mock transactions for deposit schedule", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if params.CurrentBalance == nil {
+					if balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency); err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+			}
+			if params.CurrentBalance != nil {
+				balance = *params.CurrentBalance
+			}
+
+			events := predictCashEvents(transactions, cutoffDate, now, params.HorizonDays)
+			dailySpend := averageDailyDiscretionary(transactions, cutoffDate, historyDays)
+			balances := projectDailyBalances(now, balance, events, dailySpend, params.HorizonDays)
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+			schedule := []map[string]interface{}{}
+			deposits := []map[string]interface{}{}
+			var scheduled float64
+			for _, event := range events {
+				if event.Kind != "income" {
+					continue
+				}
+				day := int(event.Date.Sub(today).Hours() / 24)
+				if day < 0 || day >= len(balances) {
+					continue
+				}
+				// Every later balance drops by whatever is deposited, so size the
+				// deposit by the lowest point from payday onward
+				lowest := balances[day]
+				for _, b := range balances[day:] {
+					lowest = math.Min(lowest, b)
+				}
+				// Round down to $5 so deposits stay tidy and leave a little slack
+				amount := math.Floor((lowest-cushion-scheduled)/5) * 5
+				if amount <= 0 {
+					continue
+				}
+				scheduled += amount
+				date := event.Date.Format("2006-01-02")
+				schedule = append(schedule, map[string]interface{}{
+					"date":   date,
+					"after":  event.Description,
+					"amount": fmt.Sprintf("%.2f", amount),
+				})
+				deposits = append(deposits, map[string]interface{}{
+					"date": date,
+					"tool": "deposit_savings",
+					"input": map[string]interface{}{
+						"amount":   fmt.Sprintf("%.2f", amount),
+						"currency": params.Currency,
+					},
+				})
+			}
+
+			monthly := scheduled / float64(params.HorizonDays) * daysPerMonth
+			result := map[string]interface{}{
+				"schedule":                  schedule,
+				"deposit_payloads":          deposits,
+				"total_scheduled":           fmt.Sprintf("%.2f", scheduled),
+				"projected_monthly_savings": fmt.Sprintf("%.2f", monthly),
+				"current_balance":           fmt.Sprintf("%.2f", balance),
+				"avg_daily_spend":           fmt.Sprintf("%.2f", dailySpend),
+				"cushion":                   fmt.Sprintf("%.2f", cushion),
+				"horizon_days":              params.HorizonDays,
+				"data_source":               map[string]bool{"is_mock": params.UseMock},
+				"generated_at":              now.Format(time.RFC3339),
+			}
+			switch {
+			case len(schedule) > 0:
+				result["summary"] = fmt.Sprintf("Deposit $%.2f across %d paydays in the next %d days (about $%.2f/month) while keeping at least $%.2f in your wallet.",
+					scheduled, len(schedule), params.HorizonDays, monthly, cushion)
+			case len(events) == 0:
+				result["summary"] = "No regular income detected, so there are no paydays to schedule deposits after."
+			default:
+				result["summary"] = fmt.Sprintf("Bills and spending leave no room above the $%.2f cushion after upcoming paydays; no deposits scheduled.", cushion)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: LOW BALANCE WARNING
+// ============================================================================
+
+// createLowBalanceWarningTool builds a tool that warns when the balance won't cover upcoming bills
+// The proactive "rent is due and you're short $40" alert
+func createLowBalanceWarningTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("check_low_balance").
+		Description("Check whether the current balance covers bills predicted in the next few days plus a cushion. Returns a severity (ok, watch, critical), the shortfall, and which upcoming bills are at risk. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"horizon_days":    tools.IntegerProperty("Days ahead to look for bills (default: 7)"),
+			"cushion":         tools.NumberProperty("Buffer to keep on top of bills (default: 100)"),
+			"current_balance": tools.NumberProperty("Override the wallet balance instead of fetching it"),
+			"currency":        tools.StringProperty("Currency of the balance (default: USD)"),
+			"proactive":       proactiveProperty(),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				HorizonDays    int      `json:"horizon_days"`
+				Cushion        *float64 `json:"cushion"`
+				CurrentBalance *float64 `json:"current_balance"`
+				Currency       string   `json:"currency"`
+				Proactive      bool     `json:"proactive"`
+				UseMock        bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.HorizonDays <= 0 {
+				params.HorizonDays = 7
+			}
+			cushion := 100.0
+			if params.Cushion != nil {
+				cushion = *params.Cushion
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			const historyDays = 90
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -historyDays)
+
+			var transactions []map[string]interface{}
+			balance := mockWalletBalance
+			if params.UseMock {
+				transactions = generateMockSubscriptionTransactions(3, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for low balance check", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if params.CurrentBalance == nil {
+					if balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency); err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+			}
+			if params.CurrentBalance != nil {
+				balance = *params.CurrentBalance
+			}
+
+			// Walk bills in date order; any bill that takes the balance below the cushion is at risk
+			var billsTotal float64
+			running := balance
+			upcoming := []map[string]interface{}{}
+			atRisk := []map[string]interface{}{}
+			for _, event := range predictCashEvents(transactions, cutoffDate, now, params.HorizonDays) {
+				if event.Kind != "bill" {
+					continue
+				}
+				amount := -event.Amount
+				billsTotal += amount
+				running -= amount
+				bill := map[string]interface{}{
+					"date":              event.Date.Format("2006-01-02"),
+					"description":       event.Description,
+					"amount":            fmt.Sprintf("%.2f", amount),
+					"balance_after_due": fmt.Sprintf("%.2f", running),
+				}
+				upcoming = append(upcoming, bill)
+				if running < cushion {
+					atRisk = append(atRisk, bill)
+				}
+			}
+
+			severity := "ok"
+			shortfall := 0.0
+			switch {
+			case balance < billsTotal:
+				severity = "critical"
+				shortfall = billsTotal - balance
+			case balance < billsTotal+cushion:
+				severity = "watch"
+				shortfall = billsTotal + cushion - balance
+			}
+
+			var message string
+			switch severity {
+			case "critical":
+				message = fmt.Sprintf("Heads up: $%.2f in bills are due in the next %d days and you're short $%.2f.", billsTotal, params.HorizonDays, shortfall)
+			case "watch":
+				message = fmt.Sprintf("Your balance covers the $%.2f in bills due in the next %d days, but leaves less than your $%.2f cushion.", billsTotal, params.HorizonDays, cushion)
+			default:
+				message = fmt.Sprintf("You're fine: $%.2f covers the $%.2f in bills due in the next %d days with room to spare.", balance, billsTotal, params.HorizonDays)
+			}
+
+			result := map[string]interface{}{
+				"severity":        severity,
+				"shortfall":       fmt.Sprintf("%.2f", shortfall),
+				"current_balance": fmt.Sprintf("%.2f", balance),
+				"bills_due":       fmt.Sprintf("%.2f", billsTotal),
+				"cushion":         fmt.Sprintf("%.2f", cushion),
+				"upcoming_bills":  upcoming,
+				"at_risk_bills":   atRisk,
+				"message":         message,
+				"horizon_days":    params.HorizonDays,
+				"data_source":     map[string]bool{"is_mock": params.UseMock},
+				"generated_at":    now.Format(time.RFC3339),
+			}
+			if pending := flushPendingAlerts(toolParams.UserID, now); len(pending) > 0 {
+				result["pending_alerts"] = pending
+			}
+			if severity != "ok" {
+				alert := pendingAlert{Tool: "check_low_balance", Severity: severity, Message: message, RaisedAt: now.Format(time.RFC3339)}
+				if holdForQuietHours(toolParams.UserID, params.Proactive, alert, now) {
+					return quietHoursResult(now), nil
+				}
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: VAULT RATE TREND
+// ============================================================================
+
+// ratePoint is one historical APY observation
+type ratePoint struct {
+	Date string  `json:"date"`
+	APY  float64 `json:"apy"`
+}
+
+// createRateTrendTool builds a tool that compares the current APY with past rates
+// get_vault_rates only returns today's rate, so history is passed in as historical_rates
+func createRateTrendTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("vault_rate_trend").
+		Description("Compare the current savings APY against historical rates. Reports whether rates are rising or falling, the change over the period, and whether now is a relatively good time to deposit. The Liminal API only exposes the current rate, so pass past rates in historical_rates. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"historical_rates": tools.ArrayProperty("Past rates in any order, e.g. [{\"date\": \"2026-01-01\", \"apy\": 4.8}]",
+				tools.ObjectSchema(map[string]interface{}{
+					"date": tools.StringProperty("Date of the observation (YYYY-MM-DD)"),
+					"apy":  tools.NumberProperty("APY as a percentage, e.g. 4.5"),
+				}, "date", "apy")),
+			"current_apy": tools.NumberProperty("Override the current APY instead of fetching it"),
+			"use_mock":    tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				HistoricalRates []ratePoint `json:"historical_rates"`
+				CurrentAPY      *float64    `json:"current_apy"`
+				UseMock         bool        `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+
+			now := time.Now()
+			current := mockVaultAPY
+			history := params.HistoricalRates
+			if params.UseMock {
+				if len(history) == 0 {
+					history = generateMockRateHistory(now, 6)
+				}
+			} else if params.CurrentAPY == nil {
+				var err error
+				if current, err = fetchVaultAPY(ctx, liminalExecutor, toolParams); err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+			if params.CurrentAPY != nil {
+				current = *params.CurrentAPY
+			}
+			if len(history) == 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "no rate history available: pass historical_rates to compare against",
+				}, nil
+			}
+
+			trend, err := rateTrend(history, current)
+			if err != nil {
+				return toolErrorResult(err), nil
+			}
+			trend["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			trend["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    trend,
+			}, nil
+		}).
+		Build()
+}
+
+// rateTrend compares current against the oldest and average of history
+// A move of less than 0.05 points counts as flat
+func rateTrend(history []ratePoint, current float64) (map[string]interface{}, error) {
+	oldest := 0
+	var oldestDate time.Time
+	var sum, high, low float64
+	low = math.Inf(1)
+	for i, point := range history {
+		date, err := time.Parse("2006-01-02", point.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in historical_rates", point.Date)
+		}
+		if i == 0 || date.Before(oldestDate) {
+			oldest, oldestDate = i, date
+		}
+		sum += point.APY
+		high = math.Max(high, point.APY)
+		low = math.Min(low, point.APY)
+	}
+	average := sum / float64(len(history))
+	first := history[oldest]
+	change := current - first.APY
+
+	direction := "flat"
+	if change >= 0.05 {
+		direction = "rising"
+	} else if change <= -0.05 {
+		direction = "falling"
+	}
+
+	goodTime := current >= average
+	var note string
+	switch {
+	case direction == "falling" && goodTime:
+		note = fmt.Sprintf("Rates have slipped from %.2f%% since %s, but %.2f%% is still above the %.2f%% average. Depositing now locks in a better-than-usual rate.", first.APY, first.Date, current, average)
+	case direction == "falling":
+		note = fmt.Sprintf("Rates have fallen from %.2f%% since %s to %.2f%%, below the %.2f%% average. Savings still earn interest, just less than before.", first.APY, first.Date, current, average)
+	case direction == "rising" && goodTime:
+		note = fmt.Sprintf("Rates have climbed from %.2f%% since %s to %.2f%%, above the %.2f%% average. A good time to deposit.", first.APY, first.Date, current, average)
+	case direction == "rising":
+		note = fmt.Sprintf("Rates are up from %.2f%% since %s but %.2f%% is still below the %.2f%% average.", first.APY, first.Date, current, average)
+	default:
+		note = fmt.Sprintf("Rates have held steady around %.2f%% since %s.", current, first.Date)
+	}
+
+	return map[string]interface{}{
+		"current_apy":       current,
+		"direction":         direction,
+		"change_points":     fmt.Sprintf("%+.2f", change),
+		"since":             first.Date,
+		"average_apy":       fmt.Sprintf("%.2f", average),
+		"high_apy":          high,
+		"low_apy":           low,
+		"observations":      len(history),
+		"good_time_to_save": goodTime,
+		"note":              note,
+	}, nil
+}
+
+// generateMockRateHistory returns one monthly APY point for each of the past months, drifting down toward mockVaultAPY
+func generateMockRateHistory(now time.Time, months int) []ratePoint {
+	history := make([]ratePoint, 0, months)
+	for i := months; i >= 1; i-- {
+		history = append(history, ratePoint{
+			Date: now.AddDate(0, -i, 0).Format("2006-01-02"),
+			APY:  math.Round((mockVaultAPY+0.1*float64(i)-0.25)*100) / 100,
+		})
+	}
+	return history
+}
+
+// ============================================================================
+// CUSTOM TOOL: CATEGORY FORECAST
+// ============================================================================
+
+// createCategoryForecastTool builds a tool that projects next month's spend per category
+// Turns this period's daily rate per category into a suggested budget
+func createCategoryForecastTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("forecast_by_category").
+		Description("Project next month's spending per category from the spending rate over the last N days, with a projected total. Each category carries a confidence note based on how many transactions back it. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":             tools.IntegerProperty("Days of history to base the forecast on (default: 90)"),
+			"category_weights": categoryWeightsProperty(),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days            int              `json:"days"`
+				CategoryWeights []categoryWeight `json:"category_weights"`
+				UseMock         bool             `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for category forecast", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in category forecast: %v", err)
+			}
+			forecast := forecastByCategory(parsed, params.Days, params.CategoryWeights)
+			forecast["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			forecast["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    forecast,
+			}, nil
+		}).
+		Build()
+}
+
+// forecastByCategory scales each category's daily rate over days to a 30-day month
+// Confidence: high with 8+ transactions, medium with 3+, low otherwise
+func forecastByCategory(transactions []Transaction, days int, weights []categoryWeight) map[string]interface{} {
+	amounts, counts := spendByCategory(transactions, weights)
+
+	names := make([]string, 0, len(amounts))
+	for name := range amounts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return amounts[names[i]] > amounts[names[j]] })
+
+	var total float64
+	categories := []map[string]interface{}{}
+	for _, name := range names {
+		projected := amounts[name] / float64(days) * 30
+		total += projected
+
+		confidence := "low"
+		note := fmt.Sprintf("Only %d transaction(s) in %d days; treat this as a rough guess", counts[name], days)
+		switch {
+		case counts[name] >= 8:
+			confidence = "high"
+			note = fmt.Sprintf("Based on %d transactions over %d days", counts[name], days)
+		case counts[name] >= 3:
+			confidence = "medium"
+			note = fmt.Sprintf("Based on %d transactions; one unusual purchase could swing this", counts[name])
+		}
+
+		categories = append(categories, map[string]interface{}{
+			"category":        name,
+			"projected":       fmt.Sprintf("%.2f", projected),
+			"spent_in_period": fmt.Sprintf("%.2f", amounts[name]),
+			"transactions":    counts[name],
+			"confidence":      confidence,
+			"confidence_note": note,
+		})
+	}
+
+	summary := "No spending found to forecast from"
+	if len(names) > 0 {
+		summary = fmt.Sprintf("Based on your patterns you'll likely spend ~$%.0f next month, with ~$%.0f on %s", total, amounts[names[0]]/float64(days)*30, names[0])
+	}
+
+	return map[string]interface{}{
+		"based_on_days":   days,
+		"projected_total": fmt.Sprintf("%.2f", total),
+		"categories":      categories,
+		"summary":         summary,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: MICRO-SUBSCRIPTIONS
+// ============================================================================
+
+// createMicroSubscriptionTool builds a tool that groups the small subscriptions nobody notices
+// Reframes a pile of $3 charges as one yearly number
+func createMicroSubscriptionTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("find_micro_subscriptions").
+		Description("Find small subscriptions costing less than a threshold per month and total them as a group, e.g. 'these small charges total $X/year'. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"micro_threshold":  tools.NumberProperty("Monthly cost below which a subscription counts as micro (default: 6)"),
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Number of months to scan (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				MicroThreshold  float64 `json:"micro_threshold"`
+				TimeframeMonths int     `json:"timeframe_months"`
+				UseMock         bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.MicroThreshold <= 0 {
+				params.MicroThreshold = 6
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{})
+				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, 0.01, 999.99)
+			micro, monthlyTotal := findMicroSubscriptions(subscriptions, params.MicroThreshold)
+
+			message := fmt.Sprintf("No subscriptions under $%.2f/month found.", params.MicroThreshold)
+			if len(micro) > 0 {
+				message = fmt.Sprintf("These %d small charges total $%.2f/month, or $%.2f/year.", len(micro), monthlyTotal, monthlyTotal*12)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"micro_threshold":     params.MicroThreshold,
+					"micro_subscriptions": micro,
+					"count":               len(micro),
+					"total_monthly":       fmt.Sprintf("%.2f", monthlyTotal),
+					"total_yearly":        fmt.Sprintf("%.2f", monthlyTotal*12),
+					"message":             message,
+					"data_source":         map[string]bool{"is_mock": params.UseMock},
+					"generated_at":        now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// findMicroSubscriptions returns subscriptions cheaper than threshold per month and their combined monthly cost
+// Irregular ones have no monthly equivalent and are left out
+func findMicroSubscriptions(subscriptions []map[string]interface{}, threshold float64) ([]map[string]interface{}, float64) {
+	micro := []map[string]interface{}{}
+	var total float64
+	for _, sub := range subscriptions {
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		merchant, _ := sub["merchant"].(string)
+		monthly := monthlyEquivalent(amount, frequency)
+		if monthly <= 0 || monthly >= threshold {
+			continue
+		}
+		total += monthly
+		micro = append(micro, map[string]interface{}{
+			"merchant":     merchant,
+			"amount":       amount,
+			"frequency":    frequency,
+			"monthly_cost": math.Round(monthly*100) / 100,
+			"yearly_cost":  math.Round(monthly*12*100) / 100,
+		})
+	}
+	sort.Slice(micro, func(i, j int) bool {
+		return micro[i]["monthly_cost"].(float64) > micro[j]["monthly_cost"].(float64)
+	})
+	return micro, total
+}
+
+// ============================================================================
+// CUSTOM TOOL: SUBSCRIPTION OFFSET
+// ============================================================================
+
+// createSubscriptionOffsetTool builds a tool that works out the savings needed for interest to cover subscriptions
+// A memorable target: "save $X and your subscriptions pay for themselves"
+func createSubscriptionOffsetTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("offset_subscriptions").
+		Description("Calculate how much the user would need in savings, at the current vault APY, for the monthly interest to cover their total subscription cost. Returns the principal required and how far current savings are from it. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Number of months to scan for subscriptions (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"currency":         tools.StringProperty("Currency of the savings balance (default: USD)"),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				TimeframeMonths int    `json:"timeframe_months"`
+				Currency        string `json:"currency"`
+				UseMock         bool   `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+
+			var transactions []map[string]interface{}
+			apy, savings := mockVaultAPY, mockSavingsBalance
+			if params.UseMock {
+				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{})
+				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err == nil {
+					apy, err = fetchVaultAPY(ctx, liminalExecutor, toolParams)
+				}
+				if err == nil {
+					savings, err = fetchSavingsBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99)
+			monthlyCost := calculateTotalMonthlyCost(subscriptions)
+
+			// Monthly interest = principal × APY / 12, solved for principal
+			required := 0.0
+			if apy > 0 {
+				required = monthlyCost * 12 / (apy / 100)
+			}
+			remaining := math.Max(required-savings, 0)
+			progress := 100.0
+			if required > 0 {
+				progress = math.Min(savings/required*100, 100)
+			}
+
+			var message string
+			switch {
+			case monthlyCost == 0:
+				message = "No subscriptions detected, so there's nothing for your savings to cover."
+			case remaining == 0:
+				message = fmt.Sprintf("Your $%.2f in savings already earns enough at %.2f%% APY to cover your $%.2f/month in subscriptions!", savings, apy, monthlyCost)
+			default:
+				message = fmt.Sprintf("You'd need $%.2f saved at %.2f%% APY for the interest to pay your $%.2f/month in subscriptions. You're %.0f%% of the way there, $%.2f to go.", required, apy, monthlyCost, progress, remaining)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"monthly_subscription_cost": fmt.Sprintf("%.2f", monthlyCost),
+					"subscriptions_found":       len(subscriptions),
+					"apy_used":                  apy,
+					"principal_required":        fmt.Sprintf("%.2f", required),
+					"current_savings":           fmt.Sprintf("%.2f", savings),
+					"remaining":                 fmt.Sprintf("%.2f", remaining),
+					"progress_percent":          fmt.Sprintf("%.1f", progress),
+					"monthly_interest_now":      fmt.Sprintf("%.2f", savings*apy/100/12),
+					"currency":                  params.Currency,
+					"message":                   message,
+					"data_source":               map[string]bool{"is_mock": params.UseMock},
+					"generated_at":              now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// ADMIN ENDPOINT
+// ============================================================================
+// Clears in-memory state between test runs without restarting the server.
+// Requires "Authorization: Bearer $ADMIN_TOKEN".
+
+// adminResetHandler serves POST /admin/reset
+// Body: {"user_id": "..."} clears one user, {"user_token": "<jwt>"} clears whoever
+// that JWT logs in as, {"all_users": true} clears everyone, an empty body only
+// flushes caches. user_id is the ID from sessionUserID (the JWT's sub claim), or
+// "anonymous" for connections without a JWT.
+func adminResetHandler(token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var body struct {
+			UserID    string `json:"user_id"`
+			UserToken string `json:"user_token"`
+			AllUsers  bool   `json:"all_users"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, fmt.Sprintf("invalid JSON body: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		selectors := 0
+		for _, set := range []bool{body.UserID != "", body.UserToken != "", body.AllUsers} {
+			if set {
+				selectors++
+			}
+		}
+		if selectors > 1 {
+			http.Error(w, "send only one of user_id, user_token or all_users", http.StatusBadRequest)
+			return
+		}
+		// Resolve the token the same way liminalAuthFunc does, so it matches the store key
+		if body.UserToken != "" {
+			body.UserID = sessionUserID(body.UserToken)
+		}
+
+		// No transaction cache exists yet; caches added later should be flushed here
+		cleared := map[string]interface{}{
+			"transaction_cache": "not enabled",
+		}
+		switch {
+		case body.AllUsers:
+			cleared["users"] = users.reset("")
+		case body.UserID != "":
+			cleared["users"] = users.reset(body.UserID)
+		default:
+			cleared["users"] = []string{}
+		}
+		log.Printf("🧹 Admin reset: %v", cleared)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cleared":    cleared,
+			"cleared_at": time.Now().Format(time.RFC3339),
+		})
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: CATEGORY VOLATILITY
+// ============================================================================
+
+// createCategoryVolatilityTool builds a tool that ranks categories by how much their monthly spend swings
+// Shows where budgeting discipline matters most
+func createCategoryVolatilityTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("category_volatility").
+		Description("Rank spending categories by volatility, using the coefficient of variation (standard deviation ÷ average) of monthly spend. Steady categories like bills rank low; erratic ones like shopping rank high. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"months":           tools.IntegerProperty("Number of 30-day months to compare, at least 2 (default: 6)"),
+			"category_weights": categoryWeightsProperty(),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Months          int              `json:"months"`
+				CategoryWeights []categoryWeight `json:"category_weights"`
+				UseMock         bool             `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Months < 2 {
+				params.Months = 6
+			}
+
+			now := time.Now()
+			days := params.Months * 30
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for volatility ranking", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in volatility ranking: %v", err)
+			}
+			ranking := rankCategoryVolatility(monthlyCategorySpend(parsed, params.CategoryWeights, params.Months, now))
+
+			summary := "Not enough spending history to compare months"
+			if len(ranking) > 0 {
+				summary = fmt.Sprintf("Your %s spend is the most unpredictable", ranking[0]["category"])
+				if len(ranking) > 1 {
+					summary += fmt.Sprintf("; %s is the steadiest", ranking[len(ranking)-1]["category"])
+				}
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"months":              params.Months,
+					"category_volatility": ranking,
+					"summary":             summary,
+					"data_source":         map[string]bool{"is_mock": params.UseMock},
+					"generated_at":        now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// rankCategoryVolatility scores each category's monthly spend by coefficient of variation, most volatile first
+// Under 0.25 is steady, under 0.6 moderate, anything higher erratic
+func rankCategoryVolatility(monthly map[string][]float64) []map[string]interface{} {
+	type volatility struct {
+		category string
+		average  float64
+		stdDev   float64
+		cv       float64
+	}
+	scored := []volatility{}
+	for category, amounts := range monthly {
+		var sum float64
+		for _, amount := range amounts {
+			sum += amount
+		}
+		average := sum / float64(len(amounts))
+		if average <= 0 {
+			continue
+		}
+		var variance float64
+		for _, amount := range amounts {
+			variance += (amount - average) * (amount - average)
+		}
+		stdDev := math.Sqrt(variance / float64(len(amounts)))
+		scored = append(scored, volatility{category, average, stdDev, stdDev / average})
+	}
+	sort.Slice(scored, func(i, j int) bool { return scored[i].cv > scored[j].cv })
+
+	ranking := []map[string]interface{}{}
+	for i, v := range scored {
+		level := "erratic"
+		if v.cv < 0.25 {
+			level = "steady"
+		} else if v.cv < 0.6 {
+			level = "moderate"
+		}
+		ranking = append(ranking, map[string]interface{}{
+			"rank":                     i + 1,
+			"category":                 v.category,
+			"coefficient_of_variation": math.Round(v.cv*100) / 100,
+			"monthly_average":          fmt.Sprintf("%.2f", v.average),
+			"monthly_std_dev":          fmt.Sprintf("%.2f", v.stdDev),
+			"level":                    level,
+		})
+	}
+	return ranking
+}
+
+// ============================================================================
+// CUSTOM TOOL: PRICE INCREASE IMPACT
+// ============================================================================
+
+// createPriceIncreaseImpactTool builds a tool that projects what a subscription price rise costs over the years
+// "$2 more a month" sounds small; "$120 over 5 years" doesn't
+func createPriceIncreaseImpactTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("price_increase_impact").
+		Description("Project the extra cost of a subscription price increase: the annual difference, the cumulative cost over several years, and what that money could grow to in savings at the vault APY. Pass old_price and new_price, or a merchant to detect the change from payment history (mock data by default).").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"old_price": tools.NumberProperty("Price before the increase, per billing period"),
+			"new_price": tools.NumberProperty("Price after the increase, per billing period"),
+			"merchant":  tools.StringProperty("Subscription to look up in payment history when prices aren't given, e.g. 'Netflix'"),
+			"frequency": tools.StringEnumProperty("Billing frequency (default: monthly)", "daily", "weekly", "biweekly", "semi-monthly", "monthly", "quarterly", "semi-annual", "annual"),
+			"years":     tools.IntegerProperty("Years to project (default: 5)"),
+			"use_mock":  tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				OldPrice  float64 `json:"old_price"`
+				NewPrice  float64 `json:"new_price"`
+				Merchant  string  `json:"merchant"`
+				Frequency string  `json:"frequency"`
+				Years     int     `json:"years"`
+				UseMock   bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.Frequency == "" {
+				params.Frequency = "monthly"
+			}
+			if params.Years <= 0 {
+				params.Years = 5
+			}
+
+			now := time.Now()
+			apy := mockVaultAPY
+			source := "provided"
+			if params.OldPrice <= 0 || params.NewPrice <= 0 {
+				if params.Merchant == "" {
+					return &core.ToolResult{
+						Success: false,
+						Error:   "provide old_price and new_price, or a merchant to look up",
+					}, nil
+				}
+				cutoffDate := now.AddDate(-1, 0, 0)
+				var transactions []map[string]interface{}
+				if params.UseMock {
+					transactions = generateMockSubscriptionTransactions(12, mockOptions{})
+				} else {
+					var err error
+					transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+						"limit":      500,
+						"start_date": cutoffDate.Format("2006-01-02"),
+					})
+					if err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+				oldPrice, newPrice, found := detectPriceChange(transactions, params.Merchant, cutoffDate)
+				if !found {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("no price increase found for %q in the last 12 months; pass old_price and new_price instead", params.Merchant),
+					}, nil
+				}
+				params.OldPrice, params.NewPrice = oldPrice, newPrice
+				source = "payment_history"
+			}
+			if !params.UseMock {
+				if liveAPY, err := fetchVaultAPY(ctx, liminalExecutor, toolParams); err == nil {
+					apy = liveAPY
+				}
+			}
+
+			monthlyDelta := monthlyEquivalent(params.NewPrice-params.OldPrice, params.Frequency)
+			annualDelta := monthlyDelta * 12
+			cumulative := annualDelta * float64(params.Years)
+			invested := futureValueOfMonthly(monthlyDelta, apy, params.Years)
+
+			label := params.Merchant
+			if label == "" {
+				label = "This subscription"
+			}
+			message := fmt.Sprintf("%s went from $%.2f to $%.2f. That's $%.2f more a year, or $%.2f over %d years. Saved instead at %.2f%% APY it would grow to $%.2f.",
+				label, params.OldPrice, params.NewPrice, annualDelta, cumulative, params.Years, apy, invested)
+			if monthlyDelta <= 0 {
+				message = fmt.Sprintf("%s didn't go up: $%.2f to $%.2f.", label, params.OldPrice, params.NewPrice)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"old_price":           fmt.Sprintf("%.2f", params.OldPrice),
+					"new_price":           fmt.Sprintf("%.2f", params.NewPrice),
+					"frequency":           params.Frequency,
+					"increase_percent":    fmt.Sprintf("%.1f", (params.NewPrice-params.OldPrice)/params.OldPrice*100),
+					"annual_increase":     fmt.Sprintf("%.2f", annualDelta),
+					"cumulative_increase": fmt.Sprintf("%.2f", cumulative),
+					"years":               params.Years,
+					"invested_value":      fmt.Sprintf("%.2f", invested),
+					"apy_used":            apy,
+					"price_source":        source,
+					"message":             message,
+					"data_source":         map[string]bool{"is_mock": params.UseMock && source == "payment_history"},
+					"generated_at":        now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// detectPriceChange compares the first and latest payment to a merchant since cutoff
+// Reports found only when the latest is more than 2% above the first, so normal jitter isn't a price rise
+func detectPriceChange(transactions []map[string]interface{}, merchant string, cutoff time.Time) (float64, float64, bool) {
+	first, last, _, found := detectPriceChangeSpan(transactions, merchant, cutoff)
+	return first, last, found
+}
+
+// detectPriceChangeSpan is detectPriceChange plus the time between the two payments compared
+func detectPriceChangeSpan(transactions []map[string]interface{}, merchant string, cutoff time.Time) (float64, float64, time.Duration, bool) {
+	needle := strings.ToLower(merchant)
+	var firstDate, lastDate time.Time
+	var first, last float64
+	parsed, _ := parseTransactions(transactions)
+	for _, tx := range parsed {
+		label := tx.Description
+		if strings.TrimSpace(label) == "" {
+			label = unknownMerchant
+		}
+		if tx.Type != "send" || tx.Date.Before(cutoff) || !strings.Contains(strings.ToLower(label), needle) {
+			continue
+		}
+		if firstDate.IsZero() || tx.Date.Before(firstDate) {
+			firstDate, first = tx.Date, tx.Amount
+		}
+		if lastDate.IsZero() || tx.Date.After(lastDate) {
+			lastDate, last = tx.Date, tx.Amount
+		}
+	}
+	if first == 0 || last <= first*1.02 {
+		return 0, 0, 0, false
+	}
+	return first, last, lastDate.Sub(firstDate), true
+}
+
+// ============================================================================
+// CUSTOM TOOL: PAYDAY SPENDING
+// ============================================================================
+
+// createPaydaySpendingTool builds a tool that measures how much spending bunches up right after payday
+// A behavioral coaching hook: "40% of your spend happens in the 3 days after payday"
+func createPaydaySpendingTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("payday_spending").
+		Description("Correlate spending with detected paydays: the share of spending that happens within N days after each payday, and how many days after payday the average dollar is spent. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":        tools.IntegerProperty("Number of days of history to analyze (default: 90)"),
+			"window_days": tools.IntegerProperty("Days after payday that count as post-payday spending (default: 3)"),
+			"use_mock":    tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days       int  `json:"days"`
+				WindowDays int  `json:"window_days"`
+				UseMock    bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			if params.WindowDays <= 0 {
+				params.WindowDays = 3
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -params.Days)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				// Only the sends from the analysis mock; payroll comes from its own regular generator
+				for _, tx := range generateMockTransactionsForAnalysis(params.Days, mockOptions{}) {
+					if tx["type"] == "send" {
+						transactions = append(transactions, tx)
+					}
+				}
+				transactions = append(transactions, generateMockPayrollTransactions(params.Days, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for payday spending", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			result := analyzePaydaySpending(transactions, cutoffDate, params.WindowDays)
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// analyzePaydaySpending attributes each send to the most recent payday before it
+// Paydays come from regular recurring income; spending before the first payday is left out
+func analyzePaydaySpending(transactions []map[string]interface{}, cutoffDate time.Time, windowDays int) map[string]interface{} {
+	var paydays []time.Time
+	for _, pattern := range detectRecurring(transactions, RecurringOpts{Type: "receive", Cutoff: cutoffDate}) {
+		if pattern.Frequency != "irregular" {
+			paydays = append(paydays, pattern.Dates...)
+		}
+	}
+	if len(paydays) == 0 {
+		return map[string]interface{}{
+			"paydays_found": 0,
+			"summary":       "No regular paydays detected, so spending can't be lined up against them",
+		}
+	}
+	sort.Slice(paydays, func(i, j int) bool { return paydays[i].Before(paydays[j]) })
+
+	var attributed, postPayday, weightedDays float64
+	parsed, _ := parseTransactions(transactions)
+	for _, tx := range parsed {
+		if tx.Type != "send" || tx.Date.Before(cutoffDate) {
+			continue
+		}
+
+		// Most recent payday on or before the purchase
+		i := sort.Search(len(paydays), func(i int) bool { return paydays[i].After(tx.Date) }) - 1
+		if i < 0 {
+			continue
+		}
+		daysAfter := int(tx.Date.Sub(paydays[i]).Hours() / 24)
+		attributed += tx.Amount
+		weightedDays += tx.Amount * float64(daysAfter)
+		if daysAfter < windowDays {
+			postPayday += tx.Amount
+		}
+	}
+
+	if attributed == 0 {
+		return map[string]interface{}{
+			"paydays_found": len(paydays),
+			"summary":       "No spending after a detected payday to analyze",
+		}
+	}
+	share := postPayday / attributed * 100
+	avgDaysAfter := weightedDays / attributed
+
+	summary := fmt.Sprintf("%.0f%% of your spending happens in the %d days after payday", share, windowDays)
+	if avgDaysAfter < 5 {
+		summary += ". Try moving money to savings the moment your paycheck lands"
+	}
+
+	return map[string]interface{}{
+		"paydays_found":         len(paydays),
+		"window_days":           windowDays,
+		"post_payday_spend":     fmt.Sprintf("%.2f", postPayday),
+		"post_payday_percent":   fmt.Sprintf("%.1f", share),
+		"spend_after_payday":    fmt.Sprintf("%.2f", attributed),
+		"avg_days_after_payday": math.Round(avgDaysAfter*10) / 10,
+		"summary":               summary,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: NET WORTH PROJECTION
+// ============================================================================
+
+// createNetWorthProjectionTool builds a tool that projects wallet + savings month by month
+// Composes the balances, the average monthly net cash flow and compounding at the vault APY
+func createNetWorthProjectionTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("project_net_worth").
+		Description("Project total net worth (wallet + savings) month by month. Starts from current balances, adds the average monthly net cash flow, moves a planned monthly contribution into savings and compounds savings at the vault APY. Returns the schedule and projected values at 1, 3 and 5 years. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"horizon_months":       tools.IntegerProperty("Months to project, up to 600 (default: 60)"),
+			"monthly_contribution": tools.NumberProperty("Planned monthly deposit into savings (default: 0)"),
+			"history_days":         tools.IntegerProperty("Days of history used for the average net cash flow (default: 90)"),
+			"currency":             tools.StringProperty("Currency of the balances (default: USD)"),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				HorizonMonths       int     `json:"horizon_months"`
+				MonthlyContribution float64 `json:"monthly_contribution"`
+				HistoryDays         int     `json:"history_days"`
+				Currency            string  `json:"currency"`
+				UseMock             bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.HorizonMonths <= 0 {
+				params.HorizonMonths = 60
+			}
+			if params.HorizonMonths > 600 {
+				params.HorizonMonths = 600
+			}
+			if params.HistoryDays <= 0 {
+				params.HistoryDays = 90
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+			if params.MonthlyContribution < 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "monthly_contribution can't be negative",
+				}, nil
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			wallet, savings, apy := mockWalletBalance, mockSavingsBalance, mockVaultAPY
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.HistoryDays, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for net worth projection", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.HistoryDays).Format("2006-01-02"),
+				})
+				if err == nil {
+					wallet, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err == nil {
+					savings, err = fetchSavingsBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err == nil {
+					apy, err = fetchVaultAPY(ctx, liminalExecutor, toolParams)
+				}
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			cashFlow := summarizeCashFlow(transactions, params.HistoryDays)
+			monthlyNet := cashFlow.MonthlyIncome - cashFlow.MonthlySpend
+			schedule := projectNetWorth(wallet, savings, monthlyNet, params.MonthlyContribution, apy, params.HorizonMonths, now)
+
+			milestones := map[string]interface{}{}
+			for _, years := range []int{1, 3, 5} {
+				if months := years * 12; months <= params.HorizonMonths {
+					milestones[fmt.Sprintf("%d_year", years)] = schedule[months-1]["net_worth"]
+				}
+			}
+			final := schedule[len(schedule)-1]
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"starting_net_worth":    fmt.Sprintf("%.2f", wallet+savings),
+					"starting_wallet":       fmt.Sprintf("%.2f", wallet),
+					"starting_savings":      fmt.Sprintf("%.2f", savings),
+					"monthly_net_cash_flow": fmt.Sprintf("%.2f", monthlyNet),
+					"monthly_contribution":  fmt.Sprintf("%.2f", params.MonthlyContribution),
+					"apy_used":              apy,
+					"horizon_months":        params.HorizonMonths,
+					"milestones":            milestones,
+					"schedule":              schedule,
+					"summary": fmt.Sprintf("At your current pace you'd have about $%s in %d months ($%s in savings).",
+						final["net_worth"], params.HorizonMonths, final["savings"]),
+					"currency":     params.Currency,
+					"data_source":  map[string]bool{"is_mock": params.UseMock},
+					"generated_at": now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// projectNetWorth steps wallet and savings forward one month at a time
+// Each month the wallet gains the net cash flow minus the contribution, and
+// savings earn a month of interest before the contribution lands. The wallet
+// can go negative, which flags a plan the cash flow can't sustain.
+func projectNetWorth(wallet, savings, monthlyNet, contribution, apy float64, months int, start time.Time) []map[string]interface{} {
+	rate := apy / 100 / 12
+	schedule := make([]map[string]interface{}, 0, months)
+	for month := 1; month <= months; month++ {
+		interest := savings * rate
+		savings += interest + contribution
+		wallet += monthlyNet - contribution
+		schedule = append(schedule, map[string]interface{}{
+			"month":     month,
+			"date":      start.AddDate(0, month, 0).Format("2006-01"),
+			"wallet":    fmt.Sprintf("%.2f", wallet),
+			"savings":   fmt.Sprintf("%.2f", savings),
+			"interest":  fmt.Sprintf("%.2f", interest),
+			"net_worth": fmt.Sprintf("%.2f", wallet+savings),
+		})
+	}
+	return schedule
+}
+
+// ============================================================================
+// CUSTOM TOOL: CATEGORY TREND
+// ============================================================================
+
+// createCategoryTrendTool builds a tool that drills into one category's month-by-month spend
+// Answers "is my dining getting worse?"
+func createCategoryTrendTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("category_trend").
+		Description("Show one spending category's month-by-month series, whether it is rising, falling or flat (least-squares slope), and the projected spend for next month. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"category":         tools.StringProperty("Category to analyze, e.g. 'Food & Dining'"),
+			"months":           tools.IntegerProperty("Number of 30-day months to include, at least 2 (default: 6)"),
+			"category_weights": categoryWeightsProperty(),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "category")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Category        string           `json:"category"`
+				Months          int              `json:"months"`
+				CategoryWeights []categoryWeight `json:"category_weights"`
+				UseMock         bool             `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if strings.TrimSpace(params.Category) == "" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "category is required",
+				}, nil
+			}
+			if params.Months < 2 {
+				params.Months = 6
+			}
+
+			now := time.Now()
+			days := params.Months * 30
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for category trend", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in category trend: %v", err)
+			}
+
+			// Match the category case-insensitively; a category with no spend is a flat zero series
+			category := params.Category
+			recent := make([]float64, params.Months)
+			for name, amounts := range monthlyCategorySpend(parsed, params.CategoryWeights, params.Months, now) {
+				if strings.EqualFold(name, params.Category) {
+					category, recent = name, amounts
+				}
+			}
+
+			trend := categoryTrend(recent)
+			series := []map[string]interface{}{}
+			for i := len(recent) - 1; i >= 0; i-- {
+				series = append(series, map[string]interface{}{
+					"month_start": now.AddDate(0, 0, -(i+1)*30).Format("2006-01-02"),
+					"amount":      fmt.Sprintf("%.2f", recent[i]),
+				})
+			}
+
+			summary := fmt.Sprintf("%s spending is %s by about $%.2f a month; expect ~$%.0f next month", category, trend.direction, math.Abs(trend.slope), trend.projected)
+			if trend.direction == "flat" {
+				summary = fmt.Sprintf("%s spending is holding steady; expect ~$%.0f next month", category, trend.projected)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"category":        category,
+					"series":          series,
+					"direction":       trend.direction,
+					"slope_per_month": fmt.Sprintf("%+.2f", trend.slope),
+					"monthly_average": fmt.Sprintf("%.2f", trend.average),
+					"projected_next":  fmt.Sprintf("%.2f", trend.projected),
+					"summary":         summary,
+					"data_source":     map[string]bool{"is_mock": params.UseMock},
+					"generated_at":    now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// trendResult is the fitted line through a monthly series
+type trendResult struct {
+	slope     float64 // change per month
+	average   float64
+	projected float64 // fitted value for next month, never below 0
+	direction string  // rising, falling or flat
+}
+
+// categoryTrend fits a least-squares line through monthly amounts (index 0 = most recent)
+// A slope under 5% of the average per month counts as flat
+func categoryTrend(recent []float64) trendResult {
+	n := float64(len(recent))
+	if n == 0 {
+		return trendResult{direction: "flat"}
+	}
+	// x runs oldest (0) to newest (n-1)
+	var sumX, sumY, sumXY, sumXX float64
+	for i, amount := range recent {
+		x := n - 1 - float64(i)
+		sumX += x
+		sumY += amount
+		sumXY += x * amount
+		sumXX += x * x
+	}
+	average := sumY / n
+	slope := 0.0
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	intercept := average - slope*sumX/n
+	projected := math.Max(intercept+slope*n, 0)
+
+	direction := "flat"
+	if average > 0 && slope > average*0.05 {
+		direction = "rising"
+	} else if average > 0 && slope < -average*0.05 {
+		direction = "falling"
+	}
+	return trendResult{slope: slope, average: average, projected: projected, direction: direction}
+}
+
+// ============================================================================
+// CUSTOM TOOL: LATTE FACTOR
+// ============================================================================
+
+// createLatteFactorTool builds a tool that finds the user's own "latte": a small purchase made over and over
+// The habit_cost projection, automated from real transactions instead of a hypothetical
+func createLatteFactorTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("latte_factor").
+		Description("Scan spending for frequent small purchases at the same merchant (at least min_count purchases averaging under max_average), pick the one costing the most, and project its annual cost and what that money would grow to in 10 years at the vault APY. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":        tools.IntegerProperty("Number of days of history to scan (default: 90)"),
+			"min_count":   tools.IntegerProperty("Minimum purchases at one merchant to count as a habit (default: 5)"),
+			"max_average": tools.NumberProperty("Maximum average purchase to count as small (default: 15)"),
+			"use_mock":    tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days       int     `json:"days"`
+				MinCount   int     `json:"min_count"`
+				MaxAverage float64 `json:"max_average"`
+				UseMock    bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			if params.MinCount <= 0 {
+				params.MinCount = 5
+			}
+			if params.MaxAverage <= 0 {
+				params.MaxAverage = 15
+			}
+
+			now := time.Now()
+			apy := mockVaultAPY
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for latte factor", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if liveAPY, err := fetchVaultAPY(ctx, liminalExecutor, toolParams); err == nil {
+					apy = liveAPY
+				}
+			}
+
+			candidates := findSmallHabits(transactions, params.MinCount, params.MaxAverage)
+			result := map[string]interface{}{
+				"candidates":   candidates,
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			if len(candidates) == 0 {
+				result["message"] = fmt.Sprintf("No merchant had %d+ purchases averaging under $%.2f in the last %d days. No latte factor here!", params.MinCount, params.MaxAverage, params.Days)
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
+
+			top := candidates[0]
+			total, _ := top["total"].(float64)
+			count, _ := top["count"].(int)
+			annual := total / float64(params.Days) * 365
+			invested := futureValueOfMonthly(annual/12, apy, 10)
+
+			result["merchant"] = top["merchant"]
+			result["purchases_per_week"] = math.Round(float64(count)/float64(params.Days)*7*10) / 10
+			result["average_purchase"] = top["average"]
+			result["annual_cost"] = fmt.Sprintf("%.2f", annual)
+			result["invested_10_years"] = fmt.Sprintf("%.2f", invested)
+			result["apy_used"] = apy
+			result["message"] = fmt.Sprintf("Your latte factor is %s: about %.1f purchases a week at $%.2f each, or $%.0f a year. Saved instead at %.2f%% APY, that's $%.0f in 10 years.",
+				top["merchant"], result["purchases_per_week"], top["average"], annual, apy, invested)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// findSmallHabits groups sends by merchant and keeps those with at least minCount
+// purchases averaging under maxAverage, ordered by total spent
+func findSmallHabits(transactions []map[string]interface{}, minCount int, maxAverage float64) []map[string]interface{} {
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		amount, _ := tx["amount"].(float64)
+		if txType != "send" || amount <= 0 {
+			continue
+		}
+		merchant := merchantName(tx)
+		totals[merchant] += amount
+		counts[merchant]++
+	}
+
+	habits := []map[string]interface{}{}
+	for merchant, total := range totals {
+		average := total / float64(counts[merchant])
+		if counts[merchant] < minCount || average >= maxAverage {
+			continue
+		}
+		habits = append(habits, map[string]interface{}{
+			"merchant": merchant,
+			"count":    counts[merchant],
+			"total":    math.Round(total*100) / 100,
+			"average":  math.Round(average*100) / 100,
+		})
+	}
+	sort.Slice(habits, func(i, j int) bool {
+		return habits[i]["total"].(float64) > habits[j]["total"].(float64)
+	})
+	return habits
+}
+
+// ============================================================================
+// CUSTOM TOOL: STREAMING CONSOLIDATION
+// ============================================================================
+
+// createStreamingConsolidationTool builds a tool that quantifies cutting streaming services down to a few
+// Turns the "multiple streaming subscriptions" warning into a concrete recommendation
+func createStreamingConsolidationTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("consolidate_streaming").
+		Description("Find all detected streaming subscriptions, keep the most expensive one (assumed most used) or the ones the user names, and report the monthly and annual savings from cancelling the rest. Nothing is cancelled: the result is context to confirm with the user, who cancels with each provider. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"keep":             tools.ArrayProperty("Services to keep, e.g. [\"Netflix\"] (default: the most expensive)", tools.StringProperty("Service name")),
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Number of months to scan (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Keep            []string `json:"keep"`
+				TimeframeMonths int      `json:"timeframe_months"`
+				UseMock         bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{})
+				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			streaming := []map[string]interface{}{}
+			for _, sub := range analyzeForSubscriptions(transactions, cutoffDate, 1.00, 999.99) {
+				merchant, _ := sub["merchant"].(string)
+				amount, _ := sub["amount"].(float64)
+				frequency, _ := sub["frequency"].(string)
+				if !inSubscriptionGroup(merchant, "streaming") {
+					continue
+				}
+				streaming = append(streaming, map[string]interface{}{
+					"merchant":     merchant,
+					"monthly_cost": math.Round(monthlyEquivalent(amount, frequency)*100) / 100,
+				})
+			}
+			sort.Slice(streaming, func(i, j int) bool {
+				return streaming[i]["monthly_cost"].(float64) > streaming[j]["monthly_cost"].(float64)
+			})
+
+			result := map[string]interface{}{
+				"streaming_found": len(streaming),
+				"data_source":     map[string]bool{"is_mock": params.UseMock},
+				"generated_at":    now.Format(time.RFC3339),
+			}
+			if len(streaming) < 2 {
+				result["message"] = fmt.Sprintf("Only %d streaming subscription(s) detected, so there's nothing to consolidate.", len(streaming))
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
+
+			// Keep what the user named, or the priciest service if they named none that matched
+			isKept := func(merchant string) bool {
+				for _, name := range params.Keep {
+					if name = strings.TrimSpace(name); name != "" && strings.Contains(strings.ToLower(merchant), strings.ToLower(name)) {
+						return true
+					}
+				}
+				return false
+			}
+			keep, cancel := []map[string]interface{}{}, []map[string]interface{}{}
+			for _, service := range streaming {
+				if isKept(service["merchant"].(string)) {
+					keep = append(keep, service)
+				} else {
+					cancel = append(cancel, service)
+				}
+			}
+			if len(keep) == 0 {
+				keep, cancel = streaming[:1], streaming[1:]
+			}
+
+			var monthlySavings float64
+			names := []string{}
+			for _, service := range cancel {
+				monthlySavings += service["monthly_cost"].(float64)
+				names = append(names, service["merchant"].(string))
+			}
+			keptNames := []string{}
+			for _, service := range keep {
+				keptNames = append(keptNames, service["merchant"].(string))
+			}
+
+			result["keep"] = keep
+			result["cancel"] = cancel
+			result["monthly_savings"] = fmt.Sprintf("%.2f", monthlySavings)
+			result["annual_savings"] = fmt.Sprintf("%.2f", monthlySavings*12)
+			result["confirmation_prompt"] = fmt.Sprintf("Keep %s and cancel %s to save $%.2f/month ($%.2f/year)?",
+				strings.Join(keptNames, " and "), strings.Join(names, ", "), monthlySavings, monthlySavings*12)
+			result["note"] = "Nothing has been cancelled. The user cancels each service with the provider."
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: WINDFALL ALLOCATION
+// ============================================================================
+
+// createWindfallTool builds a tool that answers "I just got a bonus, what do I do?"
+// Order: a slice for fun, then the emergency fund gap, then goals, then high-APY savings
+func createWindfallTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("allocate_windfall").
+		Description("Suggest how to split a one-time windfall (bonus, tax refund, gift): a discretionary slice to enjoy, topping up the emergency fund to its target, funding savings goals by priority, and the rest into high-APY savings. Returns the allocation with a rationale for each part and the projected first-year interest on the saved portion. Does not move any money. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"windfall_amount":       tools.NumberProperty("Amount of the windfall"),
+			"goals":                 goalsProperty("Optional savings goals to fund after the emergency fund"),
+			"discretionary_percent": tools.NumberProperty("Share of the windfall to spend guilt-free (default: 10)"),
+			"emergency_fund_months": tools.IntegerProperty("Months of essential spending the emergency fund should cover (default: 3)"),
+			"current_savings":       tools.NumberProperty("Override the savings balance counted toward the emergency fund"),
+			"currency":              tools.StringProperty("Currency of the windfall (default: USD)"),
+			"essential_categories":  essentialCategoriesProperty(),
+			"use_mock":              tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "windfall_amount")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				WindfallAmount       float64       `json:"windfall_amount"`
+				Goals                []savingsGoal `json:"goals"`
+				DiscretionaryPercent *float64      `json:"discretionary_percent"`
+				EmergencyFundMonths  int           `json:"emergency_fund_months"`
+				CurrentSavings       *float64      `json:"current_savings"`
+				Currency             string        `json:"currency"`
+				EssentialCategories  []string      `json:"essential_categories"`
+				UseMock              bool          `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.WindfallAmount <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "windfall_amount must be greater than 0",
+				}, nil
+			}
+			discretionaryPercent := 10.0
+			if params.DiscretionaryPercent != nil {
+				discretionaryPercent = math.Min(math.Max(*params.DiscretionaryPercent, 0), 100)
+			}
+			if params.EmergencyFundMonths <= 0 {
+				params.EmergencyFundMonths = 3
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			const historyDays = 90
+			now := time.Now()
+			var transactions []map[string]interface{}
+			savings, apy := mockSavingsBalance, mockVaultAPY
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(historyDays, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for windfall allocation", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -historyDays).Format("2006-01-02"),
+				})
+				if err == nil && params.CurrentSavings == nil {
+					savings, err = fetchSavingsBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+				if liveAPY, err := fetchVaultAPY(ctx, liminalExecutor, toolParams); err == nil {
+					apy = liveAPY
+				}
+			}
+			if params.CurrentSavings != nil {
+				savings = *params.CurrentSavings
+			}
+
+			cashFlow := summarizeCashFlowWith(transactions, historyDays, resolveEssentials(params.EssentialCategories))
+			fundTarget := cashFlow.MonthlyEssentialSpend * float64(params.EmergencyFundMonths)
+			fundGap := math.Max(fundTarget-savings, 0)
+
+			discretionary := math.Floor(params.WindfallAmount*discretionaryPercent) / 100
+			remaining := params.WindfallAmount - discretionary
+			emergency := math.Min(fundGap, remaining)
+			remaining -= emergency
+
+			goalAllocations := []map[string]interface{}{}
+			var goalsTotal float64
+			if len(params.Goals) > 0 && remaining > 0 {
+				for i, amount := range allocateToGoals(params.Goals, remaining, "priority") {
+					if amount <= 0 {
+						continue
+					}
+					goalsTotal += amount
+					goalAllocations = append(goalAllocations, map[string]interface{}{
+						"goal":   params.Goals[i].Name,
+						"amount": fmt.Sprintf("%.2f", amount),
+					})
+				}
+				remaining -= goalsTotal
+			}
+			highYield := remaining
+
+			saved := emergency + goalsTotal + highYield
+			firstYearInterest := saved * apy / 100
+
+			allocation := []map[string]interface{}{
+				{
+					"bucket":    "discretionary",
+					"amount":    fmt.Sprintf("%.2f", discretionary),
+					"rationale": fmt.Sprintf("%.0f%% to enjoy guilt-free, so the plan is one you'll actually stick to", discretionaryPercent),
+				},
+				{
+					"bucket":    "emergency_fund",
+					"amount":    fmt.Sprintf("%.2f", emergency),
+					"rationale": emergencyRationale(fundTarget, fundGap, emergency, params.EmergencyFundMonths),
+				},
+			}
+			if len(goalAllocations) > 0 {
+				allocation = append(allocation, map[string]interface{}{
+					"bucket":    "goals",
+					"amount":    fmt.Sprintf("%.2f", goalsTotal),
+					"rationale": "Funds your goals in priority order once the safety net is covered",
+					"goals":     goalAllocations,
+				})
+			}
+			allocation = append(allocation, map[string]interface{}{
+				"bucket":    "high_yield_savings",
+				"amount":    fmt.Sprintf("%.2f", highYield),
+				"rationale": fmt.Sprintf("Everything else earns %.2f%% APY until you need it", apy),
+			})
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"windfall_amount":       fmt.Sprintf("%.2f", params.WindfallAmount),
+					"allocation":            allocation,
+					"emergency_fund_target": fmt.Sprintf("%.2f", fundTarget),
+					"emergency_fund_gap":    fmt.Sprintf("%.2f", fundGap),
+					"current_savings":       fmt.Sprintf("%.2f", savings),
+					"saved_total":           fmt.Sprintf("%.2f", saved),
+					"first_year_interest":   fmt.Sprintf("%.2f", firstYearInterest),
+					"apy_used":              apy,
+					"currency":              params.Currency,
+					"note":                  "Nothing has been moved. Use deposit_savings to act on the plan.",
+					"data_source":           map[string]bool{"is_mock": params.UseMock},
+					"generated_at":          now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// emergencyRationale explains the emergency fund share of a windfall
+func emergencyRationale(target, gap, allocated float64, months int) string {
+	switch {
+	case gap == 0:
+		return fmt.Sprintf("Your emergency fund already covers %d months of essentials ($%.2f)", months, target)
+	case allocated >= gap:
+		return fmt.Sprintf("Completes your %d-month emergency fund of $%.2f", months, target)
+	default:
+		return fmt.Sprintf("Closes $%.2f of the $%.2f gap to a %d-month emergency fund; the safety net comes first", allocated, gap, months)
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: DEFICIT DETECTOR
+// ============================================================================
+
+// createDeficitDetectorTool builds a tool that flags months where spending beat income
+// The month-by-month complement to the single-window net_cash_flow in analyze_spending
+func createDeficitDetectorTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("detect_deficit").
+		Description("Bucket recent history into 30-day months and flag each month where spending exceeded income. Returns the number of deficit months, the average monthly shortfall, whether the deficit is chronic, and whether net cash flow is worsening or improving. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"months":   tools.IntegerProperty("Number of 30-day months to check, at least 2 (default: 6)"),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Months  int  `json:"months"`
+				UseMock bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Months < 2 {
+				params.Months = 6
+			}
+
+			now := time.Now()
+			days := params.Months * 30
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for deficit detection", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in deficit detection: %v", err)
+			}
+
+			spend, income := monthlyCashFlow(parsed, params.Months, now)
+			net := make([]float64, params.Months)
+			months := []map[string]interface{}{}
+			deficitMonths := 0
+			var shortfall, totalSpend float64
+			for i := params.Months - 1; i >= 0; i-- {
+				net[i] = income[i] - spend[i]
+				totalSpend += spend[i]
+				deficit := net[i] < 0
+				if deficit {
+					deficitMonths++
+					shortfall -= net[i]
+				}
+				months = append(months, map[string]interface{}{
+					"month_start": now.AddDate(0, 0, -(i+1)*30).Format("2006-01-02"),
+					"income":      fmt.Sprintf("%.2f", income[i]),
+					"spend":       fmt.Sprintf("%.2f", spend[i]),
+					"net":         fmt.Sprintf("%+.2f", net[i]),
+					"deficit":     deficit,
+				})
+			}
+			averageShortfall := 0.0
+			if deficitMonths > 0 {
+				averageShortfall = shortfall / float64(deficitMonths)
+			}
+
+			// Net cash flow moving more than 5% of average spend per month counts as a trend
+			trend := "stable"
+			slope := categoryTrend(net).slope
+			threshold := totalSpend / float64(params.Months) * 0.05
+			if slope < -threshold {
+				trend = "worsening"
+			} else if slope > threshold {
+				trend = "improving"
+			}
+			chronic := deficitMonths*2 >= params.Months
+
+			summary := fmt.Sprintf("Spending stayed within income in all %d months.", params.Months)
+			if deficitMonths > 0 {
+				summary = fmt.Sprintf("Spending exceeded income in %d of the last %d months, by $%.2f on average; net cash flow is %s.",
+					deficitMonths, params.Months, averageShortfall, trend)
+			}
+			if chronic {
+				summary += " This is a chronic deficit: it is being covered by savings or debt."
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"months":            months,
+					"deficit_months":    deficitMonths,
+					"months_checked":    params.Months,
+					"average_shortfall": fmt.Sprintf("%.2f", averageShortfall),
+					"chronic":           chronic,
+					"trend":             trend,
+					"net_slope":         fmt.Sprintf("%+.2f", slope),
+					"summary":           summary,
+					"data_source":       map[string]bool{"is_mock": params.UseMock},
+					"generated_at":      now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// monthlyCashFlow buckets spend and income into 30-day months counted back from
+// now (index 0 = most recent). Refunds come off spend rather than counting as income.
+func monthlyCashFlow(transactions []Transaction, months int, now time.Time) (spend, income []float64) {
+	spend = make([]float64, months)
+	income = make([]float64, months)
+	for _, tx := range transactions {
+		month := int(now.Sub(tx.Date).Hours() / 24 / 30)
+		if month < 0 || month >= months {
+			continue
+		}
+		switch {
+		case tx.Type == "send":
+			spend[month] += tx.Amount
+		case tx.Type == "receive" && isRefund(tx.Description):
+			spend[month] = math.Max(spend[month]-tx.Amount, 0)
+		case tx.Type == "receive":
+			income[month] += tx.Amount
+		}
+	}
+	return spend, income
+}
+
+// ============================================================================
+// CUSTOM TOOL: SPEND CONCENTRATION
+// ============================================================================
+
+// createSpendConcentrationTool builds a tool that finds how few merchants make up most of the spending
+// "Just 4 merchants account for 80% of your spending" tells the user where to look first
+func createSpendConcentrationTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("spend_concentration").
+		Description("Find the smallest set of merchants that together account for a share of total spending (80% by default, the Pareto split). Returns the merchants with their totals and cumulative share, how many there are out of all merchants, and a concentration ratio. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":              tools.IntegerProperty(fmt.Sprintf("Number of days to analyze (default: %d)", defaultSpendingDays)),
+			"threshold_percent": tools.NumberProperty("Share of spending to account for, 1-100 (default: 80)"),
+			"use_mock":          tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days             int     `json:"days"`
+				ThresholdPercent float64 `json:"threshold_percent"`
+				UseMock          bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+			if params.ThresholdPercent <= 0 || params.ThresholdPercent > 100 {
+				params.ThresholdPercent = 80
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for spend concentration", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			concentration := spendConcentration(transactions, params.ThresholdPercent)
+			concentration["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			concentration["generated_at"] = now.Format(time.RFC3339)
+
+			return &core.ToolResult{
+				Success: true,
+				Data:    concentration,
+			}, nil
+		}).
+		Build()
+}
+
+// spendConcentration sorts merchant totals descending and keeps merchants until their
+// cumulative share reaches thresholdPercent. concentration_ratio is that count over all
+// merchants, so 0.2 means a fifth of merchants take threshold_percent of the spend.
+func spendConcentration(transactions []map[string]interface{}, thresholdPercent float64) map[string]interface{} {
+	totals := make(map[string]float64)
+	var spent float64
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		amount, _ := tx["amount"].(float64)
+		if txType != "send" || amount <= 0 {
+			continue
+		}
+		totals[merchantName(tx)] += amount
+		spent += amount
+	}
+	if spent == 0 {
+		return map[string]interface{}{
+			"spend_concentration": []map[string]interface{}{},
+			"merchant_count":      0,
+			"total_merchants":     0,
+			"concentration_ratio": 0.0,
+			"threshold_percent":   thresholdPercent,
+			"total_spent":         "0.00",
+			"summary":             "No spending in this period.",
+		}
+	}
+
+	merchants := make([]string, 0, len(totals))
+	for merchant := range totals {
+		merchants = append(merchants, merchant)
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		if totals[merchants[i]] != totals[merchants[j]] {
+			return totals[merchants[i]] > totals[merchants[j]]
+		}
+		return merchants[i] < merchants[j]
+	})
+
+	top := []map[string]interface{}{}
+	var cumulative float64
+	for _, merchant := range merchants {
+		cumulative += totals[merchant]
+		share := cumulative / spent * 100
+		top = append(top, map[string]interface{}{
+			"merchant":           merchant,
+			"amount":             fmt.Sprintf("%.2f", totals[merchant]),
+			"percent":            math.Round(totals[merchant]/spent*1000) / 10,
+			"cumulative_percent": math.Round(share*10) / 10,
+		})
+		if share >= thresholdPercent {
+			break
+		}
+	}
+	ratio := float64(len(top)) / float64(len(merchants))
+
+	return map[string]interface{}{
+		"spend_concentration": top,
+		"merchant_count":      len(top),
+		"total_merchants":     len(merchants),
+		"concentration_ratio": math.Round(ratio*100) / 100,
+		"threshold_percent":   thresholdPercent,
+		"total_spent":         fmt.Sprintf("%.2f", spent),
+		"summary": fmt.Sprintf("Just %d of your %d merchants account for %.0f%% of your spending.",
+			len(top), len(merchants), thresholdPercent),
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: UNUSED SUBSCRIPTIONS
+// ============================================================================
+
+// createUnusedSubscriptionTool builds a tool that flags subscriptions the user is paying for but not using
+// We have no usage data of our own, so a frontend can pass what the user reports in last_used
+func createUnusedSubscriptionTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("flag_unused_subscriptions").
+		Description("Flag subscriptions that are still being paid but haven't been used in a while. Pass last_used (merchant name to the date the user last used it, YYYY-MM-DD) for precise results; without it, falls back to flagging subscriptions with few and stale payments. Returns each flagged subscription with its monthly cost and the total that cancelling would save. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"last_used": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Map of merchant name to the date the user last used it (YYYY-MM-DD), e.g. {\"Netflix\": \"2024-01-15\"}",
+				"additionalProperties": tools.StringProperty("Date last used (YYYY-MM-DD)"),
+			},
+			"unused_days":      tools.IntegerProperty("Days without use before a subscription counts as unused (default: 60)"),
+			"timeframe_months": tools.IntegerProperty(fmt.Sprintf("Number of months to scan (default: %d, max: %d)", defaultSubscriptionMonths, maxTimeframeMonths)),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				LastUsed        map[string]string `json:"last_used"`
+				UnusedDays      int               `json:"unused_days"`
+				TimeframeMonths int               `json:"timeframe_months"`
+				UseMock         bool              `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.UnusedDays <= 0 {
+				params.UnusedDays = 60
+			}
+			if params.TimeframeMonths <= 0 {
+				params.TimeframeMonths = defaultSubscriptionMonths
+			}
+			params.TimeframeMonths = min(params.TimeframeMonths, maxTimeframeMonths)
+			lastUsed := make(map[string]time.Time, len(params.LastUsed))
+			for merchant, dateStr := range params.LastUsed {
+				date, err := time.Parse("2006-01-02", dateStr)
+				if err != nil {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("last_used[%q] must be a YYYY-MM-DD date, got %q", merchant, dateStr),
+					}, nil
+				}
+				lastUsed[strings.ToLower(strings.TrimSpace(merchant))] = date
+			}
+
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths, mockOptions{})
+				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, 0.01, 999.99)
+			flagged, notReported, monthlyTotal := findUnusedSubscriptions(subscriptions, lastUsed, params.UnusedDays, now)
+
+			method := "usage_report"
+			if len(lastUsed) == 0 {
+				method = "inactivity_heuristic"
+			}
+			message := "Every subscription looks like it's in use."
+			if len(flagged) > 0 {
+				message = fmt.Sprintf("%d subscription(s) look unused. Cancelling them would save $%.2f/month ($%.2f/year).", len(flagged), monthlyTotal, monthlyTotal*12)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"unused_subscriptions": flagged,
+					"count":                len(flagged),
+					"monthly_savings":      fmt.Sprintf("%.2f", monthlyTotal),
+					"yearly_savings":       fmt.Sprintf("%.2f", monthlyTotal*12),
+					"no_usage_reported":    notReported,
+					"method":               method,
+					"message":              message,
+					"data_source":          map[string]bool{"is_mock": params.UseMock},
+					"generated_at":         now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// findUnusedSubscriptions flags subscriptions paid within the last 45 days whose reported
+// last use is at least unusedDays old. A last_used key matches a merchant when either name
+// contains the other, so "netflix" matches "Netflix Subscription". With no usage reports at
+// all it falls back to looksInactive. Returns the flagged subscriptions, merchants that had
+// no usage report, and the flagged monthly total.
+func findUnusedSubscriptions(subscriptions []map[string]interface{}, lastUsed map[string]time.Time, unusedDays int, now time.Time) ([]map[string]interface{}, []string, float64) {
+	flagged := []map[string]interface{}{}
+	notReported := []string{}
+	var total float64
+	for _, sub := range subscriptions {
+		merchant, _ := sub["merchant"].(string)
+		amount, _ := sub["amount"].(float64)
+		frequency, _ := sub["frequency"].(string)
+		lastPaidStr, _ := sub["last_occurrence"].(string)
+		monthly := monthlyEquivalent(amount, frequency)
+
+		var reason string
+		if len(lastUsed) == 0 {
+			if !looksInactive(sub, now) {
+				continue
+			}
+			reason = fmt.Sprintf("Only a few payments, the last on %s", lastPaidStr)
+		} else {
+			usedOn, reported := usageFor(merchant, lastUsed)
+			if !reported {
+				// Amount clusters can list one merchant twice
+				if !containsString(notReported, merchant) {
+					notReported = append(notReported, merchant)
+				}
+				continue
+			}
+			lastPaid, err := time.Parse("2006-01-02", lastPaidStr)
+			idleDays := int(now.Sub(usedOn).Hours() / 24)
+			if err != nil || now.Sub(lastPaid).Hours()/24 > 45 || idleDays < unusedDays {
+				continue
+			}
+			reason = fmt.Sprintf("Paid on %s but last used %d days ago", lastPaidStr, idleDays)
+		}
+
+		total += monthly
+		flagged = append(flagged, map[string]interface{}{
+			"merchant":     merchant,
+			"amount":       amount,
+			"frequency":    frequency,
+			"last_paid":    lastPaidStr,
+			"monthly_cost": math.Round(monthly*100) / 100,
+			"reason":       reason,
+		})
+	}
+	sort.Slice(flagged, func(i, j int) bool {
+		return flagged[i]["monthly_cost"].(float64) > flagged[j]["monthly_cost"].(float64)
+	})
+	return flagged, notReported, math.Round(total*100) / 100
+}
+
+// usageFor finds the reported last-use date for a merchant (keys are lowercased)
+func usageFor(merchant string, lastUsed map[string]time.Time) (time.Time, bool) {
+	lower := strings.ToLower(merchant)
+	if date, ok := lastUsed[lower]; ok {
+		return date, true
+	}
+	for name, date := range lastUsed {
+		if name != "" && (strings.Contains(lower, name) || strings.Contains(name, lower)) {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ============================================================================
+// CUSTOM TOOL: SAVINGS OPPORTUNITY COST
+// ============================================================================
+
+// createOpportunityCostTool builds a tool that puts a dollar figure on cash sitting in the wallet
+// Backs the "move it to savings" nudge with what the user is giving up at the vault APY
+func createOpportunityCostTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("savings_opportunity_cost").
+		Description("Estimate the interest the user is missing out on by keeping money in the zero-yield wallet instead of savings. Uses the current wallet balance (or a provided average_balance), minus an optional amount to keep on hand, at the current vault APY. Returns the yearly and monthly foregone interest. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"average_balance": tools.NumberProperty("Average idle wallet balance to use instead of the current balance"),
+			"keep_in_wallet":  tools.NumberProperty("Amount to leave in the wallet for everyday spending (default: 0)"),
+			"currency":        tools.StringProperty("Currency of the wallet balance (default: USD)"),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				AverageBalance *float64 `json:"average_balance"`
+				KeepInWallet   float64  `json:"keep_in_wallet"`
+				Currency       string   `json:"currency"`
+				UseMock        bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			// There is no balance history to average, so the current balance stands in unless one is given
+			balance, apy := mockWalletBalance, mockVaultAPY
+			balanceSource := "current_balance"
+			if !params.UseMock {
+				if params.AverageBalance == nil {
+					var err error
+					if balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency); err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+				if liveAPY, err := fetchVaultAPY(ctx, liminalExecutor, toolParams); err == nil {
+					apy = liveAPY
+				}
+			}
+			if params.AverageBalance != nil {
+				balance = *params.AverageBalance
+				balanceSource = "average_balance"
+			}
+
+			idle := math.Max(balance-math.Max(params.KeepInWallet, 0), 0)
+			yearly := idle * apy / 100
+			monthly := yearly / 12
+
+			message := fmt.Sprintf("Keeping $%.2f idle in your wallet costs you about $%.2f a year ($%.2f a month) in interest at %.2f%% APY.", idle, yearly, monthly, apy)
+			if idle == 0 {
+				message = "Nothing is sitting idle beyond what you keep on hand, so you're not missing out on interest."
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"wallet_balance":    fmt.Sprintf("%.2f", balance),
+					"balance_source":    balanceSource,
+					"keep_in_wallet":    fmt.Sprintf("%.2f", math.Max(params.KeepInWallet, 0)),
+					"idle_balance":      fmt.Sprintf("%.2f", idle),
+					"apy_used":          apy,
+					"foregone_yearly":   fmt.Sprintf("%.2f", yearly),
+					"foregone_monthly":  fmt.Sprintf("%.2f", monthly),
+					"currency":          params.Currency,
+					"message":           message,
+					"suggested_deposit": fmt.Sprintf("%.2f", idle),
+					"data_source":       map[string]bool{"is_mock": params.UseMock},
+					"generated_at":      time.Now().Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: CATEGORY RULE SUGGESTIONS
+// ============================================================================
+
+// categoryHints are extra keywords used only to guess a category for spending
+// that fell through to the fallback; they never change categorization themselves
+var categoryHints = []categoryRule{
+	{"Food & Dining", []string{"trader joe", "grocer", "market", "bakery", "deli", "kitchen", "grill", "bistro", "diner", "taco", "burger", "sushi"}},
+	{"Transportation", []string{"taxi", "transit", "airline", "toll", "fuel", "shell", "chevron", "rail"}},
+	{"Shopping", []string{"walmart", "costco", "best buy", "ikea", "mall", "outlet", "shop"}},
+	{"Entertainment", []string{"cinema", "theater", "theatre", "concert", "ticket", "game", "music"}},
+	{"Bills & Utilities", []string{"utility", "water", "energy", "insurance", "wireless", "mobile", "rent"}},
+}
+
+// ruleTokenStopwords are description tokens too generic to become a keyword
+var ruleTokenStopwords = map[string]bool{
+	"the": true, "and": true, "inc": true, "llc": true, "ltd": true, "com": true, "www": true,
+	"pos": true, "card": true, "debit": true, "purchase": true, "payment": true, "online": true,
+}
+
+// createRuleSuggestionTool builds a tool that turns uncategorized spending into keyword rule suggestions
+// Pass the accepted suggestions back to analyze_spending as category_weights
+func createRuleSuggestionTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("suggest_category_rules").
+		Description("Look at spending that fell into the fallback category (\"Other\"), cluster the merchant names by shared words, and suggest new keyword rules, e.g. \"3 transactions from 'Trader Joes' - add to Food & Dining?\". Each suggestion includes a category_weight entry that can be passed to analyze_spending's category_weights once the user agrees. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":      tools.IntegerProperty(fmt.Sprintf("Number of days to scan (default: %d)", defaultSpendingDays)),
+			"min_count": tools.IntegerProperty("Minimum transactions sharing a word before suggesting a rule (default: 2)"),
+			"use_mock":  tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days     int  `json:"days"`
+				MinCount int  `json:"min_count"`
+				UseMock  bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = defaultSpendingDays
+			}
+			if params.MinCount <= 0 {
+				params.MinCount = 2
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockTransactionsForAnalysis(params.Days, mockOptions{}),
+					generateMockUncategorizedTransactions(params.Days, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for category rule suggestions", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			suggestions, uncategorized := suggestCategoryRules(transactions, params.MinCount)
+			message := fmt.Sprintf("Everything in the last %d days matched a category. No new rules needed.", params.Days)
+			if len(suggestions) > 0 {
+				message = fmt.Sprintf("%d uncategorized transactions; %d new keyword rules would cover most of them. Ask the user before applying any.", uncategorized, len(suggestions))
+			} else if uncategorized > 0 {
+				message = fmt.Sprintf("%d uncategorized transactions, but no merchant shows up often enough to suggest a rule.", uncategorized)
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"suggestions":         suggestions,
+					"uncategorized_count": uncategorized,
+					"fallback_category":   fallbackCategory,
+					"message":             message,
+					"data_source":         map[string]bool{"is_mock": params.UseMock},
+					"generated_at":        now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// suggestCategoryRules clusters fallback-category sends by shared description tokens.
+// Tokens are taken greedily, most common first, so each transaction lands in one
+// cluster. Clusters below minCount are dropped. Returns the suggestions and the
+// number of uncategorized sends.
+func suggestCategoryRules(transactions []map[string]interface{}, minCount int) ([]map[string]interface{}, int) {
+	type uncategorizedTx struct {
+		merchant string
+		amount   float64
+		tokens   []string
+	}
+	var pending []uncategorizedTx
+	tokenCounts := make(map[string]int)
+	for _, tx := range transactions {
+		txType, _ := tx["type"].(string)
+		description, _ := tx["description"].(string)
+		if txType != "send" || strings.TrimSpace(description) == "" || categorizeTransaction(description) != fallbackCategory {
+			continue
+		}
+		amount, _ := tx["amount"].(float64)
+		tokens := ruleTokens(description)
+		for _, token := range tokens {
+			tokenCounts[token]++
+		}
+		pending = append(pending, uncategorizedTx{merchant: merchantName(tx), amount: amount, tokens: tokens})
+	}
+
+	ranked := make([]string, 0, len(tokenCounts))
+	for token := range tokenCounts {
+		ranked = append(ranked, token)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if tokenCounts[ranked[i]] != tokenCounts[ranked[j]] {
+			return tokenCounts[ranked[i]] > tokenCounts[ranked[j]]
+		}
+		// Longer words make more specific keywords ("shell" over "oil")
+		if len(ranked[i]) != len(ranked[j]) {
+			return len(ranked[i]) > len(ranked[j])
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	suggestions := []map[string]interface{}{}
+	assigned := make([]bool, len(pending))
+	for _, token := range ranked {
+		if tokenCounts[token] < minCount {
+			break
+		}
+		var total float64
+		merchants := make(map[string]int)
+		var members []int
+		for i, tx := range pending {
+			if !assigned[i] && containsString(tx.tokens, token) {
+				members = append(members, i)
+				total += tx.amount
+				merchants[tx.merchant]++
+			}
+		}
+		if len(members) < minCount {
+			continue
+		}
+		for _, i := range members {
+			assigned[i] = true
+		}
+
+		// Name the cluster after its most frequent merchant, without store numbers
+		merchant := ""
+		for name, count := range merchants {
+			if merchant == "" || count > merchants[merchant] || (count == merchants[merchant] && name < merchant) {
+				merchant = name
+			}
+		}
+		if trimmed := strings.TrimRight(merchant, "0123456789# "); trimmed != "" {
+			merchant = trimmed
+		}
+		category := guessCategory(merchant)
+		question := fmt.Sprintf("%d transactions from '%s' - add to %s?", len(members), merchant, category)
+		if category == "" {
+			question = fmt.Sprintf("%d transactions from '%s' - which category should these go in?", len(members), merchant)
+		}
+		suggestion := map[string]interface{}{
+			"merchant":           merchant,
+			"suggested_category": category,
+			"keyword":            token,
+			"count":              len(members),
+			"total":              fmt.Sprintf("%.2f", total),
+			"question":           question,
+		}
+		if category != "" {
+			suggestion["category_weight"] = categoryWeight{Category: category, Keyword: token, Weight: 1}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, len(pending)
+}
+
+// ruleTokens splits a description into distinct lowercase words usable as keywords:
+// at least 3 letters, not all digits, not a stopword
+func ruleTokens(description string) []string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '\'')
+	})
+	tokens := []string{}
+	for _, word := range words {
+		word = strings.Trim(word, "'")
+		if len(word) < 3 || ruleTokenStopwords[word] || strings.Trim(word, "0123456789") == "" || containsString(tokens, word) {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// guessCategory picks a category for an uncategorized merchant from categoryHints
+// Returns "" when nothing fits, so the user is asked instead
+func guessCategory(merchant string) string {
+	text := strings.ToLower(merchant)
+	for _, hint := range categoryHints {
+		for _, keyword := range hint.Keywords {
+			if strings.Contains(text, keyword) {
+				return hint.Name
+			}
+		}
+	}
+	return ""
+}
+
+// generateMockUncategorizedTransactions adds spending that no category rule matches
+// so the demo has something to suggest rules for
+func generateMockUncategorizedTransactions(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	templates := []struct {
+		description string
+		amount      float64
+	}{
+		{"Trader Joes #552", 48.20},
+		{"Trader Joes #118", 61.75},
+		{"CVS Pharmacy", 18.40},
+		{"Petco Supplies", 34.99},
+		{"Shell Oil 0042", 41.00},
+	}
+
+	transactions := []map[string]interface{}{}
+	for i := 0; i < days/5+3; i++ {
+		template := templates[rng.Intn(len(templates))]
+		currency := pickMockCurrency(opts.Currency, rng)
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_mock_other_%d", i),
+			"type":        "send",
+			"amount":      convertMockAmount(template.amount*(0.8+rng.Float64()*0.4), currency),
+			"description": template.description,
+			"date":        now.AddDate(0, 0, -rng.Intn(days)).Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    currency,
+		})
+	}
+	return transactions
+}
+
+// ============================================================================
+// CUSTOM TOOL: TRANSACTION SIZE TREND
+// ============================================================================
+
+// createTransactionSizeTrendTool builds a tool that tracks the average purchase size month by month
+// Separates "each purchase costs more" from "I'm buying more often"
+func createTransactionSizeTrendTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("transaction_size_trend").
+		Description("Show the average spending transaction size for each 30-day month, with the purchase count, and whether the average is rising, falling or flat (least-squares slope). Isolates ticket-size creep from changes in how often the user spends. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"months":   tools.IntegerProperty("Number of 30-day months to include, at least 2 (default: 6)"),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Months  int  `json:"months"`
+				UseMock bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Months < 2 {
+				params.Months = 6
+			}
+
+			now := time.Now()
+			days := params.Months * 30
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for transaction size trend", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in transaction size trend: %v", err)
+			}
+
+			sums := make([]float64, params.Months)
+			counts := make([]int, params.Months)
+			for _, tx := range parsed {
+				month := int(now.Sub(tx.Date).Hours() / 24 / 30)
+				if tx.Type != "send" || month < 0 || month >= params.Months {
+					continue
+				}
+				sums[month] += tx.Amount
+				counts[month]++
+			}
+
+			// Months without purchases have no average, so they're left out of the fit
+			series := []map[string]interface{}{}
+			averages := []float64{}
+			for i := params.Months - 1; i >= 0; i-- {
+				entry := map[string]interface{}{
+					"month_start": now.AddDate(0, 0, -(i+1)*30).Format("2006-01-02"),
+					"count":       counts[i],
+					"total":       fmt.Sprintf("%.2f", sums[i]),
+				}
+				if counts[i] > 0 {
+					average := sums[i] / float64(counts[i])
+					entry["average"] = fmt.Sprintf("%.2f", average)
+					averages = append([]float64{average}, averages...)
+				}
+				series = append(series, entry)
+			}
+
+			result := map[string]interface{}{
+				"series":       series,
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			if len(averages) < 2 {
+				result["direction"] = "flat"
+				result["summary"] = "Not enough months with purchases to see a trend in purchase size."
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
+
+			// averages is most recent first, as categoryTrend expects
+			trend := categoryTrend(averages)
+			summary := fmt.Sprintf("Your average purchase size is holding steady at about $%.2f.", trend.average)
+			switch trend.direction {
+			case "rising":
+				summary = fmt.Sprintf("Your average purchase size is creeping up, by about $%.2f a month (now ~$%.2f).", trend.slope, averages[0])
+			case "falling":
+				summary = fmt.Sprintf("Your average purchase size is shrinking, by about $%.2f a month (now ~$%.2f).", -trend.slope, averages[0])
+			}
+
+			result["direction"] = trend.direction
+			result["slope_per_month"] = fmt.Sprintf("%+.2f", trend.slope)
+			result["average_size"] = fmt.Sprintf("%.2f", trend.average)
+			result["summary"] = summary
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: PEER-TO-PEER TRANSFERS
+// ============================================================================
+
+// createP2PTool builds a tool that summarizes money sent to and received from other Liminal users
+// Counterparties are the @handles in the description or the recipient/sender fields
+func createP2PTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("analyze_p2p").
+		Description("Summarize peer-to-peer transfers with other users (@handles): for each person, how much was sent, received, and the net flow, e.g. \"you've net-sent $120 to @bob this month\". Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":     tools.IntegerProperty("Number of days to analyze (default: 30)"),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days    int  `json:"days"`
+				UseMock bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 30
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockTransactionsForAnalysis(params.Days, mockOptions{}),
+					generateMockP2PTransactions(params.Days, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for P2P analysis", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			counterparties, sent, received := summarizeP2P(transactions, now.AddDate(0, 0, -params.Days))
+			message := fmt.Sprintf("No transfers with other users in the last %d days.", params.Days)
+			if len(counterparties) > 0 {
+				message = fmt.Sprintf("%d people in the last %d days: $%.2f sent, $%.2f received. %s",
+					len(counterparties), params.Days, sent, received, counterparties[0]["summary"])
+			}
+
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"counterparties": counterparties,
+					"total_sent":     fmt.Sprintf("%.2f", sent),
+					"total_received": fmt.Sprintf("%.2f", received),
+					"net_flow":       fmt.Sprintf("%+.2f", received-sent),
+					"message":        message,
+					"data_source":    map[string]bool{"is_mock": params.UseMock},
+					"generated_at":   now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// summarizeP2P totals transfers per @handle since cutoff, largest net flow first
+// net is received minus sent, so a negative net means the user paid out more
+func summarizeP2P(transactions []map[string]interface{}, cutoff time.Time) ([]map[string]interface{}, float64, float64) {
+	type flow struct {
+		handle         string
+		sent, received float64
+		count          int
+		last           time.Time
+	}
+	flows := make(map[string]*flow)
+	var totalSent, totalReceived float64
+	for _, tx := range transactions {
+		handle, ok := p2pCounterparty(tx)
+		if !ok {
+			continue
+		}
+		// Parsed one at a time so the handle's raw fields stay paired with the parsed amount and date
+		parsed, _ := parseTransactions([]map[string]interface{}{tx})
+		if len(parsed) == 0 || parsed[0].Date.Before(cutoff) {
+			continue
+		}
+		txType, amount, txDate := parsed[0].Type, parsed[0].Amount, parsed[0].Date
+
+		key := strings.ToLower(handle)
+		f := flows[key]
+		if f == nil {
+			f = &flow{handle: handle}
+			flows[key] = f
+		}
+		switch txType {
+		case "send":
+			f.sent += amount
+			totalSent += amount
+		case "receive":
+			f.received += amount
+			totalReceived += amount
+		default:
+			continue
+		}
+		f.count++
+		if txDate.After(f.last) {
+			f.last = txDate
+		}
+	}
+
+	ordered := make([]*flow, 0, len(flows))
+	for _, f := range flows {
+		if f.count > 0 {
+			ordered = append(ordered, f)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		ni, nj := math.Abs(ordered[i].received-ordered[i].sent), math.Abs(ordered[j].received-ordered[j].sent)
+		if ni != nj {
+			return ni > nj
+		}
+		return ordered[i].handle < ordered[j].handle
+	})
+
+	counterparties := []map[string]interface{}{}
+	for _, f := range ordered {
+		net := f.received - f.sent
+		summary := fmt.Sprintf("You and %s are even.", f.handle)
+		if net < 0 {
+			summary = fmt.Sprintf("You've net-sent $%.2f to %s.", -net, f.handle)
+		} else if net > 0 {
+			summary = fmt.Sprintf("You've net-received $%.2f from %s.", net, f.handle)
+		}
+		counterparties = append(counterparties, map[string]interface{}{
+			"handle":        f.handle,
+			"sent":          fmt.Sprintf("%.2f", f.sent),
+			"received":      fmt.Sprintf("%.2f", f.received),
+			"net":           fmt.Sprintf("%+.2f", net),
+			"transfers":     f.count,
+			"last_transfer": f.last.Format("2006-01-02"),
+			"summary":       summary,
+		})
+	}
+	return counterparties, totalSent, totalReceived
+}
+
+// p2pCounterparty finds the @handle a transaction was sent to or received from
+// Checks the same fields as merchantName; an @ inside a word (an email) doesn't count
+func p2pCounterparty(tx map[string]interface{}) (string, bool) {
+	for _, field := range []string{"description", "counterparty", "note", "recipient", "sender"} {
+		value, _ := tx[field].(string)
+		if handle := findHandle(value); handle != "" {
+			return handle, true
+		}
+	}
+	return "", false
+}
+
+// findHandle returns the first @handle in text, or "" if there is none
+func findHandle(text string) string {
+	isHandleChar := func(c byte) bool {
+		return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-'
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && isHandleChar(text[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(text) && isHandleChar(text[end]) {
+			end++
+		}
+		if handle := strings.TrimRight(text[i:end], ".-"); len(handle) > 1 {
+			return handle
+		}
+	}
+	return ""
+}
+
+// generateMockP2PTransactions creates transfers with a few friends for the P2P demo
+func generateMockP2PTransactions(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	templates := []struct {
+		description string
+		amount      float64
+		txType      string
 	}{
-		{"Netflix Subscription", 15.99, 30},
-		{"Spotify Premium", 10.99, 30},
-		{"Amazon Prime", 14.99, 30},
-		{"Adobe Creative Cloud", 54.99, 30},
-		{"Planet Fitness", 24.99, 30},
-		{"New York Times Digital", 17.00, 30},
-		{"Hulu (No Ads)", 17.99, 30},
-		{"iCloud Storage 200GB", 2.99, 30},
-		{"GitHub Pro", 7.00, 30},
-		{"Dropbox Plus", 11.99, 30},
+		{"Payment to @bob", 40.00, "send"},
+		{"Rent share to @bob", 600.00, "send"},
+		{"Dinner split with @carol", 32.50, "send"},
+		{"Payment from @carol", 25.00, "receive"},
+		{"Payment from @alice", 75.00, "receive"},
+		{"Concert tickets to @alice", 85.00, "send"},
+	}
+
+	transactions := []map[string]interface{}{}
+	for i := 0; i < days/4+2; i++ {
+		template := templates[rng.Intn(len(templates))]
+		currency := pickMockCurrency(opts.Currency, rng)
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_mock_p2p_%d", i),
+			"type":        template.txType,
+			"amount":      convertMockAmount(template.amount, currency),
+			"description": template.description,
+			"date":        now.AddDate(0, 0, -rng.Intn(days)).Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    currency,
+		})
+	}
+	return transactions
+}
+
+// ============================================================================
+// CUSTOM TOOL: BUDGET VARIANCE
+// ============================================================================
+
+// createBudgetVarianceTool builds a tool that compares a month's spending with a planned budget
+// The classic budget-vs-actual report; the budget is passed inline so nothing is stored
+func createBudgetVarianceTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("budget_variance").
+		Description("Compare a month's actual spending per category with a planned budget (category to planned amount). Returns planned, actual and variance (amount and percent, positive = over budget) per category, the total variance, the worst overruns, and spending in categories the budget doesn't cover. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"budget": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Planned spend per category for the month, e.g. {\"Food & Dining\": 400, \"Transportation\": 150}",
+				"additionalProperties": tools.NumberProperty("Planned amount for the category"),
+			},
+			"month":            tools.StringProperty("Month to compare as YYYY-MM (default: current month)"),
+			"category_weights": categoryWeightsProperty(),
+			"timezone":         timezoneProperty(),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		}, "budget")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Budget          map[string]float64 `json:"budget"`
+				Month           string             `json:"month"`
+				CategoryWeights []categoryWeight   `json:"category_weights"`
+				Timezone        string             `json:"timezone"`
+				UseMock         bool               `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if len(params.Budget) == 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "budget must list at least one category",
+				}, nil
+			}
+			for category, planned := range params.Budget {
+				if planned < 0 {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("planned amount for %q can't be negative", category),
+					}, nil
+				}
+			}
+
+			loc, tzWarning := resolveTimezone(params.Timezone)
+			now := time.Now().In(loc)
+			monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+			if params.Month != "" {
+				parsed, err := time.ParseInLocation("2006-01", params.Month, loc)
+				if err != nil {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("invalid month %q, expected YYYY-MM", params.Month),
+					}, nil
+				}
+				if parsed.After(now) {
+					return &core.ToolResult{
+						Success: false,
+						Error:   fmt.Sprintf("month %q hasn't started yet", params.Month),
+					}, nil
+				}
+				monthStart = parsed
+			}
+			monthEnd := monthStart.AddDate(0, 1, 0)
+
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(int(now.Sub(monthStart).Hours()/24)+1, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for budget variance", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": monthStart.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in budget variance: %v", err)
+			}
+			inMonth := make([]Transaction, 0, len(parsed))
+			for _, tx := range parsed {
+				if !tx.Date.Before(monthStart) && tx.Date.Before(monthEnd) {
+					inMonth = append(inMonth, tx)
+				}
+			}
+			actual, _ := spendByCategory(inMonth, params.CategoryWeights)
+
+			result := budgetVariance(params.Budget, actual)
+			result["month"] = monthStart.Format("2006-01")
+			result["timezone"] = loc.String()
+			result["data_source"] = map[string]bool{"is_mock": params.UseMock}
+			result["generated_at"] = now.Format(time.RFC3339)
+			if now.Before(monthEnd) {
+				progress := now.Sub(monthStart).Hours() / monthEnd.Sub(monthStart).Hours() * 100
+				result["note"] = fmt.Sprintf("%.0f%% of the month has passed, so actuals are month-to-date against the full-month plan.", progress)
+			}
+			if tzWarning != "" {
+				result["timezone_warning"] = tzWarning
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// budgetVariance lines up planned and actual spend per category. Budget names match
+// actual categories case-insensitively; spending in categories the budget doesn't
+// list is reported as unbudgeted. Categories are ordered by variance, worst first.
+func budgetVariance(budget map[string]float64, actual map[string]float64) map[string]interface{} {
+	actualByKey := make(map[string]float64, len(actual))
+	for category, amount := range actual {
+		actualByKey[strings.ToLower(category)] += amount
+	}
+
+	type line struct {
+		category                  string
+		planned, actual, variance float64
+	}
+	lines := make([]line, 0, len(budget))
+	budgeted := make(map[string]bool, len(budget))
+	var totalPlanned, totalActual float64
+	for category, planned := range budget {
+		key := strings.ToLower(strings.TrimSpace(category))
+		budgeted[key] = true
+		spent := actualByKey[key]
+		totalPlanned += planned
+		totalActual += spent
+		lines = append(lines, line{category: category, planned: planned, actual: spent, variance: spent - planned})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].variance != lines[j].variance {
+			return lines[i].variance > lines[j].variance
+		}
+		return lines[i].category < lines[j].category
+	})
+
+	categories := []map[string]interface{}{}
+	worst := []map[string]interface{}{}
+	for _, l := range lines {
+		status := "on budget"
+		if l.variance > 0 {
+			status = "over"
+		} else if l.variance < 0 {
+			status = "under"
+		}
+		entry := map[string]interface{}{
+			"category": l.category,
+			"planned":  fmt.Sprintf("%.2f", l.planned),
+			"actual":   fmt.Sprintf("%.2f", l.actual),
+			"variance": fmt.Sprintf("%+.2f", l.variance),
+			"status":   status,
+		}
+		if l.planned > 0 {
+			entry["variance_percent"] = fmt.Sprintf("%+.1f%%", l.variance/l.planned*100)
+		}
+		categories = append(categories, entry)
+		if l.variance > 0 && len(worst) < 3 {
+			worst = append(worst, entry)
+		}
+	}
+
+	var missing []string
+	var unbudgetedTotal float64
+	for category, amount := range actual {
+		if !budgeted[strings.ToLower(category)] {
+			missing = append(missing, category)
+			unbudgetedTotal += amount
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return actual[missing[i]] > actual[missing[j]]
+	})
+	unbudgeted := []map[string]interface{}{}
+	for _, category := range missing {
+		unbudgeted = append(unbudgeted, map[string]interface{}{
+			"category": category,
+			"actual":   fmt.Sprintf("%.2f", actual[category]),
+		})
+	}
+
+	totalVariance := totalActual - totalPlanned
+	summary := fmt.Sprintf("You're $%.2f under your $%.2f budget.", -totalVariance, totalPlanned)
+	if totalVariance > 0 {
+		summary = fmt.Sprintf("You're $%.2f over your $%.2f budget.", totalVariance, totalPlanned)
+	}
+	if len(worst) > 0 {
+		summary += fmt.Sprintf(" Biggest overrun: %s (%s).", worst[0]["category"], worst[0]["variance"])
+	}
+	if unbudgetedTotal > 0 {
+		summary += fmt.Sprintf(" Another $%.2f went to categories not in the budget.", unbudgetedTotal)
+	}
+
+	result := map[string]interface{}{
+		"categories":       categories,
+		"worst_overruns":   worst,
+		"unbudgeted":       unbudgeted,
+		"total_planned":    fmt.Sprintf("%.2f", totalPlanned),
+		"total_actual":     fmt.Sprintf("%.2f", totalActual),
+		"total_variance":   fmt.Sprintf("%+.2f", totalVariance),
+		"unbudgeted_total": fmt.Sprintf("%.2f", unbudgetedTotal),
+		"summary":          summary,
+	}
+	if totalPlanned > 0 {
+		result["total_variance_percent"] = fmt.Sprintf("%+.1f%%", totalVariance/totalPlanned*100)
+	}
+	return result
+}
+
+// ============================================================================
+// CUSTOM TOOL: SMART WITHDRAWAL
+// ============================================================================
+
+// createSmartWithdrawalTool builds a tool that sizes a savings withdrawal to cover an upcoming shortfall
+// It never moves money itself; it returns a withdraw_savings payload for the user to confirm
+func createSmartWithdrawalTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("recommend_withdrawal").
+		Description("When predicted bills would take the wallet below a cushion, compute the smallest withdrawal from savings that covers the gap (rounded up), and return a withdraw_savings payload to confirm. Warns if the withdrawal would dip into the emergency-fund floor or exceed savings. Does not move any money. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"horizon_days":         tools.IntegerProperty("Days ahead to check for bills (default: 14)"),
+			"cushion":              tools.NumberProperty("Minimum wallet balance to keep (default: 100)"),
+			"emergency_fund_floor": tools.NumberProperty("Savings balance to keep untouched (default: 3 months of essential spending)"),
+			"round_to":             tools.IntegerProperty("Round the withdrawal up to a multiple of this amount (default: 10)"),
+			"current_balance":      tools.NumberProperty("Override the wallet balance instead of fetching it"),
+			"savings_balance":      tools.NumberProperty("Override the savings balance instead of fetching it"),
+			"currency":             tools.StringProperty("Currency of the balances (default: USD)"),
+			"essential_categories": essentialCategoriesProperty(),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				HorizonDays         int      `json:"horizon_days"`
+				Cushion             *float64 `json:"cushion"`
+				EmergencyFundFloor  *float64 `json:"emergency_fund_floor"`
+				RoundTo             int      `json:"round_to"`
+				CurrentBalance      *float64 `json:"current_balance"`
+				SavingsBalance      *float64 `json:"savings_balance"`
+				Currency            string   `json:"currency"`
+				EssentialCategories []string `json:"essential_categories"`
+				UseMock             bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.HorizonDays <= 0 {
+				params.HorizonDays = 14
+			}
+			cushion := 100.0
+			if params.Cushion != nil {
+				cushion = *params.Cushion
+			}
+			if params.RoundTo <= 0 {
+				params.RoundTo = 10
+			}
+			if params.Currency == "" {
+				params.Currency = "USD"
+			}
+
+			const historyDays = 90
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -historyDays)
+
+			var transactions []map[string]interface{}
+			balance, savings := mockWalletBalance, mockSavingsBalance
+			if params.UseMock {
+				transactions = append(generateMockSubscriptionTransactions(3, mockOptions{}),
+					generateMockTransactionsForAnalysis(historyDays, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for withdrawal recommendation", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err == nil && params.CurrentBalance == nil {
+					balance, err = fetchWalletBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err == nil && params.SavingsBalance == nil {
+					savings, err = fetchSavingsBalance(ctx, liminalExecutor, toolParams, params.Currency)
+				}
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+			if params.CurrentBalance != nil {
+				balance = *params.CurrentBalance
+			}
+			if params.SavingsBalance != nil {
+				savings = *params.SavingsBalance
+			}
+			floor := summarizeCashFlowWith(transactions, historyDays, resolveEssentials(params.EssentialCategories)).MonthlyEssentialSpend * 3
+			if params.EmergencyFundFloor != nil {
+				floor = *params.EmergencyFundFloor
+			}
+
+			// Walk bills and income in date order to find the lowest the wallet gets
+			lowest, running := balance, balance
+			var lowestDate time.Time
+			bills := []map[string]interface{}{}
+			for _, event := range predictCashEvents(transactions, cutoffDate, now, params.HorizonDays) {
+				running += event.Amount
+				if event.Kind == "bill" {
+					bills = append(bills, map[string]interface{}{
+						"date":              event.Date.Format("2006-01-02"),
+						"description":       event.Description,
+						"amount":            fmt.Sprintf("%.2f", -event.Amount),
+						"balance_after_due": fmt.Sprintf("%.2f", running),
+					})
+				}
+				if running < lowest {
+					lowest, lowestDate = running, event.Date
+				}
+			}
+
+			result := map[string]interface{}{
+				"current_balance":      fmt.Sprintf("%.2f", balance),
+				"savings_balance":      fmt.Sprintf("%.2f", savings),
+				"lowest_balance":       fmt.Sprintf("%.2f", lowest),
+				"cushion":              fmt.Sprintf("%.2f", cushion),
+				"emergency_fund_floor": fmt.Sprintf("%.2f", floor),
+				"upcoming_bills":       bills,
+				"data_source":          map[string]bool{"is_mock": params.UseMock},
+				"generated_at":         now.Format(time.RFC3339),
+			}
+			shortfall := cushion - lowest
+			if shortfall <= 0 {
+				result["withdrawal_needed"] = false
+				result["recommended_amount"] = "0.00"
+				result["message"] = fmt.Sprintf("No withdrawal needed: your wallet stays above $%.2f through the next %d days.", cushion, params.HorizonDays)
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
+
+			step := float64(params.RoundTo)
+			amount := math.Ceil(shortfall/step) * step
+			warnings := []string{}
+			if amount > savings {
+				amount = math.Floor(savings*100) / 100
+				warnings = append(warnings, fmt.Sprintf("Savings of $%.2f can't cover the full $%.2f shortfall; you'd still be $%.2f short.", savings, shortfall, shortfall-amount))
+			}
+			remaining := savings - amount
+			if remaining < floor {
+				warnings = append(warnings, fmt.Sprintf("This leaves $%.2f in savings, $%.2f below your $%.2f emergency-fund floor.", remaining, floor-remaining, floor))
+			}
+
+			result["withdrawal_needed"] = true
+			result["shortfall"] = fmt.Sprintf("%.2f", shortfall)
+			result["shortfall_date"] = lowestDate.Format("2006-01-02")
+			result["recommended_amount"] = fmt.Sprintf("%.2f", amount)
+			result["savings_after"] = fmt.Sprintf("%.2f", remaining)
+			result["floor_intact"] = remaining >= floor
+			result["warnings"] = warnings
+			result["withdraw_payload"] = map[string]interface{}{
+				"tool": "withdraw_savings",
+				"input": map[string]interface{}{
+					"amount":   fmt.Sprintf("%.2f", amount),
+					"currency": params.Currency,
+				},
+			}
+			result["message"] = fmt.Sprintf("Your wallet is projected to drop to $%.2f on %s. Withdrawing $%.2f from savings keeps it above your $%.2f cushion.",
+				lowest, lowestDate.Format("Jan 2"), amount, cushion)
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// ============================================================================
+// CUSTOM TOOL: DUPLICATE CHARGES
+// ============================================================================
+
+// createDuplicateChargeTool builds a tool that flags likely double charges at the same merchant
+// Amounts only need to match within a tolerance, so tips and currency rounding don't hide a duplicate
+func createDuplicateChargeTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("detect_duplicate_charges").
+		Description("Flag pairs of charges at the same merchant that are close in time and match in amount within a tolerance (absolute or percent, whichever is larger), with a confidence that each pair is a true duplicate. Pairs are discounted when the merchant always charges about the same amount, so a daily coffee isn't flagged. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":              tools.IntegerProperty("Number of days to scan (default: 30)"),
+			"amount_tolerance":  tools.NumberProperty("Largest amount difference, in currency units, still treated as a match (default: 0.50)"),
+			"tolerance_percent": tools.NumberProperty("Largest amount difference as a percent of the charge (default: 1)"),
+			"window_hours":      tools.IntegerProperty("Longest gap between the two charges, in hours (default: 48)"),
+			"min_confidence":    tools.NumberProperty("Only report pairs at or above this confidence, 0-1 (default: 0.5)"),
+			"use_mock":          tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days             int      `json:"days"`
+				AmountTolerance  *float64 `json:"amount_tolerance"`
+				TolerancePercent *float64 `json:"tolerance_percent"`
+				WindowHours      int      `json:"window_hours"`
+				MinConfidence    *float64 `json:"min_confidence"`
+				UseMock          bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 30
+			}
+			if params.WindowHours <= 0 {
+				params.WindowHours = 48
+			}
+			opts := duplicateOptions{
+				AmountTolerance:  0.50,
+				TolerancePercent: 1,
+				Window:           time.Duration(params.WindowHours) * time.Hour,
+				MinConfidence:    0.5,
+			}
+			if params.AmountTolerance != nil && *params.AmountTolerance >= 0 {
+				opts.AmountTolerance = *params.AmountTolerance
+			}
+			if params.TolerancePercent != nil && *params.TolerancePercent >= 0 {
+				opts.TolerancePercent = *params.TolerancePercent
+			}
+			if params.MinConfidence != nil {
+				opts.MinConfidence = math.Max(0, math.Min(1, *params.MinConfidence))
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = append(generateMockTransactionsForAnalysis(params.Days, mockOptions{}),
+					generateMockDuplicateCharges(params.Days, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for duplicate detection", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in duplicate detection: %v", err)
+			}
+
+			pairs := findDuplicateCharges(parsed, opts)
+			total := 0.0
+			for _, pair := range pairs {
+				total += pair.Second.Amount
+			}
+			flagged := []map[string]interface{}{}
+			for _, pair := range pairs {
+				flagged = append(flagged, map[string]interface{}{
+					"merchant":     pair.Second.Description,
+					"first_id":     pair.First.ID,
+					"second_id":    pair.Second.ID,
+					"first_date":   pair.First.Date.Format(time.RFC3339),
+					"second_date":  pair.Second.Date.Format(time.RFC3339),
+					"first_amount": fmt.Sprintf("%.2f", pair.First.Amount),
+					"amount":       fmt.Sprintf("%.2f", pair.Second.Amount),
+					"currency":     pair.Second.Currency,
+					"hours_apart":  math.Round(pair.Second.Date.Sub(pair.First.Date).Hours()*10) / 10,
+					"confidence":   math.Round(pair.Confidence*100) / 100,
+					"reason":       pair.Reason,
+				})
+			}
+
+			summary := fmt.Sprintf("No likely duplicate charges in the last %d days.", params.Days)
+			if len(pairs) > 0 {
+				summary = fmt.Sprintf("Found %d likely duplicate charge(s) in the last %d days, worth $%.2f if refunded. Check them with the merchant before disputing.",
+					len(pairs), params.Days, total)
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"duplicates":        flagged,
+					"potential_refund":  fmt.Sprintf("%.2f", total),
+					"amount_tolerance":  fmt.Sprintf("%.2f", opts.AmountTolerance),
+					"tolerance_percent": opts.TolerancePercent,
+					"window_hours":      params.WindowHours,
+					"summary":           summary,
+					"data_source":       map[string]bool{"is_mock": params.UseMock},
+					"generated_at":      now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// duplicateOptions tunes findDuplicateCharges
+type duplicateOptions struct {
+	AmountTolerance  float64       // absolute amount difference still treated as a match
+	TolerancePercent float64       // percent of the charge; the larger of the two tolerances applies
+	Window           time.Duration // longest gap between the two charges
+	MinConfidence    float64       // pairs below this confidence are dropped
+}
+
+// duplicatePair is two charges that look like the same purchase billed twice
+type duplicatePair struct {
+	First, Second Transaction
+	Confidence    float64
+	Reason        string
+}
+
+// minDuplicateHistory is how many charges a merchant needs before its usual
+// spread of amounts is trusted; with fewer, an identical amount counts for less
+const minDuplicateHistory = 4
+
+// findDuplicateCharges pairs each charge with the next one at the same merchant and currency.
+// Confidence multiplies three scores, each 0-1:
+//   - closeness: 1 for back-to-back charges, 0.5 at the window's edge
+//   - match: 1 for identical amounts, 0.75 at the tolerance's edge
+//   - distinctiveness: how unusual the match is for this merchant. A merchant whose
+//     charges always come out the same (a daily coffee) scores near 0, so routine
+//     repeat purchases aren't flagged; one whose amounts vary (groceries) scores near 1
+func findDuplicateCharges(transactions []Transaction, opts duplicateOptions) []duplicatePair {
+	byMerchant := make(map[string][]Transaction)
+	keys := []string{}
+	for _, tx := range transactions {
+		if tx.Type != "send" || tx.Amount <= 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(tx.Description)) + "|" + tx.Currency
+		if _, ok := byMerchant[key]; !ok {
+			keys = append(keys, key)
+		}
+		byMerchant[key] = append(byMerchant[key], tx)
 	}
 
-	// Add some irregular subscriptions
-	irregularSubs := []struct {
-		merchant  string
-		amount    float64
-		frequency int
-	}{
-		{"Annual Software License", 299.00, 365},
-		{"Quarterly Insurance", 450.00, 90},
-		{"Biweekly Meal Delivery", 89.99, 14},
+	pairs := []duplicatePair{}
+	for _, key := range keys {
+		charges := byMerchant[key]
+		if len(charges) < 2 {
+			continue
+		}
+		sort.Slice(charges, func(i, j int) bool { return charges[i].Date.Before(charges[j].Date) })
+
+		sum := 0.0
+		for _, tx := range charges {
+			sum += tx.Amount
+		}
+		average := sum / float64(len(charges))
+		variance := 0.0
+		for _, tx := range charges {
+			variance += (tx.Amount - average) * (tx.Amount - average)
+		}
+		stdDev := math.Sqrt(variance / float64(len(charges)))
+
+		for i := 1; i < len(charges); i++ {
+			first, second := charges[i-1], charges[i]
+			gap := second.Date.Sub(first.Date)
+			if gap > opts.Window {
+				continue
+			}
+			allowed := math.Max(opts.AmountTolerance, math.Max(first.Amount, second.Amount)*opts.TolerancePercent/100)
+			diff := math.Abs(second.Amount - first.Amount)
+			if diff > allowed+0.005 {
+				continue
+			}
+
+			closeness := 1.0
+			if opts.Window > 0 {
+				closeness = 1 - 0.5*float64(gap)/float64(opts.Window)
+			}
+			match := 1.0
+			if allowed > 0 {
+				match = 1 - 0.25*diff/allowed
+			}
+			distinctiveness := 0.75
+			reason := "same merchant and amount in quick succession"
+			if len(charges) >= minDuplicateHistory {
+				// How far apart this merchant's charges usually are, relative to this pair
+				distinctiveness = math.Max(0, math.Min(1, (stdDev-diff)/math.Max(stdDev, allowed)))
+				if distinctiveness < 0.3 {
+					reason = "this merchant usually charges about the same amount, so this may be a repeat purchase"
+				} else {
+					reason = fmt.Sprintf("amounts match far more closely than this merchant's usual $%.2f spread", stdDev)
+				}
+			}
+
+			confidence := closeness * match * distinctiveness
+			if confidence < opts.MinConfidence {
+				continue
+			}
+			pairs = append(pairs, duplicatePair{First: first, Second: second, Confidence: confidence, Reason: reason})
+		}
 	}
 
-	subscriptions = append(subscriptions, irregularSubs...)
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Confidence > pairs[j].Confidence })
+	return pairs
+}
 
-	// Select 5-8 random subscriptions for this user
-	numSubs := 5 + rand.Intn(4)
-	selectedSubs := make([]struct {
-		merchant  string
-		amount    float64
-		frequency int
-	}, numSubs)
-	for i := 0; i < numSubs; i++ {
-		selectedSubs[i] = subscriptions[rand.Intn(len(subscriptions))]
+// generateMockDuplicateCharges creates a few double charges plus a daily coffee habit
+// The coffees repeat at a fixed price and shouldn't be flagged
+func generateMockDuplicateCharges(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	now := time.Now()
+	currency := pickMockCurrency(opts.Currency, rng)
+	transactions := []map[string]interface{}{}
+	add := func(description string, amount float64, date time.Time) {
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_mock_dup_%d", len(transactions)),
+			"type":        "send",
+			"amount":      convertMockAmount(amount, currency),
+			"description": description,
+			"date":        date.Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    currency,
+		})
 	}
 
-	// Generate recurring transactions for each subscription
-	daysToGenerate := months * 30
-	for _, sub := range selectedSubs {
-		numOccurrences := daysToGenerate / sub.frequency
-		for j := 0; j < numOccurrences; j++ {
-			daysAgo := j * sub.frequency
-			if daysAgo > daysToGenerate {
-				break
+	// A grocery run billed twice, the second with a rounding difference
+	grocery := now.AddDate(0, 0, -rng.Intn(days))
+	add("Whole Foods Market", 72.48, grocery)
+	add("Whole Foods Market", 72.50, grocery.Add(3*time.Hour))
+
+	// A restaurant charge re-posted the next day with the tip added
+	dinner := now.AddDate(0, 0, -rng.Intn(days))
+	add("Chipotle Mexican Grill", 41.20, dinner)
+	add("Chipotle Mexican Grill", 41.55, dinner.Add(20*time.Hour))
+
+	// Morning and afternoon coffee at a fixed price
+	for i := 0; i < 10 && i < days; i++ {
+		day := now.AddDate(0, 0, -i)
+		add("Blue Bottle Coffee", 5.75, day.Add(-8*time.Hour))
+		add("Blue Bottle Coffee", 5.75, day.Add(-2*time.Hour))
+	}
+	return transactions
+}
+
+// ============================================================================
+// CUSTOM TOOL: SAVINGS CHALLENGE
+// ============================================================================
+
+// challengeSkipShare is the share of habit purchases each difficulty asks the user to skip
+var challengeSkipShare = map[string]float64{
+	"easy":   0.15,
+	"medium": 0.25,
+	"hard":   0.5,
+}
+
+// createSavingsChallengeTool builds a tool that turns a small spending habit into a savings challenge
+// Builds on latte_factor: same habit scan, but the output is a week-by-week plan instead of a projection
+func createSavingsChallengeTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("create_savings_challenge").
+		Description("Propose a personalized savings challenge from the user's discretionary habits: pick the frequent small purchase costing the most (skipping bills and subscriptions), ask the user to skip a share of those purchases, and return a week-by-week schedule with the projected total saved. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"weeks":       tools.IntegerProperty("Length of the challenge in weeks (default: 4)"),
+			"difficulty":  tools.StringEnumProperty("How much of the habit to skip (default: medium)", "easy", "medium", "hard"),
+			"merchant":    tools.StringProperty("Build the challenge around this merchant instead of the top habit"),
+			"days":        tools.IntegerProperty("Number of days of history to scan (default: 90)"),
+			"min_count":   tools.IntegerProperty("Minimum purchases at one merchant to count as a habit (default: 4)"),
+			"max_average": tools.NumberProperty("Maximum average purchase to count as small (default: 25)"),
+			"use_mock":    tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Weeks      int     `json:"weeks"`
+				Difficulty string  `json:"difficulty"`
+				Merchant   string  `json:"merchant"`
+				Days       int     `json:"days"`
+				MinCount   int     `json:"min_count"`
+				MaxAverage float64 `json:"max_average"`
+				UseMock    bool    `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Weeks <= 0 {
+				params.Weeks = 4
+			}
+			if _, ok := challengeSkipShare[params.Difficulty]; !ok {
+				params.Difficulty = "medium"
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			if params.MinCount <= 0 {
+				params.MinCount = 4
+			}
+			if params.MaxAverage <= 0 {
+				params.MaxAverage = 25
 			}
 
-			txDate := now.AddDate(0, 0, -daysAgo)
-			// Add small variance to amounts (±2%) to simulate real-world pricing variations
-			variance := 0.98 + rand.Float64()*0.04
-			amount := math.Round(sub.amount*variance*100) / 100
+			now := time.Now()
+			cutoffDate := now.AddDate(0, 0, -params.Days)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for savings challenge", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
 
-			transactions = append(transactions, map[string]interface{}{
-				"id":          fmt.Sprintf("tx_sub_%s_%d", sub.merchant, j),
-				"type":        "send",
-				"amount":      amount,
-				"description": sub.merchant,
-				"date":        txDate.Format(time.RFC3339),
-				"status":      "completed",
-				"currency":    "USD",
+			// Bills and subscriptions can't be skipped a few times a week, so they're left out.
+			// Recurring detection isn't used here: a daily coffee is regular too
+			habits := []map[string]interface{}{}
+			for _, habit := range findSmallHabits(transactions, params.MinCount, params.MaxAverage) {
+				merchant, _ := habit["merchant"].(string)
+				if isKnownSubscription(merchant) || strings.EqualFold(categorizeTransaction(merchant), "Bills & Utilities") {
+					continue
+				}
+				if params.Merchant != "" && !strings.Contains(strings.ToLower(merchant), strings.ToLower(params.Merchant)) {
+					continue
+				}
+				habits = append(habits, habit)
+			}
+
+			result := map[string]interface{}{
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			if len(habits) == 0 {
+				result["challenge"] = nil
+				result["message"] = fmt.Sprintf("No discretionary habit with %d+ purchases averaging under $%.2f in the last %d days to build a challenge around.",
+					params.MinCount, params.MaxAverage, params.Days)
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
+
+			habit := habits[0]
+			merchant, _ := habit["merchant"].(string)
+			count, _ := habit["count"].(int)
+			average, _ := habit["average"].(float64)
+			challenge := planSavingsChallenge(merchant, float64(count)/float64(params.Days)*7, average,
+				challengeSkipShare[params.Difficulty], params.Weeks, now)
+			challenge["difficulty"] = params.Difficulty
+
+			alternatives := []string{}
+			for _, other := range habits[1:] {
+				if len(alternatives) == 3 {
+					break
+				}
+				alternatives = append(alternatives, fmt.Sprint(other["merchant"]))
+			}
+
+			result["challenge"] = challenge
+			result["projected_savings"] = challenge["projected_savings"]
+			result["alternatives"] = alternatives
+			result["message"] = challenge["title"]
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// planSavingsChallenge lays out a skip target for each week of the challenge.
+// The cumulative target is rounded rather than each week, so a habit that happens
+// less than weekly still gets spread-out skips instead of one every week.
+func planSavingsChallenge(merchant string, perWeek, average, skipShare float64, weeks int, start time.Time) map[string]interface{} {
+	skipsByWeek := make([]int, weeks)
+	totalSkips := 0
+	for week := 1; week <= weeks; week++ {
+		skipsByWeek[week-1] = int(math.Round(perWeek*skipShare*float64(week))) - totalSkips
+		totalSkips += skipsByWeek[week-1]
+	}
+	// Every challenge asks for at least one skip
+	if totalSkips == 0 {
+		skipsByWeek[0], totalSkips = 1, 1
+	}
+
+	schedule := []map[string]interface{}{}
+	saved, usualTotal := 0.0, 0
+	for week := 1; week <= weeks; week++ {
+		skips := skipsByWeek[week-1]
+		// Usual purchases also accumulate, so they stay in step with the skips
+		usual := int(math.Round(perWeek*float64(week))) - usualTotal
+		if usual < skips {
+			usual = skips
+		}
+		usualTotal += usual
+		saved += float64(skips) * average
+		schedule = append(schedule, map[string]interface{}{
+			"week":              week,
+			"starts":            start.AddDate(0, 0, (week-1)*7).Format("2006-01-02"),
+			"usual_purchases":   usual,
+			"skip":              skips,
+			"allowed_purchases": usual - skips,
+			"saves":             fmt.Sprintf("%.2f", float64(skips)*average),
+			"cumulative_saved":  fmt.Sprintf("%.2f", saved),
+		})
+	}
+
+	weekly := saved / float64(weeks)
+	title := fmt.Sprintf("Skip %d of your ~%d %s purchases over the next %d weeks and save $%.2f (about $%.0f a week).",
+		totalSkips, usualTotal, merchant, weeks, saved, weekly)
+	return map[string]interface{}{
+		"title":              title,
+		"merchant":           merchant,
+		"average_purchase":   fmt.Sprintf("%.2f", average),
+		"purchases_per_week": math.Round(perWeek*10) / 10,
+		"weeks":              weeks,
+		"total_skips":        totalSkips,
+		"weekly_savings":     fmt.Sprintf("%.2f", weekly),
+		"projected_savings":  fmt.Sprintf("%.2f", saved),
+		"annualized_savings": fmt.Sprintf("%.2f", weekly*52),
+		"schedule":           schedule,
+	}
+}
+
+// ============================================================================
+// CUSTOM TOOL: FX FEES
+// ============================================================================
+
+// fxFeeFields are the transaction fields checked, in order, for a fee the platform reported
+var fxFeeFields = []string{"fx_fee", "fee", "fee_amount"}
+
+// createFXFeeTool builds a tool that totals what currency conversion cost the user
+// Uses a reported fee when the transaction carries one, otherwise estimates it from fee_rate
+func createFXFeeTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("analyze_fx_fees").
+		Description("Find transactions in a currency other than the user's primary currency and total the currency conversion fees paid: the reported fee when a transaction has one, otherwise an estimate at fee_rate. Returns a per-currency breakdown (count, volume, fees) and the total FX cost in the primary currency, converted with exchange_rates. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"days":             tools.IntegerProperty("Number of days to analyze (default: 90)"),
+			"primary_currency": tools.StringProperty("The user's home currency (default: the currency most transactions use)"),
+			"fee_rate":         tools.NumberProperty("Percent fee assumed when a transaction reports none (default: 1)"),
+			"exchange_rates":   exchangeRatesProperty(),
+			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Days            int                `json:"days"`
+				PrimaryCurrency string             `json:"primary_currency"`
+				FeeRate         *float64           `json:"fee_rate"`
+				ExchangeRates   map[string]float64 `json:"exchange_rates"`
+				UseMock         bool               `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Days <= 0 {
+				params.Days = 90
+			}
+			feeRate := 1.0
+			if params.FeeRate != nil && *params.FeeRate >= 0 {
+				feeRate = *params.FeeRate
+			}
+
+			now := time.Now()
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockFXTransactions(params.Days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for FX fees", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -params.Days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
+
+			primary := strings.ToUpper(strings.TrimSpace(params.PrimaryCurrency))
+			if primary == "" {
+				primary = dominantCurrency(transactions)
+			}
+			rates := exchangeRates(primary, params.UseMock, params.ExchangeRates)
+
+			// Fees are in each currency's own units, so order by their primary-currency value;
+			// currencies without a rate can't be compared and go last
+			breakdown := summarizeFXFees(transactions, primary, feeRate)
+			sort.SliceStable(breakdown, func(i, j int) bool {
+				rateI, okI := rates[breakdown[i].Currency]
+				rateJ, okJ := rates[breakdown[j].Currency]
+				if okI != okJ {
+					return okI
+				}
+				return okI && breakdown[i].Fees/rateI > breakdown[j].Fees/rateJ
 			})
+			total := 0.0
+			currencies := []map[string]interface{}{}
+			unconverted := []string{}
+			for _, fx := range breakdown {
+				entry := map[string]interface{}{
+					"currency":       fx.Currency,
+					"count":          fx.Count,
+					"volume":         fmt.Sprintf("%.2f", fx.Volume),
+					"fees":           fmt.Sprintf("%.2f", fx.Fees),
+					"reported_fees":  fx.Reported,
+					"estimated_fees": fx.Count - fx.Reported,
+				}
+				if rate, ok := rates[fx.Currency]; ok {
+					entry["fees_in_primary"] = fmt.Sprintf("%.2f", fx.Fees/rate)
+					total += fx.Fees / rate
+				} else {
+					unconverted = append(unconverted, fx.Currency)
+				}
+				currencies = append(currencies, entry)
+			}
+
+			summary := fmt.Sprintf("No transactions outside %s in the last %d days, so no FX fees.", primary, params.Days)
+			if len(breakdown) > 0 {
+				summary = fmt.Sprintf("You paid about %.2f %s in currency conversion fees across %d currencies in the last %d days.",
+					total, primary, len(breakdown), params.Days)
+				if len(unconverted) > 0 {
+					summary += fmt.Sprintf(" Fees in %s aren't included; pass exchange_rates to convert them.", strings.Join(unconverted, ", "))
+				}
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"primary_currency":       primary,
+					"fee_rate":               feeRate,
+					"total_fx_fees":          fmt.Sprintf("%.2f", total),
+					"by_currency":            currencies,
+					"unconverted_currencies": unconverted,
+					"summary":                summary,
+					"data_source":            map[string]bool{"is_mock": params.UseMock},
+					"generated_at":           now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// exchangeRatesProperty is the shared schema for the exchange_rates param
+func exchangeRatesProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 "object",
+		"description":          "Units of each currency per 1 unit of the primary currency, e.g. {\"EUR\": 0.92, \"JPY\": 150}, used to convert amounts into the primary currency",
+		"additionalProperties": tools.NumberProperty("Units of this currency per 1 primary"),
+	}
+}
+
+// exchangeRates resolves units of each currency per 1 primary. Mock runs start from
+// the mock rates rebased on primary; overrides win. Live data has no rate source, so
+// only overrides are known there and callers must handle a missing currency.
+func exchangeRates(primary string, useMock bool, overrides map[string]float64) map[string]float64 {
+	rates := make(map[string]float64)
+	if useMock {
+		if base := mockCurrencyRates[primary]; base > 0 {
+			for currency, rate := range mockCurrencyRates {
+				rates[currency] = rate / base
+			}
+		}
+	}
+	for currency, rate := range overrides {
+		if rate > 0 {
+			rates[strings.ToUpper(currency)] = rate
+		}
+	}
+	rates[primary] = 1
+	return rates
+}
+
+// fxCurrencySummary totals foreign-currency activity for one currency, in that currency
+type fxCurrencySummary struct {
+	Currency string
+	Count    int
+	Reported int // transactions whose fee came from a fee field rather than the estimate
+	Volume   float64
+	Fees     float64
+}
+
+// summarizeFXFees totals volume and fees per non-primary currency, ordered by currency code.
+// Raw maps are used rather than parseTransactions because the fee fields aren't parsed there.
+func summarizeFXFees(transactions []map[string]interface{}, primary string, feeRate float64) []fxCurrencySummary {
+	byCurrency := make(map[string]*fxCurrencySummary)
+	for _, tx := range transactions {
+		status, _ := tx["status"].(string)
+		currency, _ := tx["currency"].(string)
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if skippedTransactionStatuses[strings.ToLower(status)] || currency == "" || currency == primary {
+			continue
+		}
+		amount, err := parseAmount(tx["amount"])
+		if err != nil || amount == 0 {
+			continue
+		}
+		amount = math.Abs(amount)
+
+		summary, ok := byCurrency[currency]
+		if !ok {
+			summary = &fxCurrencySummary{Currency: currency}
+			byCurrency[currency] = summary
+		}
+		summary.Count++
+		summary.Volume += amount
+
+		fee, reported := 0.0, false
+		for _, field := range fxFeeFields {
+			if value, ok := tx[field]; ok {
+				if parsed, err := parseAmount(value); err == nil {
+					fee, reported = math.Abs(parsed), true
+					break
+				}
+			}
 		}
+		if reported {
+			summary.Reported++
+		} else {
+			fee = amount * feeRate / 100
+		}
+		summary.Fees += fee
 	}
 
-	// Add some one-time purchases to make the data more realistic
-	oneTimePurchases := []string{
-		"Whole Foods Market",
-		"Target Store",
-		"Uber Ride",
-		"Amazon.com",
-		"Starbucks Coffee",
-		"Gas Station",
+	summaries := []fxCurrencySummary{}
+	for _, summary := range byCurrency {
+		summaries = append(summaries, *summary)
 	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Currency < summaries[j].Currency })
+	return summaries
+}
 
-	for i := 0; i < 20; i++ {
-		purchase := oneTimePurchases[rand.Intn(len(oneTimePurchases))]
-		daysAgo := rand.Intn(daysToGenerate)
-		txDate := now.AddDate(0, 0, -daysAgo)
-		amount := 10.00 + rand.Float64()*90.00
-
-		transactions = append(transactions, map[string]interface{}{
-			"id":          fmt.Sprintf("tx_once_%d", i),
-			"type":        "send",
-			"amount":      math.Round(amount*100) / 100,
-			"description": purchase,
-			"date":        txDate.Format(time.RFC3339),
-			"status":      "completed",
-			"currency":    "USD",
-		})
+// dominantCurrency returns the currency most transactions use, defaulting to USD
+func dominantCurrency(transactions []map[string]interface{}) string {
+	counts := make(map[string]int)
+	best := defaultMockCurrency
+	for _, tx := range transactions {
+		currency, _ := tx["currency"].(string)
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if currency == "" {
+			continue
+		}
+		counts[currency]++
+		if counts[currency] > counts[best] || (counts[currency] == counts[best] && currency < best) {
+			best = currency
+		}
 	}
+	return best
+}
 
+// generateMockFXTransactions creates mostly-USD activity with some purchases abroad
+// About half of the foreign purchases report their fee, the rest leave it to the estimate
+func generateMockFXTransactions(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	transactions := generateMockTransactionsForAnalysis(days, opts)
+	foreign := []string{"EUR", "GBP", "JPY"}
+	for _, tx := range transactions {
+		if tx["type"] != "send" || rng.Float64() >= 0.25 {
+			continue
+		}
+		currency := foreign[rng.Intn(len(foreign))]
+		amount, _ := tx["amount"].(float64)
+		converted := convertMockAmount(amount, currency)
+		tx["amount"] = converted
+		tx["currency"] = currency
+		if rng.Intn(2) == 0 {
+			tx["fx_fee"] = convertMockAmount(amount*0.015, currency)
+		}
+	}
 	return transactions
 }
 
 // ============================================================================
-// CUSTOM TOOL: SPENDING ANALYZER
+// CUSTOM TOOLS: GOALS DASHBOARD
 // ============================================================================
 
-// createSpendingAnalyzerTool builds a tool that analyzes spending patterns
-// Returns insights on categories, velocity, and cash flow
-func createSpendingAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool {
-	return tools.New("analyze_spending").
-		Description("Analyze the user's spending patterns over a specified time period. Returns insights about spending velocity, categories, and trends. Uses mock data by default for demo purposes.").
+// storedGoal is a savings goal remembered in the per-user store
+// MonthlyContribution is what the user has committed to put in each month
+type storedGoal struct {
+	Name                string  `json:"name"`
+	Target              float64 `json:"target"`
+	Current             float64 `json:"current"`
+	MonthlyContribution float64 `json:"monthly_contribution"`
+	TargetDate          string  `json:"target_date,omitempty"`
+	SetAt               string  `json:"set_at"`
+}
+
+// createSaveGoalTool builds a tool that adds, updates or removes one of the user's stored savings goals
+func createSaveGoalTool() core.Tool {
+	return tools.New("save_savings_goal").
+		Description("Save a savings goal for the user, or update the one with the same name: target, amount saved so far, monthly contribution and optional target date. Set remove to delete it. Goals are remembered across conversations and summarized by goals_dashboard.").
 		Schema(tools.ObjectSchema(map[string]interface{}{
-			"days":     tools.IntegerProperty("Number of days to analyze (default: 30)"),
-			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
-		})).
+			"name":                 tools.StringProperty("Goal name, e.g. 'Emergency fund'"),
+			"target":               tools.NumberProperty("Target amount for the goal"),
+			"current":              tools.NumberProperty("Amount already saved toward the goal (default: 0)"),
+			"monthly_contribution": tools.NumberProperty("Amount the user plans to add each month (default: 0)"),
+			"target_date":          tools.StringProperty("Date to reach the goal by (YYYY-MM-DD, optional)"),
+			"remove":               tools.BoolProperty("Delete the goal with this name instead of saving it"),
+		}, "name")).
 		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
-			// Parse input parameters
 			var params struct {
-				Days    int  `json:"days"`
-				UseMock bool `json:"use_mock"`
+				Name                string  `json:"name"`
+				Target              float64 `json:"target"`
+				Current             float64 `json:"current"`
+				MonthlyContribution float64 `json:"monthly_contribution"`
+				TargetDate          string  `json:"target_date"`
+				Remove              bool    `json:"remove"`
 			}
 			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
-				// Default to mock mode
-				params.UseMock = true
-				params.Days = 30
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
 			}
-
-			// Default to 30 days if not specified
-			if params.Days == 0 {
-				params.Days = 30
+			params.Name = strings.TrimSpace(params.Name)
+			if params.Name == "" {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "name is required",
+				}, nil
 			}
 
-			var transactions []map[string]interface{}
-
-			// STEP 1: Get transaction data (mock or real)
-			if params.UseMock {
-				// Generate mock transactions
-				transactions = generateMockTransactionsForAnalysis(params.Days)
-				log.Printf("📊 Generated %d mock transactions for analysis", len(transactions))
-			} else {
-				// Fetch real transactions from Liminal API
-				txRequest := map[string]interface{}{
-					"limit": 100,
-				}
-				txRequestJSON, _ := json.Marshal(txRequest)
-
-				txResponse, err := liminalExecutor.Execute(ctx, &core.ExecuteRequest{
-					UserID:    toolParams.UserID,
-					Tool:      "get_transactions",
-					Input:     txRequestJSON,
-					RequestID: toolParams.RequestID,
+			if params.Remove {
+				removed := ""
+				users.update(toolParams.UserID, func(data *userData) {
+					if i := goalIndex(data.Goals, params.Name); i >= 0 {
+						removed = data.Goals[i].Name
+						data.Goals = append(data.Goals[:i:i], data.Goals[i+1:]...)
+					}
 				})
-				if err != nil {
+				if removed == "" {
 					return &core.ToolResult{
 						Success: false,
-						Error:   fmt.Sprintf("failed to fetch transactions: %v", err),
+						Error:   fmt.Sprintf("no saved goal named %q", params.Name),
 					}, nil
 				}
+				return &core.ToolResult{
+					Success: true,
+					Data: map[string]interface{}{
+						"removed": removed,
+						"message": fmt.Sprintf("Removed the %s goal", removed),
+					},
+				}, nil
+			}
 
-				if !txResponse.Success {
+			if params.Target <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "target must be greater than 0",
+				}, nil
+			}
+			if params.Current < 0 || params.MonthlyContribution < 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "current and monthly_contribution can't be negative",
+				}, nil
+			}
+			if params.TargetDate != "" {
+				if _, err := time.Parse("2006-01-02", params.TargetDate); err != nil {
 					return &core.ToolResult{
 						Success: false,
-						Error:   fmt.Sprintf("transaction fetch failed: %s", txResponse.Error),
+						Error:   "target_date must be YYYY-MM-DD",
 					}, nil
 				}
-
-				// Parse transaction data
-				var txData map[string]interface{}
-				if err := json.Unmarshal(txResponse.Data, &txData); err == nil {
-					if txArray, ok := txData["transactions"].([]interface{}); ok {
-						for _, tx := range txArray {
-							if txMap, ok := tx.(map[string]interface{}); ok {
-								transactions = append(transactions, txMap)
-							}
-						}
-					}
-				}
 			}
 
-			// STEP 2: Analyze the data
-			analysis := analyzeTransactions(transactions, params.Days)
+			goal := storedGoal{
+				Name:                params.Name,
+				Target:              params.Target,
+				Current:             params.Current,
+				MonthlyContribution: params.MonthlyContribution,
+				TargetDate:          params.TargetDate,
+				SetAt:               time.Now().Format(time.RFC3339),
+			}
+			count := 0
+			users.update(toolParams.UserID, func(data *userData) {
+				// Copy before writing so earlier get() snapshots never see the change
+				goals := append([]storedGoal(nil), data.Goals...)
+				if i := goalIndex(goals, goal.Name); i >= 0 {
+					goals[i] = goal
+				} else {
+					goals = append(goals, goal)
+				}
+				data.Goals = goals
+				count = len(goals)
+			})
 
-			// STEP 3: Return insights
 			result := map[string]interface{}{
-				"period_days":        params.Days,
-				"total_transactions": len(transactions),
-				"analysis":           analysis,
-				"data_source":        map[string]bool{"is_mock": params.UseMock},
-				"generated_at":       time.Now().Format(time.RFC3339),
+				"goal":       goal,
+				"goal_count": count,
+				"message":    fmt.Sprintf("Saved the %s goal: $%.2f of $%.2f", goal.Name, goal.Current, goal.Target),
+			}
+			if warning := sharedStateWarning(toolParams.UserID); warning != "" {
+				result["warning"] = warning
 			}
-
 			return &core.ToolResult{
 				Success: true,
 				Data:    result,
@@ -469,265 +11400,438 @@ func createSpendingAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool {
 		Build()
 }
 
-// analyzeTransactions processes transaction data and returns spending insights
-// Calculates totals, categories, velocity, and generates actionable insights
-func analyzeTransactions(transactions []map[string]interface{}, days int) map[string]interface{} {
-	if len(transactions) == 0 {
-		return map[string]interface{}{
-			"summary": "No transactions found in the specified period",
+// goalIndex finds a goal by case-insensitive name, or -1
+func goalIndex(goals []storedGoal, name string) int {
+	for i, goal := range goals {
+		if strings.EqualFold(goal.Name, name) {
+			return i
 		}
 	}
+	return -1
+}
 
-	// Calculate basic metrics
-	var totalSpent, totalReceived float64
-	var spendCount, receiveCount int
-	categorySpending := make(map[string]float64)
-	categoryCount := make(map[string]int)
+// createGoalsDashboardTool builds a tool that reports progress on every stored goal at once
+func createGoalsDashboardTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("goals_dashboard").
+		Description("Summarize every savings goal saved with save_savings_goal: progress, ETA at the current monthly contribution, and the monthly amount needed to hit each target date, plus the total committed each month across goals. Warns when the commitments exceed the user's monthly surplus (income minus spending). Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"monthly_surplus": tools.NumberProperty("Override the monthly surplus instead of estimating it from the last 90 days"),
+			"use_mock":        tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				MonthlySurplus *float64 `json:"monthly_surplus"`
+				UseMock        bool     `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
 
-	for _, tx := range transactions {
-		txType, _ := tx["type"].(string)
-		amount, _ := tx["amount"].(float64)
-		description, _ := tx["description"].(string)
+			goals := users.get(toolParams.UserID).Goals
+			if len(goals) == 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "no savings goals saved; use save_savings_goal first",
+				}, nil
+			}
 
-		category := categorizeTransaction(description)
+			const historyDays = 90
+			now := time.Now()
+			surplus := 0.0
+			if params.MonthlySurplus != nil {
+				surplus = *params.MonthlySurplus
+			} else {
+				var transactions []map[string]interface{}
+				if params.UseMock {
+					transactions = generateMockTransactionsForAnalysis(historyDays, mockOptions{})
+					log.Printf("📊 Generated %d mock transactions for goals dashboard", len(transactions))
+				} else {
+					var err error
+					transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+						"limit":      500,
+						"start_date": now.AddDate(0, 0, -historyDays).Format("2006-01-02"),
+					})
+					if err != nil {
+						return toolErrorResult(err), nil
+					}
+				}
+				flow := summarizeCashFlow(transactions, historyDays)
+				surplus = flow.MonthlyIncome - flow.MonthlySpend
+			}
 
-		switch txType {
-		case "send":
-			totalSpent += amount
-			spendCount++
-			categorySpending[category] += amount
-			categoryCount[category]++
-		case "receive":
-			totalReceived += amount
-			receiveCount++
-		}
-	}
+			rows := []map[string]interface{}{}
+			committed, saved, targeted := 0.0, 0.0, 0.0
+			offTrack := []string{}
+			for _, goal := range goals {
+				row := goalStatus(goal, now)
+				if onTrack, ok := row["on_track"].(bool); ok && !onTrack {
+					offTrack = append(offTrack, goal.Name)
+				}
+				committed += goal.MonthlyContribution
+				saved += math.Min(goal.Current, goal.Target)
+				targeted += goal.Target
+				rows = append(rows, row)
+			}
 
-	avgDailySpend := totalSpent / float64(days)
-	netCashFlow := totalReceived - totalSpent
+			warnings := []string{}
+			if committed > surplus {
+				warnings = append(warnings, fmt.Sprintf("You've committed $%.2f a month to goals but only have about $%.2f left over each month. Trim contributions or spending to close the $%.2f gap.",
+					committed, math.Max(surplus, 0), committed-surplus))
+			}
+			if len(offTrack) > 0 {
+				warnings = append(warnings, fmt.Sprintf("Behind schedule for the target date: %s.", strings.Join(offTrack, ", ")))
+			}
+			if warning := sharedStateWarning(toolParams.UserID); warning != "" {
+				warnings = append(warnings, warning)
+			}
 
-	// Find top spending categories
-	type categoryInfo struct {
-		name       string
-		amount     float64
-		count      int
-		percentage float64
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"goals":                   rows,
+					"total_committed_monthly": fmt.Sprintf("%.2f", committed),
+					"monthly_surplus":         fmt.Sprintf("%.2f", surplus),
+					"total_saved":             fmt.Sprintf("%.2f", saved),
+					"total_target":            fmt.Sprintf("%.2f", targeted),
+					"overall_progress":        math.Round(saved/targeted*1000) / 10,
+					"warnings":                warnings,
+					"summary": fmt.Sprintf("%d goals, %.0f%% funded overall ($%.2f of $%.2f), with $%.2f committed each month.",
+						len(goals), saved/targeted*100, saved, targeted, committed),
+					"data_source":  map[string]bool{"is_mock": params.UseMock && params.MonthlySurplus == nil},
+					"generated_at": now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
+
+// goalStatus reports one goal's progress, ETA at its monthly contribution and,
+// when it has a target date, the contribution needed to get there on time
+func goalStatus(goal storedGoal, now time.Time) map[string]interface{} {
+	remaining := math.Max(goal.Target-goal.Current, 0)
+	row := map[string]interface{}{
+		"name":                 goal.Name,
+		"target":               fmt.Sprintf("%.2f", goal.Target),
+		"current":              fmt.Sprintf("%.2f", goal.Current),
+		"remaining":            fmt.Sprintf("%.2f", remaining),
+		"progress_percent":     math.Round(math.Min(goal.Current/goal.Target, 1)*1000) / 10,
+		"monthly_contribution": fmt.Sprintf("%.2f", goal.MonthlyContribution),
 	}
-	categories := []categoryInfo{}
-	for name, amount := range categorySpending {
-		percentage := 0.0
-		if totalSpent > 0 {
-			percentage = (amount / totalSpent) * 100
-		}
-		categories = append(categories, categoryInfo{
-			name:       name,
-			amount:     amount,
-			count:      categoryCount[name],
-			percentage: percentage,
-		})
+	if remaining == 0 {
+		row["status"] = "complete"
+		return row
 	}
-	// Sort by amount (highest first)
-	sort.Slice(categories, func(i, j int) bool {
-		return categories[i].amount > categories[j].amount
-	})
 
-	// Take top 5 categories
-	topCategories := []map[string]interface{}{}
-	for i := 0; i < len(categories) && i < 5; i++ {
-		topCategories = append(topCategories, map[string]interface{}{
-			"category":   categories[i].name,
-			"amount":     fmt.Sprintf("%.2f", categories[i].amount),
-			"count":      categories[i].count,
-			"percentage": fmt.Sprintf("%.1f%%", categories[i].percentage),
-		})
+	if goal.MonthlyContribution > 0 {
+		months := int(math.Ceil(remaining / goal.MonthlyContribution))
+		row["months_to_go"] = months
+		row["eta"] = now.AddDate(0, months, 0).Format("2006-01-02")
 	}
 
-	// Generate human-readable insights
-	insights := []string{
-		fmt.Sprintf("You made %d spending transactions over %d days", spendCount, days),
-		fmt.Sprintf("Average daily spend: $%.2f", avgDailySpend),
+	row["status"] = "in progress"
+	if goal.TargetDate == "" {
+		return row
 	}
-
-	if netCashFlow > 0 {
-		insights = append(insights, fmt.Sprintf("Great! You're cash flow positive with $%.2f net income", netCashFlow))
-	} else if netCashFlow < 0 {
-		insights = append(insights, fmt.Sprintf("You spent $%.2f more than you received this period", math.Abs(netCashFlow)))
+	deadline, err := time.Parse("2006-01-02", goal.TargetDate)
+	if err != nil {
+		return row
 	}
-
-	if len(topCategories) > 0 {
-		topCat := categories[0]
-		insights = append(insights, fmt.Sprintf("Your biggest spending category is %s (%.0f%% of spending)", topCat.name, topCat.percentage))
+	row["target_date"] = goal.TargetDate
+	// Whole months left, counting a partial month as one; a past deadline needs everything now
+	monthsLeft := math.Ceil(deadline.Sub(now).Hours() / 24 / daysPerMonth)
+	required := remaining
+	if monthsLeft > 1 {
+		required = remaining / monthsLeft
 	}
-
-	return map[string]interface{}{
-		"total_spent":      fmt.Sprintf("%.2f", totalSpent),
-		"total_received":   fmt.Sprintf("%.2f", totalReceived),
-		"net_cash_flow":    fmt.Sprintf("%.2f", netCashFlow),
-		"spend_count":      spendCount,
-		"receive_count":    receiveCount,
-		"avg_daily_spend":  fmt.Sprintf("%.2f", avgDailySpend),
-		"velocity":         calculateVelocity(spendCount, days),
-		"top_categories":   topCategories,
-		"insights":         insights,
+	row["required_monthly"] = fmt.Sprintf("%.2f", required)
+	row["on_track"] = goal.MonthlyContribution >= required-0.005
+	if !row["on_track"].(bool) {
+		row["status"] = "behind"
 	}
+	return row
 }
 
-// categorizeTransaction maps merchant descriptions to spending categories
-// Uses keyword matching to classify transactions
-func categorizeTransaction(description string) string {
-	text := strings.ToLower(description)
+// ============================================================================
+// CUSTOM TOOL: SUBSCRIPTION INFLATION
+// ============================================================================
 
-	// Food & Dining
-	if strings.Contains(text, "starbucks") || strings.Contains(text, "coffee") ||
-		strings.Contains(text, "chipotle") || strings.Contains(text, "pizza") ||
-		strings.Contains(text, "food") || strings.Contains(text, "doordash") ||
-		strings.Contains(text, "restaurant") || strings.Contains(text, "cafe") {
-		return "Food & Dining"
-	}
+// subscriptionInflationHorizons are the years out the projection reports
+var subscriptionInflationHorizons = []int{1, 3, 5}
 
-	// Transportation
-	if strings.Contains(text, "uber") || strings.Contains(text, "lyft") ||
-		strings.Contains(text, "gas") || strings.Contains(text, "metro") ||
-		strings.Contains(text, "parking") {
-		return "Transportation"
-	}
+// createSubscriptionInflationTool builds a tool that projects where subscription costs are heading
+// Rolls every detected price increase into one annual rate, then compounds it forward
+func createSubscriptionInflationTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("project_subscription_inflation").
+		Description("Look for price increases across all detected subscriptions over the last year, combine them into an average annual increase rate (weighted by what each subscription costs), and project the total annual subscription cost 1, 3 and 5 years out if the trend continues, with the cumulative extra spend. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"months":   tools.IntegerProperty(fmt.Sprintf("Months of payment history to scan for increases (default: 12, minimum: 2, max: %d)", maxTimeframeMonths)),
+			"use_mock": tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Months  int  `json:"months"`
+				UseMock bool `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Months <= 0 {
+				params.Months = 12
+			}
+			// An increase needs at least two months of payments to show up
+			params.Months = min(max(params.Months, 2), maxTimeframeMonths)
 
-	// Shopping
-	if strings.Contains(text, "amazon") || strings.Contains(text, "target") ||
-		strings.Contains(text, "nike") || strings.Contains(text, "store") {
-		return "Shopping"
-	}
+			now := time.Now()
+			cutoffDate := now.AddDate(0, -params.Months, 0)
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockSubscriptionPriceHistory(params.Months, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for subscription inflation", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": cutoffDate.Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
 
-	// Entertainment
-	if strings.Contains(text, "netflix") || strings.Contains(text, "spotify") ||
-		strings.Contains(text, "movie") || strings.Contains(text, "steam") ||
-		strings.Contains(text, "hulu") || strings.Contains(text, "disney") {
-		return "Entertainment"
-	}
+			// A price increase can split one subscription into two patterns; keep the latest per merchant
+			latest := make(map[string]map[string]interface{})
+			merchants := []string{}
+			for _, sub := range analyzeForSubscriptions(transactions, cutoffDate, 0, 0) {
+				merchant, _ := sub["merchant"].(string)
+				previous, ok := latest[merchant]
+				if !ok {
+					merchants = append(merchants, merchant)
+				} else if fmt.Sprint(previous["last_occurrence"]) >= fmt.Sprint(sub["last_occurrence"]) {
+					continue
+				}
+				latest[merchant] = sub
+			}
+			sort.Strings(merchants)
 
-	// Bills & Utilities
-	if strings.Contains(text, "bill") || strings.Contains(text, "electric") ||
-		strings.Contains(text, "internet") || strings.Contains(text, "phone") {
-		return "Bills & Utilities"
-	}
+			increases := []map[string]interface{}{}
+			currentAnnual, weightedRate := 0.0, 0.0
+			for _, merchant := range merchants {
+				sub := latest[merchant]
+				frequency, _ := sub["frequency"].(string)
+				price, _ := sub["amount"].(float64)
+				oldPrice, newPrice, span, found := detectPriceChangeSpan(transactions, merchant, cutoffDate)
+				if found {
+					price = newPrice
+				}
+				annual := monthlyEquivalent(price, frequency) * 12
+				if annual <= 0 {
+					continue
+				}
+				currentAnnual += annual
+				if !found {
+					continue
+				}
+				// Treat an increase as at most one a year, so a recent bump isn't annualized into a huge rate
+				years := math.Max(span.Hours()/24/365, 1)
+				rate := math.Pow(newPrice/oldPrice, 1/years) - 1
+				weightedRate += rate * annual
+				increases = append(increases, map[string]interface{}{
+					"merchant":            merchant,
+					"old_price":           fmt.Sprintf("%.2f", oldPrice),
+					"new_price":           fmt.Sprintf("%.2f", newPrice),
+					"frequency":           frequency,
+					"annual_rate_percent": math.Round(rate*1000) / 10,
+					"annual_cost":         fmt.Sprintf("%.2f", annual),
+				})
+			}
 
-	return "Other"
-}
+			result := map[string]interface{}{
+				"subscription_count":  len(merchants),
+				"current_annual_cost": fmt.Sprintf("%.2f", currentAnnual),
+				"price_increases":     increases,
+				"data_source":         map[string]bool{"is_mock": params.UseMock},
+				"generated_at":        now.Format(time.RFC3339),
+			}
+			if len(increases) == 0 || currentAnnual == 0 {
+				result["average_annual_increase_percent"] = 0.0
+				result["projections"] = []map[string]interface{}{}
+				result["summary"] = fmt.Sprintf("No subscription price increases in the last %d months, so costs should hold at about $%.2f a year.", params.Months, currentAnnual)
+				return &core.ToolResult{Success: true, Data: result}, nil
+			}
 
-// calculateVelocity determines spending frequency (low/moderate/high)
-// Based on average transactions per week
-func calculateVelocity(transactionCount, days int) string {
-	txPerWeek := float64(transactionCount) / float64(days) * 7
+			// Subscriptions that didn't go up count as 0%, so the rate is for the whole bundle
+			rate := weightedRate / currentAnnual
+			projections := []map[string]interface{}{}
+			cumulativeExtra, lastHorizon := 0.0, 0
+			for _, years := range subscriptionInflationHorizons {
+				for year := lastHorizon + 1; year <= years; year++ {
+					cumulativeExtra += currentAnnual*math.Pow(1+rate, float64(year)) - currentAnnual
+				}
+				lastHorizon = years
+				annual := currentAnnual * math.Pow(1+rate, float64(years))
+				projections = append(projections, map[string]interface{}{
+					"years_out":        years,
+					"year":             now.Year() + years,
+					"annual_cost":      fmt.Sprintf("%.2f", annual),
+					"annual_increase":  fmt.Sprintf("%.2f", annual-currentAnnual),
+					"cumulative_extra": fmt.Sprintf("%.2f", cumulativeExtra),
+				})
+			}
 
-	switch {
-	case txPerWeek < 2:
-		return "low"
-	case txPerWeek < 7:
-		return "moderate"
-	default:
-		return "high"
+			final := projections[len(projections)-1]
+			result["average_annual_increase_percent"] = math.Round(rate*1000) / 10
+			result["projections"] = projections
+			result["summary"] = fmt.Sprintf("%d of your %d subscriptions went up, an average of %.1f%% a year across the bundle. If that continues, your subscriptions will cost $%s more a year by %d, and $%s extra in total along the way.",
+				len(increases), len(merchants), rate*100, final["annual_increase"], final["year"], final["cumulative_extra"])
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
+}
+
+// generateMockSubscriptionPriceHistory creates subscription payments where some prices went up
+// Each raised subscription charged 8-20% less before a random month in the window
+func generateMockSubscriptionPriceHistory(months int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	transactions := generateMockSubscriptionTransactions(months, opts)
+	now := time.Now()
+	raisedAt := make(map[string]time.Time)
+	increase := make(map[string]float64)
+	for _, tx := range transactions {
+		merchant := merchantName(tx)
+		if _, seen := increase[merchant]; seen {
+			continue
+		}
+		increase[merchant] = 0
+		if rng.Intn(2) == 0 {
+			increase[merchant] = 0.08 + rng.Float64()*0.12
+			raisedAt[merchant] = now.AddDate(0, -1-rng.Intn(months-1), 0)
+		}
 	}
+	for _, tx := range transactions {
+		merchant := merchantName(tx)
+		dateStr, _ := tx["date"].(string)
+		date, err := time.Parse(time.RFC3339, dateStr)
+		amount, _ := tx["amount"].(float64)
+		if err != nil || increase[merchant] == 0 || !date.Before(raisedAt[merchant]) {
+			continue
+		}
+		tx["amount"] = math.Round(amount/(1+increase[merchant])*100) / 100
+	}
+	return transactions
 }
 
 // ============================================================================
-// CUSTOM TOOL: SUBSCRIPTION ANALYZER
+// CUSTOM TOOL: SUSPICIOUS ACTIVITY
 // ============================================================================
 
-// createSubscriptionAnalyzerTool builds a tool that detects recurring payments
-// Identifies subscriptions by finding payment patterns with regular intervals
-func createSubscriptionAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool {
-	return tools.New("analyze_subscriptions").
-		Description("Scan transaction history to identify recurring subscriptions and recurring payments. Returns subscription patterns, total monthly costs, and cancellation insights. Uses mock data by default for demo purposes.").
+// createCardTestingDetectorTool builds a tool that flags card-testing bursts
+// Fraudsters check a stolen card with a run of tiny charges at throwaway merchants before a big one
+func createCardTestingDetectorTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("detect_suspicious_activity").
+		Description("Flag bursts of many small sends to new or unknown merchants in a short window (by default 5+ sends under $5 within 10 minutes), the classic card-testing pattern. Returns each suspicious cluster with its timestamps and a recommendation to review. Does not block or reverse anything. Uses mock data by default for demo purposes.").
 		Schema(tools.ObjectSchema(map[string]interface{}{
-			"timeframe_months": tools.IntegerProperty("Number of months to analyze for recurring patterns (default: 6)"),
-			"min_amount":       tools.NumberProperty("Minimum amount to be considered as subscription (default: 1.00)"),
-			"max_amount":       tools.NumberProperty("Maximum amount to be considered as a subscription (default: 999.99)"),
-			"use_mock":         tools.BoolProperty("Use mock data for testing (default: true)"),
+			"days":           tools.IntegerProperty("Number of days to scan (default: 30)"),
+			"max_amount":     tools.NumberProperty("Sends below this amount count as small (default: 5)"),
+			"min_count":      tools.IntegerProperty("Small sends needed in one window to flag it (default: 5)"),
+			"window_minutes": tools.IntegerProperty("Length of the burst window in minutes (default: 10)"),
+			"use_mock":       tools.BoolProperty("Use mock data for testing (default: true)"),
 		})).
 		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
 			var params struct {
-				TimeframeMonths int     `json:"timeframe_months"`
-				MinAmount       float64 `json:"min_amount"`
-				MaxAmount       float64 `json:"max_amount"`
-				UseMock         bool    `json:"use_mock"`
+				Days          int     `json:"days"`
+				MaxAmount     float64 `json:"max_amount"`
+				MinCount      int     `json:"min_count"`
+				WindowMinutes int     `json:"window_minutes"`
+				UseMock       bool    `json:"use_mock"`
 			}
 			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
-				// Default to mock mode
 				params.UseMock = true
-				params.TimeframeMonths = 6
-				params.MinAmount = 1.00
-				params.MaxAmount = 999.99
 			}
-
-			// Set defaults
-			if params.TimeframeMonths == 0 {
-				params.TimeframeMonths = 6
+			if params.Days <= 0 {
+				params.Days = 30
 			}
-			if params.MinAmount == 0 {
-				params.MinAmount = 1.00
+			if params.MaxAmount <= 0 {
+				params.MaxAmount = 5
 			}
-			if params.MaxAmount == 0 {
-				params.MaxAmount = 999.99
+			if params.MinCount < 2 {
+				params.MinCount = 5
+			}
+			if params.WindowMinutes <= 0 {
+				params.WindowMinutes = 10
 			}
 
-			var transactions []map[string]interface{}
+			// Extra history so a merchant the user has paid before isn't mistaken for a new one
+			const baselineDays = 90
 			now := time.Now()
-			cutoffDate := now.AddDate(0, -params.TimeframeMonths, 0)
-
-			// Get transaction data (mock or real)
+			var transactions []map[string]interface{}
 			if params.UseMock {
-				// Generate mock subscription transactions
-				transactions = generateMockSubscriptionTransactions(params.TimeframeMonths)
-				log.Printf("📊 Generated %d mock subscription transactions", len(transactions))
+				transactions = append(generateMockTransactionsForAnalysis(params.Days+baselineDays, mockOptions{}),
+					generateMockCardTestingBurst(params.Days, mockOptions{})...)
+				log.Printf("📊 Generated %d mock transactions for suspicious activity", len(transactions))
 			} else {
-				// Fetch real transactions
-				txRequest := map[string]interface{}{
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
 					"limit":      500,
-					"start_date": cutoffDate.Format("2006-01-02"),
-				}
-				txRequestJSON, _ := json.Marshal(txRequest)
-				txResponse, err := liminalExecutor.Execute(ctx, &core.ExecuteRequest{
-					UserID:    toolParams.UserID,
-					Tool:      "get_transactions",
-					Input:     txRequestJSON,
-					RequestID: toolParams.RequestID,
+					"start_date": now.AddDate(0, 0, -(params.Days + baselineDays)).Format("2006-01-02"),
 				})
 				if err != nil {
-					return &core.ToolResult{
-						Success: false,
-						Error:   fmt.Sprintf("failed to fetch transactions: %v", err),
-					}, nil
-				}
-				if !txResponse.Success {
-					return &core.ToolResult{
-						Success: false,
-						Error:   fmt.Sprintf("transaction fetch failed: %s", txResponse.Error),
-					}, nil
+					return toolErrorResult(err), nil
 				}
+			}
 
-				var txData map[string]interface{}
-				if err := json.Unmarshal(txResponse.Data, &txData); err == nil {
-					if txArray, ok := txData["transactions"].([]interface{}); ok {
-						for _, tx := range txArray {
-							if txMap, ok := tx.(map[string]interface{}); ok {
-								transactions = append(transactions, txMap)
-							}
-						}
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in suspicious activity scan: %v", err)
+			}
+
+			window := time.Duration(params.WindowMinutes) * time.Minute
+			bursts := findCardTestingBursts(parsed, now.AddDate(0, 0, -params.Days), params.MaxAmount, params.MinCount, window)
+			clusters := []map[string]interface{}{}
+			for _, burst := range bursts {
+				total := 0.0
+				merchants := []string{}
+				charges := []map[string]interface{}{}
+				for _, tx := range burst {
+					total += tx.Amount
+					merchant := burstMerchant(tx)
+					if !containsString(merchants, merchant) {
+						merchants = append(merchants, merchant)
 					}
+					charges = append(charges, map[string]interface{}{
+						"id":       tx.ID,
+						"date":     tx.Date.Format(time.RFC3339),
+						"amount":   fmt.Sprintf("%.2f", tx.Amount),
+						"merchant": merchant,
+					})
 				}
+				first, last := burst[0].Date, burst[len(burst)-1].Date
+				clusters = append(clusters, map[string]interface{}{
+					"start":        first.Format(time.RFC3339),
+					"end":          last.Format(time.RFC3339),
+					"span_minutes": math.Round(last.Sub(first).Minutes()*10) / 10,
+					"count":        len(burst),
+					"total":        fmt.Sprintf("%.2f", total),
+					"merchants":    merchants,
+					"transactions": charges,
+				})
 			}
 
-			subscriptions := analyzeForSubscriptions(transactions, cutoffDate, params.MinAmount, params.MaxAmount)
 			result := map[string]interface{}{
-				"analysis_period":            fmt.Sprintf("%d months", params.TimeframeMonths),
-				"total_transactions_scanned": len(transactions),
-				"subscriptions_found":        len(subscriptions),
-				"subscriptions":              subscriptions,
-				"total_monthly_cost":         calculateTotalMonthlyCost(subscriptions),
-				"warnings":                   generateWarnings(subscriptions),
-				"data_source":                map[string]bool{"is_mock": params.UseMock},
-				"generated_at":               now.Format(time.RFC3339),
+				"suspicious":   len(clusters) > 0,
+				"clusters":     clusters,
+				"criteria":     fmt.Sprintf("%d+ sends under $%.2f to new or unknown merchants within %d minutes", params.MinCount, params.MaxAmount, params.WindowMinutes),
+				"data_source":  map[string]bool{"is_mock": params.UseMock},
+				"generated_at": now.Format(time.RFC3339),
+			}
+			if len(clusters) == 0 {
+				result["summary"] = fmt.Sprintf("No card-testing patterns in the last %d days.", params.Days)
+				return &core.ToolResult{Success: true, Data: result}, nil
 			}
+			result["summary"] = fmt.Sprintf("Found %d burst(s) of tiny charges to unfamiliar merchants, a common sign someone is testing a stolen card.", len(clusters))
+			result["recommendation"] = "Review these charges now. If you don't recognize them, contact support to freeze your card and dispute them, and watch for a larger charge that often follows."
 			return &core.ToolResult{
 				Success: true,
 				Data:    result,
@@ -736,261 +11840,343 @@ func createSubscriptionAnalyzerTool(liminalExecutor core.ToolExecutor) core.Tool
 		Build()
 }
 
-// analyzeForSubscriptions detects recurring payment patterns
-// Groups transactions by merchant+amount, checks for regular intervals
-func analyzeForSubscriptions(transactions []map[string]interface{}, cutoffDate time.Time, minAmount, maxAmount float64) []map[string]interface{} {
-	if len(transactions) == 0 {
-		return []map[string]interface{}{}
-	}
-
-	// Group transactions by merchant and amount
-	type paymentKey struct {
-		merchant string
-		amount   string
+// burstMerchant names a transaction's merchant for the suspicious activity scan
+func burstMerchant(tx Transaction) string {
+	if name := strings.TrimSpace(tx.Description); name != "" {
+		return name
 	}
-	paymentGroups := make(map[paymentKey][]time.Time)
+	return unknownMerchant
+}
 
-	for _, tx := range transactions {
-		txType, _ := tx["type"].(string)
-		if txType != "send" { // Only look at outgoing payments
-			continue
-		}
+// findCardTestingBursts returns runs of at least minCount small sends since cutoff that
+// fit within window, oldest first. Only sends to merchants with no earlier payment
+// before the run starts (or no name at all) count, so a daily coffee can't trigger it.
+// Runs don't overlap: once one is reported, the scan resumes after it.
+func findCardTestingBursts(transactions []Transaction, cutoff time.Time, maxAmount float64, minCount int, window time.Duration) [][]Transaction {
+	sorted := make([]Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
 
-		amount, _ := tx["amount"].(float64)
-		if amount < minAmount || amount > maxAmount {
-			continue
+	firstSeen := make(map[string]time.Time)
+	for _, tx := range sorted {
+		key := strings.ToLower(burstMerchant(tx))
+		if _, ok := firstSeen[key]; !ok {
+			firstSeen[key] = tx.Date
 		}
+	}
 
-		merchant := "Unknown"
-		if desc, ok := tx["description"].(string); ok && desc != "" {
-			merchant = desc
-		} else if recipient, ok := tx["recipient"].(string); ok && recipient != "" {
-			merchant = recipient
+	candidates := []Transaction{}
+	for _, tx := range sorted {
+		if tx.Type == "send" && tx.Amount < maxAmount && !tx.Date.Before(cutoff) {
+			candidates = append(candidates, tx)
 		}
+	}
 
-		txDateStr, ok := tx["date"].(string)
-		if !ok {
-			continue
-		}
-		txDate, err := time.Parse(time.RFC3339, txDateStr)
-		if err != nil {
-			continue
+	bursts := [][]Transaction{}
+	for i := 0; i < len(candidates); {
+		start := candidates[i].Date
+		run := []Transaction{}
+		j := i
+		for ; j < len(candidates) && candidates[j].Date.Sub(start) <= window; j++ {
+			merchant := burstMerchant(candidates[j])
+			if merchant == unknownMerchant || !firstSeen[strings.ToLower(merchant)].Before(start) {
+				run = append(run, candidates[j])
+			}
 		}
-		if txDate.Before(cutoffDate) {
+		if len(run) >= minCount {
+			bursts = append(bursts, run)
+			i = j
 			continue
 		}
-
-		// Round amount to avoid floating point issues
-		roundedAmount := fmt.Sprintf("%.2f", amount)
-		key := paymentKey{merchant: merchant, amount: roundedAmount}
-		paymentGroups[key] = append(paymentGroups[key], txDate)
+		i++
 	}
+	return bursts
+}
 
-	var subscriptions []map[string]interface{}
-	for key, dates := range paymentGroups {
-		if len(dates) < 2 { // Need at least 2 occurrences to detect pattern
-			continue
-		}
-
-		// Sort dates chronologically
-		sort.Slice(dates, func(i, j int) bool {
-			return dates[i].Before(dates[j])
+// generateMockCardTestingBurst creates one card-testing run: tiny charges to
+// one-off online merchants a few minutes apart, sometime in the last days
+func generateMockCardTestingBurst(days int, opts mockOptions) []map[string]interface{} {
+	rng := opts.newRand()
+	currency := pickMockCurrency(opts.Currency, rng)
+	start := time.Now().AddDate(0, 0, -rng.Intn(days)).Add(-time.Duration(rng.Intn(12)) * time.Hour)
+	transactions := []map[string]interface{}{}
+	for i, at := 0, start; i < 6+rng.Intn(3); i++ {
+		transactions = append(transactions, map[string]interface{}{
+			"id":          fmt.Sprintf("tx_mock_burst_%d", i),
+			"type":        "send",
+			"amount":      convertMockAmount(0.5+rng.Float64()*2.5, currency),
+			"description": fmt.Sprintf("WEBSHOP*%04d ONLINE", rng.Intn(10000)),
+			"date":        at.Format(time.RFC3339),
+			"status":      "completed",
+			"currency":    currency,
 		})
-
-		// Calculate intervals between payments
-		intervals := make([]int, 0)
-		for i := 1; i < len(dates); i++ {
-			daysBetween := int(dates[i].Sub(dates[i-1]).Hours() / 24)
-			intervals = append(intervals, daysBetween)
-		}
-
-		// Check if intervals form a regular pattern
-		if isRegularPattern(intervals) {
-			amount, _ := strconv.ParseFloat(key.amount, 64)
-			frequency := detectFrequency(intervals)
-			subscription := map[string]interface{}{
-				"merchant":        key.merchant,
-				"amount":          amount,
-				"frequency":       frequency,
-				"occurrences":     len(dates),
-				"last_occurrence": dates[len(dates)-1].Format("2006-01-02"),
-				"estimated_next":  estimateNextPayment(dates[len(dates)-1], frequency),
-				"total_paid":      amount * float64(len(dates)),
-				"confidence":      calculateConfidence(len(dates), intervals),
-			}
-			subscriptions = append(subscriptions, subscription)
-		}
+		at = at.Add(time.Duration(30+rng.Intn(60)) * time.Second)
 	}
-
-	return subscriptions
+	return transactions
 }
 
-// isRegularPattern checks if payment intervals are consistent (within 20% tolerance)
-// Returns true if 70% or more intervals fall within tolerance
-func isRegularPattern(intervals []int) bool {
-	if len(intervals) == 0 {
-		return false
-	}
-	sum := 0
-	for _, interval := range intervals {
-		sum += interval
-	}
-	avg := float64(sum) / float64(len(intervals))
+// ============================================================================
+// CUSTOM TOOL: CANCELLATION COST
+// ============================================================================
 
-	withinTolerance := 0
-	tolerance := avg * 0.2 // 20% tolerance
-	for _, interval := range intervals {
-		if math.Abs(float64(interval)-avg) <= tolerance {
-			withinTolerance++
-		}
-	}
-	return float64(withinTolerance)/float64(len(intervals)) >= 0.7
-}
+// createCancellationCostTool builds a tool that decides whether breaking a contract is worth the fee
+// Canceling costs the early termination fee; riding it out costs the remaining payments
+func createCancellationCostTool() core.Tool {
+	return tools.New("cancellation_cost").
+		Description("For a contract subscription (phone plan, gym, software with a term), compare canceling now and paying the early termination fee with paying out the remaining months. Returns both costs, the savings, the break-even point (how many remaining months make the fee worth it) and the last month canceling still saves money, with a recommendation. Pure math: does not cancel anything.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"monthly_cost":          tools.NumberProperty("What the subscription costs per month"),
+			"months_remaining":      tools.IntegerProperty("Months left on the commitment"),
+			"early_termination_fee": tools.NumberProperty("Fee charged for canceling before the term ends (default: 0)"),
+			"name":                  tools.StringProperty("Subscription name for the message, e.g. 'Gym membership'"),
+		}, "monthly_cost", "months_remaining")).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				MonthlyCost         float64 `json:"monthly_cost"`
+				MonthsRemaining     int     `json:"months_remaining"`
+				EarlyTerminationFee float64 `json:"early_termination_fee"`
+				Name                string  `json:"name"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				return &core.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid input: %v", err),
+				}, nil
+			}
+			if params.MonthlyCost <= 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "monthly_cost must be greater than 0",
+				}, nil
+			}
+			if params.MonthsRemaining < 0 || params.EarlyTerminationFee < 0 {
+				return &core.ToolResult{
+					Success: false,
+					Error:   "months_remaining and early_termination_fee can't be negative",
+				}, nil
+			}
+			name := params.Name
+			if name == "" {
+				name = "this subscription"
+			}
 
-// detectFrequency classifies payment frequency based on average interval
-func detectFrequency(intervals []int) string {
-	if len(intervals) == 0 {
-		return "unknown"
-	}
-	sum := 0
-	for _, interval := range intervals {
-		sum += interval
-	}
-	avgDays := float64(sum) / float64(len(intervals))
+			rideOut := params.MonthlyCost * float64(params.MonthsRemaining)
+			fee := params.EarlyTerminationFee
+			savings := rideOut - fee
+			// Canceling pays off once more months remain than the fee buys
+			breakEven := fee / params.MonthlyCost
+			// Canceling after k more months costs k payments plus the fee, so it saves while k < remaining - breakEven
+			lastMonthToSave := int(math.Ceil(float64(params.MonthsRemaining)-breakEven)) - 1
 
-	switch {
-	case avgDays >= 25 && avgDays <= 35:
-		return "monthly"
-	case avgDays >= 80 && avgDays <= 100:
-		return "quarterly"
-	case avgDays >= 170 && avgDays <= 190:
-		return "semi-annual"
-	case avgDays >= 350 && avgDays <= 380:
-		return "annual"
-	case avgDays >= 7 && avgDays <= 14:
-		return "biweekly"
-	case avgDays >= 1 && avgDays <= 7:
-		return "weekly"
-	default:
-		return "irregular"
-	}
-}
+			var recommendation, message string
+			switch {
+			case savings > 0.005:
+				recommendation = "cancel_now"
+				message = fmt.Sprintf("Cancel %s now: the $%.2f fee is less than the $%.2f left to pay over %d months, saving $%.2f. Each month you wait costs another $%.2f.",
+					name, fee, rideOut, params.MonthsRemaining, savings, params.MonthlyCost)
+			case savings < -0.005:
+				recommendation = "ride_out"
+				message = fmt.Sprintf("Keep %s until the term ends: the remaining %d months cost $%.2f, which is $%.2f less than the $%.2f fee. Set a reminder to cancel when it's up.",
+					name, params.MonthsRemaining, rideOut, -savings, fee)
+			default:
+				recommendation = "either"
+				message = fmt.Sprintf("It's a wash for %s: the $%.2f fee matches the remaining payments, so keep using it until the term ends unless you want it gone now.", name, fee)
+			}
 
-// estimateNextPayment predicts the next payment date based on frequency
-func estimateNextPayment(lastPayment time.Time, frequency string) string {
-	switch frequency {
-	case "monthly":
-		return lastPayment.AddDate(0, 1, 0).Format("2006-01-02")
-	case "quarterly":
-		return lastPayment.AddDate(0, 3, 0).Format("2006-01-02")
-	case "semi-annual":
-		return lastPayment.AddDate(0, 6, 0).Format("2006-01-02")
-	case "annual":
-		return lastPayment.AddDate(1, 0, 0).Format("2006-01-02")
-	case "biweekly":
-		return lastPayment.AddDate(0, 0, 14).Format("2006-01-02")
-	case "weekly":
-		return lastPayment.AddDate(0, 0, 7).Format("2006-01-02")
-	default:
-		return "unknown"
-	}
+			result := map[string]interface{}{
+				"monthly_cost":          fmt.Sprintf("%.2f", params.MonthlyCost),
+				"months_remaining":      params.MonthsRemaining,
+				"early_termination_fee": fmt.Sprintf("%.2f", fee),
+				"cost_if_cancel_now":    fmt.Sprintf("%.2f", fee),
+				"cost_if_ride_out":      fmt.Sprintf("%.2f", rideOut),
+				"savings_if_cancel_now": fmt.Sprintf("%.2f", savings),
+				"break_even_months":     math.Round(breakEven*10) / 10,
+				"recommendation":        recommendation,
+				"message":               message,
+				"generated_at":          time.Now().Format(time.RFC3339),
+			}
+			if recommendation == "cancel_now" {
+				result["cancel_within_months"] = lastMonthToSave
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data:    result,
+			}, nil
+		}).
+		Build()
 }
 
-// calculateConfidence determines detection confidence based on occurrences and regularity
-func calculateConfidence(occurrences int, intervals []int) string {
-	if occurrences >= 4 && isRegularPattern(intervals) {
-		return "high"
-	} else if occurrences >= 3 {
-		return "medium"
-	} else {
-		return "low"
-	}
-}
+// ============================================================================
+// CUSTOM TOOL: SUGGEST BUDGET
+// ============================================================================
 
-// calculateTotalMonthlyCost normalizes all subscriptions to monthly cost
-// Converts quarterly, annual, etc. to equivalent monthly amount
-func calculateTotalMonthlyCost(subscriptions []map[string]interface{}) float64 {
-	var totalMonthly float64
-	for _, sub := range subscriptions {
-		amount, _ := sub["amount"].(float64)
-		frequency, _ := sub["frequency"].(string)
-		switch frequency {
-		case "monthly":
-			totalMonthly += amount
-		case "quarterly":
-			totalMonthly += amount / 3
-		case "semi-annual":
-			totalMonthly += amount / 6
-		case "annual":
-			totalMonthly += amount / 12
-		case "biweekly":
-			totalMonthly += amount * 2.167 // ~26 payments/year ÷ 12 months
-		case "weekly":
-			totalMonthly += amount * 4.333 // ~52 payments/year ÷ 12 months
-		}
-	}
-	return math.Round(totalMonthly*100) / 100
-}
+// createSuggestBudgetTool builds a tool that proposes per-category budget limits from past spending
+// The result's budget map can be passed straight to budget_variance
+func createSuggestBudgetTool(liminalExecutor core.ToolExecutor) core.Tool {
+	return tools.New("suggest_budget").
+		Description("Suggest a realistic monthly budget from history: for each spending category, the average month and a limit at a percentile of past months (default 75th, a little below the peak to encourage discipline), rounded, plus the total. Optionally trims discretionary categories so the total fits within a percentage of income. Returns a budget map ready for budget_variance. Uses mock data by default for demo purposes.").
+		Schema(tools.ObjectSchema(map[string]interface{}{
+			"months":               tools.IntegerProperty("Number of 30-day months of history to use, at least 2 (default: 6)"),
+			"percentile":           tools.NumberProperty("Percentile of past months to set each limit at, 1-100 (default: 75)"),
+			"income_percent":       tools.NumberProperty("Keep the total within this percent of average monthly income by trimming non-essential categories (optional)"),
+			"round_to":             tools.IntegerProperty("Round each limit to a multiple of this amount (default: 5)"),
+			"category_weights":     categoryWeightsProperty(),
+			"essential_categories": essentialCategoriesProperty(),
+			"use_mock":             tools.BoolProperty("Use mock data for testing (default: true)"),
+		})).
+		Handler(func(ctx context.Context, toolParams *core.ToolParams) (*core.ToolResult, error) {
+			var params struct {
+				Months              int              `json:"months"`
+				Percentile          float64          `json:"percentile"`
+				IncomePercent       float64          `json:"income_percent"`
+				RoundTo             int              `json:"round_to"`
+				CategoryWeights     []categoryWeight `json:"category_weights"`
+				EssentialCategories []string         `json:"essential_categories"`
+				UseMock             bool             `json:"use_mock"`
+			}
+			if err := json.Unmarshal(toolParams.Input, &params); err != nil {
+				params.UseMock = true
+			}
+			if params.Months < 2 {
+				params.Months = 6
+			}
+			if params.Percentile <= 0 || params.Percentile > 100 {
+				params.Percentile = 75
+			}
+			if params.RoundTo <= 0 {
+				params.RoundTo = 5
+			}
 
-// generateWarnings creates actionable insights about subscriptions
-// Identifies duplicate categories, inactive subscriptions, and savings opportunities
-func generateWarnings(subscriptions []map[string]interface{}) []string {
-	warnings := make([]string, 0)
-	if len(subscriptions) == 0 {
-		warnings = append(warnings, "No subscriptions were detected in your transaction history.")
-		return warnings
-	}
+			now := time.Now()
+			days := params.Months * 30
+			var transactions []map[string]interface{}
+			if params.UseMock {
+				transactions = generateMockTransactionsForAnalysis(days, mockOptions{})
+				log.Printf("📊 Generated %d mock transactions for budget suggestion", len(transactions))
+			} else {
+				var err error
+				transactions, err = fetchTransactions(ctx, liminalExecutor, toolParams, map[string]interface{}{
+					"limit":      500,
+					"start_date": now.AddDate(0, 0, -days).Format("2006-01-02"),
+				})
+				if err != nil {
+					return toolErrorResult(err), nil
+				}
+			}
 
-	totalMonthly := calculateTotalMonthlyCost(subscriptions)
-	warnings = append(warnings, fmt.Sprintf("You are spending approximately $%.2f per month on subscriptions.", totalMonthly))
+			parsed, parseErrs := parseTransactions(transactions)
+			for _, err := range parseErrs {
+				log.Printf("⚠️  Skipping transaction in budget suggestion: %v", err)
+			}
 
-	// Check for duplicate categories (e.g., multiple streaming services)
-	merchantCategories := make(map[string][]string)
-	knownPatterns := map[string][]string{
-		"streaming": {"netflix", "hulu", "disney", "prime", "spotify", "hbo", "apple tv", "youtube premium"},
-		"music":     {"spotify", "apple music", "youtube music", "tidal", "pandora"},
-		"cloud":     {"dropbox", "google one", "icloud", "onedrive"},
-		"fitness":   {"peloton", "classpass", "apple fitness", "strava", "planet fitness"},
-		"software":  {"adobe", "github", "office"},
-	}
+			step := float64(params.RoundTo)
+			monthly := monthlyCategorySpend(parsed, params.CategoryWeights, params.Months, now)
+			type suggestion struct {
+				category  string
+				average   float64
+				peak      float64
+				limit     float64
+				essential bool
+			}
+			essentials := resolveEssentials(params.EssentialCategories)
+			suggestions := []suggestion{}
+			for category, amounts := range monthly {
+				total, peak := 0.0, 0.0
+				for _, amount := range amounts {
+					total += amount
+					peak = math.Max(peak, amount)
+				}
+				limit := math.Round(percentile(amounts, params.Percentile)/step) * step
+				suggestions = append(suggestions, suggestion{category, total / float64(len(amounts)), peak, limit, essentials.has(category)})
+			}
 
-	for _, sub := range subscriptions {
-		merchant, _ := sub["merchant"].(string)
-		merchantLower := strings.ToLower(merchant)
-		for category, keywords := range knownPatterns {
-			for _, keyword := range keywords {
-				if strings.Contains(merchantLower, keyword) {
-					merchantCategories[category] = append(merchantCategories[category], merchant)
-					break
+			total := 0.0
+			for _, s := range suggestions {
+				total += s.limit
+			}
+			monthlyIncome := summarizeCashFlow(transactions, days).MonthlyIncome
+			warnings := []string{}
+			if params.IncomePercent > 0 && monthlyIncome > 0 {
+				target := monthlyIncome * params.IncomePercent / 100
+				if total > target {
+					// Essentials stay put; discretionary limits shrink proportionally to make room
+					essential, discretionary := 0.0, 0.0
+					for _, s := range suggestions {
+						if s.essential {
+							essential += s.limit
+						} else {
+							discretionary += s.limit
+						}
+					}
+					if essential > target || discretionary == 0 {
+						warnings = append(warnings, fmt.Sprintf("Essentials alone come to $%.0f, so the budget can't fit within %.0f%% of income ($%.0f) without cutting them. Limits are left at your usual spending.", essential, params.IncomePercent, target))
+					} else {
+						scale := (target - essential) / discretionary
+						total = 0
+						for i := range suggestions {
+							if !suggestions[i].essential {
+								suggestions[i].limit = math.Floor(suggestions[i].limit*scale/step) * step
+							}
+							total += suggestions[i].limit
+						}
+						warnings = append(warnings, fmt.Sprintf("Trimmed discretionary limits by %.0f%% to keep the total within %.0f%% of income.", (1-scale)*100, params.IncomePercent))
+					}
 				}
 			}
-		}
-	}
 
-	// Warn about duplicate categories
-	for category, merchants := range merchantCategories {
-		if len(merchants) > 1 {
-			warnings = append(warnings, fmt.Sprintf("You have multiple %s subscriptions: %s. Consider consolidating.", category, strings.Join(merchants, ", ")))
-		}
-	}
+			sort.Slice(suggestions, func(i, j int) bool {
+				if suggestions[i].limit != suggestions[j].limit {
+					return suggestions[i].limit > suggestions[j].limit
+				}
+				return suggestions[i].category < suggestions[j].category
+			})
+			budget := make(map[string]float64, len(suggestions))
+			categories := []map[string]interface{}{}
+			for _, s := range suggestions {
+				budget[s.category] = s.limit
+				categories = append(categories, map[string]interface{}{
+					"category":        s.category,
+					"monthly_average": fmt.Sprintf("%.2f", s.average),
+					"peak_month":      fmt.Sprintf("%.2f", s.peak),
+					"suggested_limit": fmt.Sprintf("%.2f", s.limit),
+					"essential":       s.essential,
+				})
+			}
 
-	// Check for potentially inactive subscriptions
-	now := time.Now()
-	for _, sub := range subscriptions {
-		occurrences, _ := sub["occurrences"].(int)
-		lastDateStr, _ := sub["last_occurrence"].(string)
-		lastDate, err := time.Parse("2006-01-02", lastDateStr)
-		if err == nil && occurrences < 3 && now.Sub(lastDate).Hours()/24 > 90 {
-			merchant, _ := sub["merchant"].(string)
-			warnings = append(warnings, fmt.Sprintf("Subscription to '%s' seems inactive (last paid %s). Consider cancelling if you no longer use it.", merchant, lastDateStr))
-		}
-	}
+			summary := fmt.Sprintf("Suggested monthly budget: $%.0f across %d categories, set at your %.0fth-percentile month.", total, len(categories), params.Percentile)
+			if monthlyIncome > 0 {
+				summary += fmt.Sprintf(" That's %.0f%% of your average monthly income.", total/monthlyIncome*100)
+			}
+			return &core.ToolResult{
+				Success: true,
+				Data: map[string]interface{}{
+					"categories":     categories,
+					"budget":         budget,
+					"total":          fmt.Sprintf("%.2f", total),
+					"monthly_income": fmt.Sprintf("%.2f", monthlyIncome),
+					"percentile":     params.Percentile,
+					"months":         params.Months,
+					"warnings":       warnings,
+					"summary":        summary,
+					"data_source":    map[string]bool{"is_mock": params.UseMock},
+					"generated_at":   now.Format(time.RFC3339),
+				},
+			}, nil
+		}).
+		Build()
+}
 
-	// Suggest potential savings
-	if totalMonthly > 50 {
-		savings := math.Round(totalMonthly*0.1*100) / 100
-		warnings = append(warnings, fmt.Sprintf("Tip: Cancelling just 10%% of your subscriptions could save you $%.2f monthly!", savings))
+// percentile returns the p-th percentile (0-100) of values, interpolating between neighbours
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
 	}
-
-	return warnings
-}
\ No newline at end of file
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
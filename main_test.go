@@ -0,0 +1,721 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/becomeliminal/nim-go-sdk/core"
+	"github.com/becomeliminal/nim-go-sdk/executor"
+	"github.com/becomeliminal/nim-go-sdk/tools"
+)
+
+// countingTool builds a fake write tool that records how often it actually ran
+func countingTool(name string, calls *int) core.Tool {
+	return tools.New(name).
+		Description("fake " + name).
+		HandlerFunc(func(ctx context.Context, input json.RawMessage) (interface{}, error) {
+			*calls++
+			return map[string]interface{}{"ok": true}, nil
+		}).
+		Build()
+}
+
+func TestLimitedToolEnforcesCap(t *testing.T) {
+	cases := []struct {
+		name    string
+		amount  string
+		allowed bool
+	}{
+		{"under cap", "25.00", true},
+		{"at cap", "100", true},
+		{"over cap", "100.01", false},
+		{"NaN", "NaN", false},
+		{"Inf", "Inf", false},
+		{"negative Inf", "-Inf", false},
+		{"zero", "0", false},
+		{"negative", "-50", false},
+		{"malformed", "abc", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			tool := &limitedTool{Tool: countingTool("send_money", &calls), maxAmount: 100}
+
+			input, _ := json.Marshal(map[string]string{"amount": tc.amount, "currency": "USD"})
+			result, err := tool.Execute(context.Background(), &core.ToolParams{Input: input})
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if result.Success != tc.allowed {
+				t.Errorf("amount %q: success = %v, want %v (error %q)", tc.amount, result.Success, tc.allowed, result.Error)
+			}
+			if wantCalls := map[bool]int{true: 1, false: 0}[tc.allowed]; calls != wantCalls {
+				t.Errorf("amount %q: wrapped tool ran %d times, want %d", tc.amount, calls, wantCalls)
+			}
+		})
+	}
+}
+
+// fakeExecutor is a core.ToolExecutor whose reads are answered by respond
+type fakeExecutor struct {
+	calls   int
+	respond func(call int) (*core.ExecuteResponse, error)
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, req *core.ExecuteRequest) (*core.ExecuteResponse, error) {
+	e.calls++
+	return e.respond(e.calls)
+}
+
+func (e *fakeExecutor) ExecuteWrite(ctx context.Context, req *core.ExecuteRequest) (*core.ExecuteResponse, error) {
+	return nil, errors.New("writes not supported")
+}
+
+func (e *fakeExecutor) Confirm(ctx context.Context, userID, confirmationID string) (*core.ExecuteResponse, error) {
+	return nil, errors.New("writes not supported")
+}
+
+func (e *fakeExecutor) Cancel(ctx context.Context, userID, confirmationID string) error {
+	return errors.New("writes not supported")
+}
+
+// negativeAmountFixture mixes explicit types with sign-encoded amounts
+func negativeAmountFixture() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"id": "typed-send", "type": "send", "amount": -20.0, "date": "2026-01-05"},
+		{"id": "untyped-send", "amount": "-15.50", "date": "2026-01-06"},
+		{"id": "untyped-receive", "amount": 100.0, "date": "2026-01-07"},
+		{"id": "typed-receive", "type": "receive", "amount": "-5", "date": "2026-01-08"},
+	}
+}
+
+func TestNormalizeTransactionAmounts(t *testing.T) {
+	cases := []struct {
+		name              string
+		useSignConvention bool
+		want              map[string]string
+	}{
+		{"type wins when present", false, map[string]string{
+			"typed-send": "send", "untyped-send": "send", "untyped-receive": "receive", "typed-receive": "receive",
+		}},
+		{"sign convention overrides type", true, map[string]string{
+			"typed-send": "send", "untyped-send": "send", "untyped-receive": "receive", "typed-receive": "send",
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := negativeAmountFixture()
+			for _, tx := range normalizeTransactionAmounts(raw, tc.useSignConvention) {
+				id := tx["id"].(string)
+				if tx["type"] != tc.want[id] {
+					t.Errorf("%s: type = %v, want %s", id, tx["type"], tc.want[id])
+				}
+				if amount, ok := tx["amount"].(float64); !ok || amount < 0 {
+					t.Errorf("%s: amount = %v, want a non-negative float64", id, tx["amount"])
+				}
+			}
+			if raw[0]["amount"] != -20.0 {
+				t.Errorf("input map was modified: amount = %v", raw[0]["amount"])
+			}
+		})
+	}
+}
+
+func TestParseTransactionsNegativeAmounts(t *testing.T) {
+	parsed, errs := parseTransactions(negativeAmountFixture())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected parse errors: %v", errs)
+	}
+
+	want := map[string]Transaction{
+		"typed-send":      {Type: "send", Amount: 20},
+		"untyped-send":    {Type: "send", Amount: 15.5},
+		"untyped-receive": {Type: "receive", Amount: 100},
+		"typed-receive":   {Type: "receive", Amount: 5},
+	}
+	if len(parsed) != len(want) {
+		t.Fatalf("parsed %d transactions, want %d", len(parsed), len(want))
+	}
+	for _, tx := range parsed {
+		if w := want[tx.ID]; tx.Type != w.Type || tx.Amount != w.Amount {
+			t.Errorf("%s: got %s %.2f, want %s %.2f", tx.ID, tx.Type, tx.Amount, w.Type, w.Amount)
+		}
+	}
+}
+
+func TestFetchTransactionsNormalizesNegativeAmounts(t *testing.T) {
+	data, _ := json.Marshal(map[string]interface{}{"transactions": negativeAmountFixture()})
+	fake := &fakeExecutor{respond: func(int) (*core.ExecuteResponse, error) {
+		return &core.ExecuteResponse{Success: true, Data: data}, nil
+	}}
+
+	transactions, err := fetchTransactions(context.Background(), fake, &core.ToolParams{UserID: "user"}, map[string]interface{}{"limit": 10})
+	if err != nil {
+		t.Fatalf("fetchTransactions: %v", err)
+	}
+
+	var spent float64
+	for _, tx := range transactions {
+		if tx["type"] == "send" {
+			spent += tx["amount"].(float64)
+		}
+	}
+	if spent != 35.5 {
+		t.Errorf("total spent = %.2f, want 35.50", spent)
+	}
+}
+
+func TestLiminalTransactionShape(t *testing.T) {
+	data, _ := json.Marshal(executor.GetTransactionsResponse{Transactions: []executor.Transaction{
+		{ID: "coffee", Type: "send", Amount: "4.50", Currency: "USDC", Counterparty: "Blue Bottle", Note: "latte", Status: "completed", Direction: "out", CreatedAt: "2026-01-05T08:30:00Z"},
+		{ID: "pay", Type: "receive", Amount: "2000", Currency: "USDC", Note: "salary", Status: "completed", Direction: "in", CreatedAt: "2026-01-01T09:00:00Z"},
+	}})
+	fake := &fakeExecutor{respond: func(int) (*core.ExecuteResponse, error) {
+		return &core.ExecuteResponse{Success: true, Data: data}, nil
+	}}
+
+	raw, err := fetchTransactions(context.Background(), fake, &core.ToolParams{UserID: "user"}, map[string]interface{}{"limit": 10})
+	if err != nil {
+		t.Fatalf("fetchTransactions: %v", err)
+	}
+	if capped, _ := capTransactions(raw, 10); capped[0]["id"] != "coffee" {
+		t.Errorf("capTransactions put %v first, want the newest (coffee)", capped[0]["id"])
+	}
+	if got := merchantName(raw[0]); got != "Blue Bottle" {
+		t.Errorf("merchantName = %q, want Blue Bottle", got)
+	}
+
+	transactions, errs := parseTransactions(raw)
+	if len(errs) > 0 {
+		t.Fatalf("parseTransactions errors: %v", errs)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("parsed %d transactions, want 2", len(transactions))
+	}
+	want := map[string]Transaction{
+		"coffee": {Type: "send", Description: "Blue Bottle", Amount: 4.5, Date: time.Date(2026, 1, 5, 8, 30, 0, 0, time.UTC)},
+		"pay":    {Type: "receive", Description: "salary", Amount: 2000, Date: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tx := range transactions {
+		w := want[tx.ID]
+		if tx.Type != w.Type || tx.Description != w.Description || tx.Amount != w.Amount || !tx.Date.Equal(w.Date) {
+			t.Errorf("%s = %+v, want %+v", tx.ID, tx, w)
+		}
+	}
+}
+
+func TestExecuteReadWithRetry(t *testing.T) {
+	defer func(delay time.Duration) { liminalRetryBaseDelay = delay }(liminalRetryBaseDelay)
+	liminalRetryBaseDelay = time.Millisecond
+
+	networkErr := fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	cases := []struct {
+		name         string
+		failures     int
+		fail         func() (*core.ExecuteResponse, error)
+		wantAttempts int
+		wantSuccess  bool
+	}{
+		{"5xx recovers", 2, func() (*core.ExecuteResponse, error) {
+			return &core.ExecuteResponse{Success: false, Error: "HTTP 503: unavailable"}, nil
+		}, 3, true},
+		{"429 recovers", 1, func() (*core.ExecuteResponse, error) {
+			return &core.ExecuteResponse{Success: false, Error: "HTTP 429: slow down"}, nil
+		}, 2, true},
+		{"network error recovers", 1, func() (*core.ExecuteResponse, error) {
+			return nil, networkErr
+		}, 2, true},
+		{"gives up after max retries", 5, func() (*core.ExecuteResponse, error) {
+			return &core.ExecuteResponse{Success: false, Error: "HTTP 502: bad gateway"}, nil
+		}, liminalMaxRetries + 1, false},
+		{"4xx is not retried", 5, func() (*core.ExecuteResponse, error) {
+			return &core.ExecuteResponse{Success: false, Error: "HTTP 400: bad request"}, nil
+		}, 1, false},
+		{"parse error is not retried", 5, func() (*core.ExecuteResponse, error) {
+			return nil, errors.New("failed to parse get_transactions response: unexpected EOF")
+		}, 1, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeExecutor{respond: func(call int) (*core.ExecuteResponse, error) {
+				if call <= tc.failures {
+					return tc.fail()
+				}
+				return &core.ExecuteResponse{Success: true, Data: json.RawMessage(`{}`)}, nil
+			}}
+
+			resp, err := executeReadWithRetry(context.Background(), fake, &core.ExecuteRequest{Tool: "get_balance"})
+			if fake.calls != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", fake.calls, tc.wantAttempts)
+			}
+			if success := err == nil && resp != nil && resp.Success; success != tc.wantSuccess {
+				t.Errorf("success = %v, want %v (err %v)", success, tc.wantSuccess, err)
+			}
+		})
+	}
+}
+
+func TestExecuteReadWithRetryStopsOnAuthFailure(t *testing.T) {
+	fake := &fakeExecutor{respond: func(int) (*core.ExecuteResponse, error) {
+		return &core.ExecuteResponse{Success: false, Error: "HTTP 401: token expired"}, nil
+	}}
+
+	_, err := executeReadWithRetry(context.Background(), fake, &core.ExecuteRequest{Tool: "get_balance"})
+	if !errors.Is(err, errAuthExpired) {
+		t.Errorf("err = %v, want errAuthExpired", err)
+	}
+	if fake.calls != 1 {
+		t.Errorf("attempts = %d, want 1", fake.calls)
+	}
+}
+
+func TestTransactionsSinceExcludesMockDataBeforeCutoff(t *testing.T) {
+	now := time.Now()
+	cutoff := now.AddDate(0, -3, 0)
+	mock := generateMockSubscriptionTransactions(6, mockOptions{Seed: 42})
+
+	older := 0
+	for _, tx := range mock {
+		if date, err := time.Parse(time.RFC3339, tx["date"].(string)); err == nil && date.Before(cutoff) {
+			older++
+		}
+	}
+	if older == 0 {
+		t.Fatal("fixture has no mock transactions before the cutoff; test proves nothing")
+	}
+
+	kept := transactionsSince(mock, cutoff)
+	if len(kept) != len(mock)-older {
+		t.Errorf("kept %d of %d transactions, want %d", len(kept), len(mock), len(mock)-older)
+	}
+	for _, tx := range kept {
+		date, err := time.Parse(time.RFC3339, tx["date"].(string))
+		if err != nil {
+			t.Fatalf("kept undated transaction %v", tx)
+		}
+		if date.Before(cutoff) {
+			t.Errorf("kept transaction dated %s, before cutoff %s", date.Format("2006-01-02"), cutoff.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestTransactionsSinceReadsAllDateLayouts(t *testing.T) {
+	cutoff := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	transactions := []map[string]interface{}{
+		{"id": "date-only", "date": "2026-01-15"},
+		{"id": "created-at", "createdAt": "2026-01-12T10:00:00Z"},
+		{"id": "unpadded", "date": "2026-2-1"},
+		{"id": "too-old", "date": "2026-01-02"},
+		{"id": "undated"},
+	}
+	got := fmt.Sprint(ids(transactionsSince(transactions, cutoff)))
+	if want := "[date-only created-at unpadded]"; got != want {
+		t.Errorf("transactionsSince kept %s, want %s", got, want)
+	}
+}
+
+func TestDetectPriceChangeParsesTransactions(t *testing.T) {
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []map[string]interface{}{
+		{"id": "old", "type": "send", "amount": "9.99", "counterparty": "Netflix", "createdAt": "2025-12-01T00:00:00Z"},
+		{"id": "jan", "type": "send", "amount": "15.49", "description": "Netflix", "date": "2026-01-05"},
+		{"id": "feb", "type": "send", "amount": 17.99, "description": "Netflix", "date": "2026-2-5"},
+		{"id": "failed", "type": "send", "amount": 99.0, "description": "Netflix", "date": "2026-03-05", "status": "failed"},
+	}
+	first, last, found := detectPriceChange(transactions, "netflix", cutoff)
+	if !found || first != 15.49 || last != 17.99 {
+		t.Errorf("detectPriceChange = %.2f, %.2f, %v; want 15.49, 17.99, true", first, last, found)
+	}
+}
+
+func TestSummarizeP2PParsesTransactions(t *testing.T) {
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []map[string]interface{}{
+		{"id": "dinner", "type": "send", "amount": "40", "counterparty": "@alice", "createdAt": "2026-01-05T19:00:00Z"},
+		{"id": "repaid", "amount": 15.0, "description": "from @alice", "date": "2026-01-09"},
+		{"id": "failed", "type": "send", "amount": 500.0, "description": "to @alice", "date": "2026-01-10", "status": "failed"},
+		{"id": "old", "type": "send", "amount": 25.0, "description": "to @alice", "date": "2025-12-20"},
+	}
+	counterparties, sent, received := summarizeP2P(transactions, cutoff)
+	if sent != 40 || received != 15 {
+		t.Errorf("sent, received = %.2f, %.2f; want 40.00, 15.00", sent, received)
+	}
+	if len(counterparties) != 1 || counterparties[0]["net"] != "-25.00" || counterparties[0]["last_transfer"] != "2026-01-09" {
+		t.Errorf("counterparties = %v, want @alice net -25.00 last 2026-01-09", counterparties)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   interface{}
+		want    float64
+		wantErr bool
+	}{
+		{"float", 12.5, 12.5, false},
+		{"numeric string", "19.99", 19.99, false},
+		{"padded string", "  7 ", 7, false},
+		{"negative string", "-3.25", -3.25, false},
+		{"empty string", "", 0, true},
+		{"malformed string", "12,50", 0, true},
+		{"currency symbol", "$10", 0, true},
+		{"NaN string", "NaN", 0, true},
+		{"Inf string", "Inf", 0, true},
+		{"negative Inf string", "-Inf", 0, true},
+		{"NaN float", math.NaN(), 0, true},
+		{"Inf float", math.Inf(1), 0, true},
+		{"missing", nil, 0, true},
+		{"wrong type", true, 0, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseAmount(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseAmount(%v) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseAmount(%v) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTransactionsSkipsBadAmounts(t *testing.T) {
+	raw := []map[string]interface{}{
+		{"id": "ok", "type": "send", "amount": "12.00", "date": "2026-01-05"},
+		{"id": "empty", "type": "send", "amount": "", "date": "2026-01-05"},
+		{"id": "malformed", "type": "send", "amount": "abc", "date": "2026-01-05"},
+		{"id": "nan", "type": "send", "amount": "NaN", "date": "2026-01-05"},
+		{"id": "inf", "type": "send", "amount": "Inf", "date": "2026-01-05"},
+		{"id": "missing", "type": "send", "date": "2026-01-05"},
+		{"id": "zero", "type": "send", "amount": 0.0, "date": "2026-01-05"},
+	}
+
+	parsed, errs := parseTransactions(raw)
+	if len(parsed) != 1 || parsed[0].ID != "ok" || parsed[0].Amount != 12 {
+		t.Errorf("parsed = %+v, want only the 12.00 transaction", parsed)
+	}
+	// Zero amounts are dropped silently; everything else unparseable is reported
+	if len(errs) != 5 {
+		t.Errorf("got %d errors, want 5: %v", len(errs), errs)
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	overnight := parseQuietHours("22:00", "07:00", "America/New_York")
+	daytime := parseQuietHours("12:00", "13:30", "")
+	ny, _ := time.LoadLocation("America/New_York")
+	at := func(hour, minute int, loc *time.Location) time.Time {
+		return time.Date(2026, 3, 10, hour, minute, 0, 0, loc)
+	}
+
+	cases := []struct {
+		name  string
+		hours quietHours
+		t     time.Time
+		want  bool
+	}{
+		{"overnight late evening", overnight, at(23, 30, ny), true},
+		{"overnight early morning", overnight, at(3, 0, ny), true},
+		{"overnight end is exclusive", overnight, at(7, 0, ny), false},
+		{"overnight afternoon", overnight, at(15, 0, ny), false},
+		{"overnight uses its timezone", overnight, at(3, 0, time.UTC), true}, // 23:00 in New York
+		{"daytime inside", daytime, at(13, 0, time.UTC), true},
+		{"daytime outside", daytime, at(14, 0, time.UTC), false},
+		{"disabled", parseQuietHours("", "", ""), at(3, 0, time.UTC), false},
+		{"invalid disables", parseQuietHours("10pm", "07:00", ""), at(23, 0, time.UTC), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.hours.contains(tc.t); got != tc.want {
+				t.Errorf("contains(%s) = %v, want %v", tc.t.Format(time.RFC3339), got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursQueueAndFlush(t *testing.T) {
+	defer func(q quietHours) { alertQuietHours = q }(alertQuietHours)
+	alertQuietHours = parseQuietHours("22:00", "07:00", "")
+	const userID = "quiet-hours-test"
+	defer users.reset(userID)
+
+	night := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	alert := pendingAlert{Tool: "check_low_balance", Severity: "critical", Message: "short $40"}
+
+	if holdForQuietHours(userID, false, alert, night) {
+		t.Error("alert the user asked for was queued")
+	}
+	if holdForQuietHours(userID, true, alert, morning) {
+		t.Error("proactive alert outside quiet hours was queued")
+	}
+	if !holdForQuietHours(userID, true, alert, night) {
+		t.Fatal("proactive alert during quiet hours was not queued")
+	}
+	if pending := flushPendingAlerts(userID, night); pending != nil {
+		t.Errorf("flushed %v during quiet hours", pending)
+	}
+	if pending := flushPendingAlerts(userID, morning); len(pending) != 1 || pending[0].Message != alert.Message {
+		t.Errorf("flushed %v after quiet hours, want the queued alert", pending)
+	}
+	if pending := flushPendingAlerts(userID, morning); len(pending) != 0 {
+		t.Errorf("second flush returned %v, want nothing", pending)
+	}
+}
+
+func TestQuietHoursQueueDedupesAndCaps(t *testing.T) {
+	defer func(q quietHours) { alertQuietHours = q }(alertQuietHours)
+	alertQuietHours = parseQuietHours("22:00", "07:00", "")
+	const userID = "quiet-hours-cap-test"
+	defer users.reset(userID)
+
+	night := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 50; i++ {
+		holdForQuietHours(userID, true, pendingAlert{Tool: "check_low_balance", Message: fmt.Sprintf("run %d", i)}, night)
+	}
+	pending := flushPendingAlerts(userID, morning)
+	if len(pending) != 1 || pending[0].Message != "run 49" {
+		t.Errorf("flushed %v, want only the latest check_low_balance alert", pending)
+	}
+
+	for i := 0; i < maxPendingAlerts+5; i++ {
+		holdForQuietHours(userID, true, pendingAlert{Tool: fmt.Sprintf("tool-%d", i)}, night)
+	}
+	pending = flushPendingAlerts(userID, morning)
+	if len(pending) != maxPendingAlerts || pending[0].Tool != "tool-5" {
+		t.Errorf("flushed %d alerts starting at %v, want the newest %d", len(pending), pending[0].Tool, maxPendingAlerts)
+	}
+}
+
+func TestFetchVaultAPYUnits(t *testing.T) {
+	defer func(fraction bool) { vaultAPYIsFraction = fraction }(vaultAPYIsFraction)
+
+	cases := []struct {
+		name     string
+		fraction bool
+		body     string
+		want     float64
+	}{
+		{"small percentage stays a percentage", false, `{"vaults":[{"currency":"USD","apy":"0.5"}]}`, 0.5},
+		{"best vault wins", false, `{"vaults":[{"currency":"USD","apy":"0.5"},{"currency":"EUR","apy":"4.25"}]}`, 4.25},
+		{"fraction unit is converted", true, `{"vaults":[{"currency":"USD","apy":"0.045"}]}`, 4.5},
+		{"bad rates are skipped", false, `{"vaults":[{"currency":"USD","apy":"NaN"},{"currency":"EUR","apy":"3"}]}`, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vaultAPYIsFraction = tc.fraction
+			fake := &fakeExecutor{respond: func(int) (*core.ExecuteResponse, error) {
+				return &core.ExecuteResponse{Success: true, Data: json.RawMessage(tc.body)}, nil
+			}}
+			got, err := fetchVaultAPY(context.Background(), fake, &core.ToolParams{})
+			if err != nil {
+				t.Fatalf("fetchVaultAPY: %v", err)
+			}
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("APY = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// testJWT builds an unsigned JWT carrying the given claims
+func testJWT(claims map[string]interface{}) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestLiminalAuthFuncDerivesUserID(t *testing.T) {
+	auth := liminalAuthFunc(executor.NewHTTPExecutor(executor.HTTPExecutorConfig{BaseURL: "http://localhost"}))
+	alice := testJWT(map[string]interface{}{"sub": "alice"})
+	noSub := testJWT(map[string]interface{}{"email": "bob@example.com"})
+
+	cases := []struct {
+		name    string
+		request func() *http.Request
+		want    string
+	}{
+		{"query token", func() *http.Request {
+			return httptest.NewRequest("GET", "/ws?token="+alice, nil)
+		}, sessionUserID(alice)},
+		{"bearer header", func() *http.Request {
+			r := httptest.NewRequest("GET", "/ws", nil)
+			r.Header.Set("Authorization", "Bearer "+alice)
+			return r
+		}, sessionUserID(alice)},
+		{"no token", func() *http.Request {
+			return httptest.NewRequest("GET", "/ws", nil)
+		}, anonymousUserID},
+		{"non-bearer header", func() *http.Request {
+			r := httptest.NewRequest("GET", "/ws", nil)
+			r.Header.Set("Authorization", "Basic abc")
+			return r
+		}, anonymousUserID},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := auth(tc.request())
+			if err != nil {
+				t.Fatalf("auth: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("user ID = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	// Every token gets its own stable ID, never the shared placeholder
+	first, second := sessionUserID(noSub), sessionUserID(noSub)
+	if first != second || first == "user" || first == anonymousUserID {
+		t.Errorf("sessionUserID = %q then %q, want one stable per-token ID", first, second)
+	}
+	if other := sessionUserID("not-a-jwt"); other == first {
+		t.Errorf("different tokens share user ID %q", other)
+	}
+	// The signature isn't verified, so a forged token claiming alice's sub must not become alice
+	forged := strings.TrimSuffix(alice, ".signature") + ".forged"
+	if sessionUserID(forged) == sessionUserID(alice) {
+		t.Error("a forged token with the same sub shares alice's user ID")
+	}
+}
+
+func TestGoalsAreKeptPerSession(t *testing.T) {
+	auth := liminalAuthFunc(executor.NewHTTPExecutor(executor.HTTPExecutorConfig{BaseURL: "http://localhost"}))
+	userFor := func(sub string) string {
+		id, err := auth(httptest.NewRequest("GET", "/ws?token="+testJWT(map[string]interface{}{"sub": sub}), nil))
+		if err != nil {
+			t.Fatalf("auth: %v", err)
+		}
+		return id
+	}
+	alice, bob := userFor("goals-test-alice"), userFor("goals-test-bob")
+	defer users.reset(alice)
+	defer users.reset(bob)
+
+	save, dashboard := createSaveGoalTool(), createGoalsDashboardTool(nil)
+	run := func(tool core.Tool, userID, input string) *core.ToolResult {
+		result, err := tool.Execute(context.Background(), &core.ToolParams{UserID: userID, Input: json.RawMessage(input)})
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		return result
+	}
+
+	if result := run(save, alice, `{"name": "Vacation", "target": 2000, "current": 500}`); !result.Success {
+		t.Fatalf("save_savings_goal failed: %s", result.Error)
+	} else if _, shared := result.Data.(map[string]interface{})["warning"]; shared {
+		t.Error("logged-in user was warned about shared storage")
+	}
+	if result := run(dashboard, alice, `{"monthly_surplus": 300}`); !result.Success {
+		t.Errorf("alice's dashboard failed: %s", result.Error)
+	}
+	if result := run(dashboard, bob, `{"monthly_surplus": 300}`); result.Success {
+		t.Errorf("bob sees alice's goals: %v", result.Data)
+	}
+}
+
+func TestAdminResetByUserToken(t *testing.T) {
+	const adminToken = "admin-secret"
+	jwt := testJWT(map[string]interface{}{"sub": "reset-test-carol"})
+	userID := sessionUserID(jwt)
+	users.update(userID, func(data *userData) {
+		data.Goals = []storedGoal{{Name: "Car", Target: 5000}}
+	})
+	defer users.reset(userID)
+
+	reset := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/admin/reset", strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		adminResetHandler(adminToken)(w, r)
+		return w
+	}
+
+	if w := reset(`{"user_token": "` + jwt + `", "all_users": true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("conflicting selectors: status %d, want 400", w.Code)
+	}
+	if w := reset(`{"user_token": "` + jwt + `"}`); w.Code != http.StatusOK {
+		t.Fatalf("reset by token: status %d: %s", w.Code, w.Body.String())
+	}
+	if goals := users.get(userID).Goals; len(goals) != 0 {
+		t.Errorf("goals survived reset: %v", goals)
+	}
+}
+
+func TestEssentialCategoriesOverride(t *testing.T) {
+	if !isEssential("Bills & Utilities") || isEssential("Entertainment") {
+		t.Fatal("default essential categories changed; update this test")
+	}
+	if got := resolveEssentials(nil); !got.has("bills & utilities") {
+		t.Errorf("no override should fall back to the defaults, got %v", got)
+	}
+	if got := resolveEssentials([]string{" ", ""}); !got.has("Transportation") {
+		t.Errorf("blank override should fall back to the defaults, got %v", got)
+	}
+
+	override := resolveEssentials([]string{" Entertainment "})
+	if !override.has("entertainment") || override.has("Bills & Utilities") {
+		t.Errorf("override = %v, want only entertainment", override)
+	}
+
+	transactions := []map[string]interface{}{
+		{"type": "send", "amount": 100.0, "description": "Electric Company"},
+		{"type": "send", "amount": 30.0, "description": "Netflix"},
+	}
+	byDefault := summarizeCashFlowWith(transactions, 30, essentialCategories)
+	overridden := summarizeCashFlowWith(transactions, 30, override)
+	if byDefault.MonthlyEssentialSpend <= overridden.MonthlyEssentialSpend {
+		t.Errorf("essential spend: default %.2f, entertainment-only %.2f; want the utility bill to dominate",
+			byDefault.MonthlyEssentialSpend, overridden.MonthlyEssentialSpend)
+	}
+	if overridden.MonthlyEssentialSpend == 0 {
+		t.Error("entertainment override counted no essential spending")
+	}
+}
+
+func TestCapTransactionsSortsByParsedDate(t *testing.T) {
+	transactions := []map[string]interface{}{
+		{"id": "padded", "date": "2026-01-09"},
+		{"id": "unpadded", "date": "2026-1-10"},               // sorts before "2026-01-09" as a string
+		{"id": "offset", "date": "2026-01-10T01:00:00+05:00"}, // 2026-01-09T20:00Z
+		{"id": "utc", "date": "2026-01-09T21:00:00Z"},
+		{"id": "undated", "date": "soon"},
+	}
+
+	sorted, truncated := capTransactions(transactions, 10)
+	if truncated {
+		t.Error("truncated with room to spare")
+	}
+	want := []string{"unpadded", "utc", "offset", "padded", "undated"}
+	for i, tx := range sorted {
+		if tx["id"] != want[i] {
+			t.Fatalf("order = %v, want %v", ids(sorted), want)
+		}
+	}
+
+	capped, truncated := capTransactions(transactions, 2)
+	if !truncated || len(capped) != 2 || capped[0]["id"] != "unpadded" {
+		t.Errorf("capped = %v (truncated %v), want the 2 most recent", ids(capped), truncated)
+	}
+}
+
+// ids lists the id field of each transaction, for failure messages
+func ids(transactions []map[string]interface{}) []interface{} {
+	out := make([]interface{}, len(transactions))
+	for i, tx := range transactions {
+		out[i] = tx["id"]
+	}
+	return out
+}